@@ -2,30 +2,43 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 
+	"github.com/lavinas/ephemeris/internal/adapters/config"
 	"github.com/lavinas/ephemeris/internal/adapters/handler"
+	"github.com/lavinas/ephemeris/internal/adapters/notifier"
 	"github.com/lavinas/ephemeris/internal/adapters/repository"
 	"github.com/lavinas/ephemeris/internal/usecase"
+	"github.com/lavinas/ephemeris/pkg"
 )
 
 // main is the entry point of the application
 func main() {
-	repo, err := repository.NewRepository(os.Getenv("MYSQL_DNS"))
+	cfg := config.NewEnvConfig()
+	repo, err := repository.NewRepository(cfg.Get(pkg.ConfigMysqlDNS))
 	if err != nil {
 		fmt.Println("internal error: " + err.Error())
 		return
 	}
 	defer repo.Close()
-	devnull, err := os.Open("/dev/null")
-	if err != nil {
-		fmt.Println("internal error: " + err.Error())
-		return
+	var out io.Writer = os.Stderr
+	if cfg.Get(pkg.ConfigLogOutput) != pkg.LogOutputStderr {
+		devnull, err := os.Open("/dev/null")
+		if err != nil {
+			fmt.Println("internal error: " + err.Error())
+			return
+		}
+		defer devnull.Close()
+		out = devnull
+	}
+	logger := log.New(out, "ephemeris: ", log.LstdFlags)
+	outbox := cfg.Get(pkg.ConfigNotifyOutbox)
+	if outbox == "" {
+		outbox = pkg.DefaultNotifyOutbox
 	}
-	defer devnull.Close()
-	logger := log.New(devnull, "ephemeris: ", log.LstdFlags)
-	usecase := usecase.NewCommandUsecase(repo, logger)
+	usecase := usecase.NewCommandUsecase(repo, cfg, logger, notifier.NewFileNotifier(outbox))
 	handler := handler.NewCommandHandler(usecase)
 	handler.Run()
 }
@@ -3,9 +3,11 @@ module github.com/lavinas/ephemeris
 go 1.22.1
 
 require (
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/gocarina/gocsv v0.0.0-20240520201108-78e41c74b4b1
 	github.com/klassmann/cpfcnpj v0.0.0-20200907140233-a595c5fd8de1
 	github.com/nyaruka/phonenumbers v1.3.4
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/texttheater/golang-levenshtein/levenshtein v0.0.0-20200805054039-cae8b0eaed6c
 	golang.org/x/text v0.14.0
 	gorm.io/driver/mysql v1.5.5
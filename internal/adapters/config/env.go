@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	BusinessKey = "BUSINNESS_ID"
+)
+
+// Env is the configuration handler based on environment variables
+// keys can be overridden per business by prefixing them with the business id
+// eg: CARDOSO_BARBOSA_SESSION_TIE_WEIGHTS overrides SESSION_TIE_WEIGHTS for business cardoso&barbosa
+type Env struct {
+	prefix string
+}
+
+// NewEnvConfig creates a new configuration handler based on environment variables
+func NewEnvConfig() *Env {
+	prefix := ""
+	if business := strings.TrimSpace(os.Getenv(BusinessKey)); business != "" {
+		re := regexp.MustCompile("[^A-Z0-9]+")
+		prefix = re.ReplaceAllString(strings.ToUpper(business), "_") + "_"
+	}
+	return &Env{prefix: prefix}
+}
+
+// Get is a method that returns the value of the key
+// it returns the business value if it is informed, otherwise the general one
+func (e *Env) Get(key string) string {
+	if e.prefix != "" {
+		if val := strings.TrimSpace(os.Getenv(e.prefix + key)); val != "" {
+			return val
+		}
+	}
+	return strings.TrimSpace(os.Getenv(key))
+}
@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File is a notifier that appends the messages to an outbox file
+// to be delivered by an external process
+type File struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a new notifier based on an outbox file
+func NewFileNotifier(path string) *File {
+	return &File{path: path}
+}
+
+// Send is a method that appends the message to the outbox file as one tab separated line
+// the paths of the attachments follow the body separated by commas when informed
+func (f *File) Send(channel, address, subject, body string, attachments ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	line := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", time.Now().Format(time.RFC3339), channel, address, subject, body)
+	if len(attachments) > 0 {
+		line += "\t" + strings.Join(attachments, ",")
+	}
+	_, err = fmt.Fprintln(file, line)
+	return err
+}
@@ -248,8 +248,11 @@ func (r *MySql) where(tx *gorm.DB, sob reflect.Type, base interface{}, extras ..
 		if isgorm == "-" || isgorm == "" {
 			continue
 		}
-		elem := reflect.ValueOf(base).Elem().Field(i).Interface()
-		if pkg.IsEmpty(elem) {
+		field := reflect.ValueOf(base).Elem().Field(i)
+		elem := field.Interface()
+		// zero numbers are not filters, as the entities are also built by field to be found, like
+		// the invoice items of an invoice, and the empty value of the commands is the NaN or minimum one
+		if pkg.IsEmpty(elem) || (field.Kind() != reflect.Ptr && field.IsZero()) {
 			continue
 		}
 		fName := r.fieldName(sob.Field(i).Name)
@@ -259,6 +262,10 @@ func (r *MySql) where(tx *gorm.DB, sob reflect.Type, base interface{}, extras ..
 		}
 	}
 	for _, extra := range extras {
+		if cond, ok := extra.(*pkg.Condition); ok {
+			tx = tx.Where(cond.Query, cond.Args...)
+			continue
+		}
 		tx = tx.Where(extra)
 	}
 	return tx, nil
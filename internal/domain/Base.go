@@ -4,14 +4,21 @@ package domain
 func All() []interface{} {
 	return []interface{}{
 		&Client{},
+		&ClientContact{},
 		&Service{},
 		&Recurrence{},
 		&Package{},
 		&PackageItem{},
 		&Contract{},
+		&ContractPause{},
+		&ContractPayer{},
+		&Price{},
 		&Agenda{},
 		&Invoice{},
 		&InvoiceItem{},
+		&Payment{},
+		&Dunning{},
+		&CreditNote{},
 		&Session{},
 	}
 }
@@ -28,6 +28,12 @@ var (
 		pkg.AgendaStatusCanceled,
 		pkg.AgendaStatusLocked,
 	}
+	// StatusOriginal is a slice that contains the status allowed for an agenda replaced by a rescheduled one
+	StatusOriginal = []string{
+		pkg.AgendaStatusOpenned,
+		pkg.AgendaStatusCanceled,
+		pkg.AgendaStatusSaved,
+	}
 )
 
 // Agenda represents the agenda entity
@@ -45,10 +51,11 @@ type Agenda struct {
 	Bond         *string    `gorm:"type:varchar(50)"`
 	BillingMonth *time.Time `gorm:"type:datetime"`
 	Locked       *time.Time `gorm:"type:datetime;null; index"`
+	Professional *string    `gorm:"type:varchar(50); null; index"`
 }
 
 // NewAgenda creates a new agenda domain entity
-func NewAgenda(id, date, clientID, serviceID, contractID, start, end, price, kind, status, bond, billing string) *Agenda {
+func NewAgenda(id, date, clientID, serviceID, contractID, start, end, price, kind, status, bond, billing, professional string) *Agenda {
 	agenda := &Agenda{}
 	agenda.ID = id
 	local, _ := time.LoadLocation(pkg.Location)
@@ -77,6 +84,9 @@ func NewAgenda(id, date, clientID, serviceID, contractID, start, end, price, kin
 	if p, err := strconv.ParseFloat(price, 64); err == nil {
 		agenda.Price = &p
 	}
+	if professional != "" {
+		agenda.Professional = &professional
+	}
 	return agenda
 }
 
@@ -121,6 +131,9 @@ func (a *Agenda) Format(repo port.Repository, args ...string) error {
 	if err := a.formatBillingMonth(); err != nil {
 		msg += err.Error() + " | "
 	}
+	if err := a.formatProfessional(); err != nil {
+		msg += err.Error() + " | "
+	}
 	tx := repo.Begin()
 	defer repo.Rollback(tx)
 	if err := a.validateDuplicity(repo, tx, noduplicity); err != nil {
@@ -174,11 +187,20 @@ func (a *Agenda) GetEmpty() port.Domain {
 	return &Agenda{}
 }
 
-// Lock is a method that locks the contract
+// Lock is a method that locks the agenda
+// it waits for timeout seconds if the agenda is locked by other process
+// the lock is checked and set inside a locking read to avoid two processes taking the same agenda
 func (a *Agenda) Lock(repo port.Repository, timeout int) error {
+	if a.IsLocked(repo, timeout) {
+		return errors.New(pkg.ErrAgendaLocked)
+	}
 	tx := repo.Begin()
 	defer repo.Rollback(tx)
-	if a.IsLocked(repo, tx, timeout) {
+	hot, err := a.getHotAgenda(repo, tx, true)
+	if err != nil {
+		return err
+	}
+	if hot.Locked != nil {
 		return errors.New(pkg.ErrAgendaLocked)
 	}
 	x := time.Now()
@@ -192,8 +214,8 @@ func (a *Agenda) Lock(repo port.Repository, timeout int) error {
 	return nil
 }
 
-// IsLocked is a method that checks if the contract is locked
-func (a *Agenda) IsLocked(repo port.Repository, tx interface{}, timeout int) bool {
+// IsLocked is a method that checks if the agenda is locked waiting for timeout seconds to be unlocked
+func (a *Agenda) IsLocked(repo port.Repository, timeout int) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
 	defer cancel()
 	for {
@@ -205,7 +227,9 @@ func (a *Agenda) IsLocked(repo port.Repository, tx interface{}, timeout int) boo
 			return true
 		default:
 			time.Sleep(1 * time.Second)
-			ag, error := a.getHotAgenda(repo, tx)
+			tx := repo.Begin()
+			ag, error := a.getHotAgenda(repo, tx, false)
+			repo.Rollback(tx)
 			if error != nil {
 				return true
 			}
@@ -215,9 +239,9 @@ func (a *Agenda) IsLocked(repo port.Repository, tx interface{}, timeout int) boo
 }
 
 // getHot gets the agenda out of default transaction
-func (a *Agenda) getHotAgenda(repo port.Repository, tx interface{}) (*Agenda, error) {
+func (a *Agenda) getHotAgenda(repo port.Repository, tx interface{}, lock bool) (*Agenda, error) {
 	ag := &Agenda{ID: a.ID}
-	if ok, err := repo.Get(tx, ag, a.ID, false); err != nil {
+	if ok, err := repo.Get(tx, ag, a.ID, lock); err != nil {
 		return nil, err
 	} else if !ok {
 		return nil, errors.New(pkg.ErrAgendaNotFound)
@@ -438,6 +462,22 @@ func (c *Agenda) formatStatus(filled bool) error {
 	return nil
 }
 
+// formatProfessional is a method that formats the professional attending the agenda
+func (c *Agenda) formatProfessional() error {
+	if c.Professional == nil {
+		return nil
+	}
+	professional := strings.ToLower(c.formatString(*c.Professional))
+	if len(professional) > 50 {
+		return errors.New(pkg.ErrLongProfessional)
+	}
+	if len(strings.Split(professional, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidProfessional)
+	}
+	c.Professional = &professional
+	return nil
+}
+
 // formatBond is a method that formats the bond of the agenda
 func (c *Agenda) formatBond(repo port.Repository) error {
 	if c.Bond == nil {
@@ -11,7 +11,6 @@ import (
 
 	"github.com/klassmann/cpfcnpj"
 	"github.com/lavinas/ephemeris/internal/port"
-	"github.com/nyaruka/phonenumbers"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -21,6 +20,9 @@ import (
 var (
 	// ContactWays is a slice that contains the ways to contact a client
 	ContactWays = []string{pkg.ContactEmail, pkg.ContactWhatsapp, pkg.ContactAll}
+	// TaxRegimes is a slice that contains the tax regimes of a client for fiscal documents
+	TaxRegimes = []string{pkg.TaxRegimeMEI, pkg.TaxRegimeSimples, pkg.TaxRegimePresumido, pkg.TaxRegimeReal,
+		pkg.TaxRegimeExempt}
 )
 
 // Client represents the client entity
@@ -33,10 +35,22 @@ type Client struct {
 	Contact  string     `gorm:"type:varchar(20); not null; index"`
 	Document *string    `gorm:"type:varchar(20); null; index"`
 	Lock     *time.Time `gorm:"type:datetime; null"`
+	// address and fiscal profile for service invoices
+	ZipCode      *string `gorm:"type:varchar(9); null; index"`
+	Street       *string `gorm:"type:varchar(100); null"`
+	Number       *string `gorm:"type:varchar(20); null"`
+	Complement   *string `gorm:"type:varchar(50); null"`
+	District     *string `gorm:"type:varchar(50); null"`
+	City         *string `gorm:"type:varchar(50); null; index"`
+	CityCode     *string `gorm:"type:varchar(7); null; index"`
+	State        *string `gorm:"type:varchar(2); null; index"`
+	MunicipalReg *string `gorm:"type:varchar(20); null"`
+	TaxRegime    *string `gorm:"type:varchar(20); null"`
 }
 
 // NewClient is a function that creates a new client
-func NewClient(id, date, name, email, phone, document, contact string) *Client {
+func NewClient(id, date, name, email, phone, document, contact, zipCode, street, number, complement, district, city,
+	cityCode, state, municipalReg, taxRegime string) *Client {
 	date = strings.TrimSpace(date)
 	local, _ := time.LoadLocation(pkg.Location)
 	fdate := time.Time{}
@@ -50,7 +64,7 @@ func NewClient(id, date, name, email, phone, document, contact string) *Client {
 	if document != "" {
 		doc = &document
 	}
-	return &Client{
+	client := &Client{
 		ID:       id,
 		Date:     fdate,
 		Name:     name,
@@ -59,6 +73,15 @@ func NewClient(id, date, name, email, phone, document, contact string) *Client {
 		Document: doc,
 		Contact:  contact,
 	}
+	fields := map[**string]string{&client.ZipCode: zipCode, &client.Street: street, &client.Number: number,
+		&client.Complement: complement, &client.District: district, &client.City: city, &client.CityCode: cityCode,
+		&client.State: state, &client.MunicipalReg: municipalReg, &client.TaxRegime: taxRegime}
+	for field, value := range fields {
+		if value = strings.TrimSpace(value); value != "" {
+			*field = &value
+		}
+	}
+	return client
 }
 
 // Format is a method that formats the client
@@ -73,6 +96,8 @@ func (c *Client) Format(repo port.Repository, args ...string) error {
 		c.formatPhone,
 		c.formatDocument,
 		c.formatContact,
+		c.formatAddress,
+		c.formatFiscal,
 	}
 	message := ""
 	for _, f := range formatMap {
@@ -188,7 +213,7 @@ func (c *Client) formatEmail(filled bool) error {
 	return nil
 }
 
-// formatPhone is a method that formats the phone field
+// formatPhone is a method that formats the phone field on E.164 format
 func (c *Client) formatPhone(filled bool) error {
 	phone := c.formatString(c.Phone)
 	if phone == "" {
@@ -197,14 +222,9 @@ func (c *Client) formatPhone(filled bool) error {
 		}
 		return errors.New(pkg.ErrEmptyPhone)
 	}
-	phone = c.formatNumber(phone)
-	p, err := phonenumbers.Parse(phone, "BR")
+	phone, err := pkg.NormalizePhone(phone)
 	if err != nil {
-		return errors.New(pkg.ErrInvalidPhone)
-	}
-	phone = phonenumbers.Format(p, phonenumbers.E164)
-	if len(phone) > 20 {
-		return errors.New(pkg.ErrLongPhone)
+		return err
 	}
 	c.Phone = phone
 	return nil
@@ -251,6 +271,86 @@ func (c *Client) formatContact(filled bool) error {
 	return nil
 }
 
+// formatAddress is a method that formats the address fields
+// zip code, street, city and state are required if any address field is informed
+func (c *Client) formatAddress(filled bool) error {
+	limits := []struct {
+		name  string
+		field **string
+		max   int
+	}{
+		{"street", &c.Street, 100}, {"number", &c.Number, 10}, {"complement", &c.Complement, 50},
+		{"district", &c.District, 50}, {"city", &c.City, 50},
+	}
+	informed := c.ZipCode != nil || c.CityCode != nil || c.State != nil
+	for _, l := range limits {
+		if *l.field == nil {
+			continue
+		}
+		value := c.formatString(**l.field)
+		if len(value) > l.max {
+			return fmt.Errorf(pkg.ErrLongAddress, l.name, l.max)
+		}
+		*l.field = &value
+		informed = true
+	}
+	if c.ZipCode != nil {
+		zip, err := pkg.FormatCEP(*c.ZipCode)
+		if err != nil {
+			return err
+		}
+		c.ZipCode = &zip
+	}
+	if c.State != nil {
+		state := strings.ToUpper(c.formatString(*c.State))
+		if _, ok := pkg.StateCodes[state]; !ok {
+			return errors.New(pkg.ErrInvalidState)
+		}
+		c.State = &state
+	}
+	if c.CityCode != nil {
+		code := c.formatNumber(*c.CityCode)
+		if c.State != nil && !pkg.ValidCityCode(code, *c.State) {
+			return errors.New(pkg.ErrInvalidCityCode)
+		}
+		c.CityCode = &code
+	}
+	if filled || !informed {
+		return nil
+	}
+	if c.ZipCode == nil || c.Street == nil || c.City == nil || c.State == nil {
+		return errors.New(pkg.ErrIncompleteAddress)
+	}
+	return nil
+}
+
+// formatFiscal is a method that formats the municipal registration and the tax regime
+// companies regimes require a cnpj document
+func (c *Client) formatFiscal(filled bool) error {
+	if c.MunicipalReg != nil {
+		reg := c.formatNumber(*c.MunicipalReg)
+		if reg == "" || len(reg) > 15 {
+			return errors.New(pkg.ErrInvalidMunicipalReg)
+		}
+		c.MunicipalReg = &reg
+	}
+	if c.TaxRegime == nil {
+		return nil
+	}
+	regime := strings.ToLower(c.formatString(*c.TaxRegime))
+	if !slices.Contains(TaxRegimes, regime) {
+		return fmt.Errorf(pkg.ErrInvalidTaxRegime, strings.Join(TaxRegimes, ", "))
+	}
+	c.TaxRegime = &regime
+	if filled || regime == pkg.TaxRegimeExempt {
+		return nil
+	}
+	if c.Document == nil || len(c.formatNumber(*c.Document)) != 14 {
+		return fmt.Errorf(pkg.ErrTaxRegimeWithoutCNPJ, regime)
+	}
+	return nil
+}
+
 // formatNumber is a method that formats a number
 func (c *Client) formatNumber(number string) string {
 	re := regexp.MustCompile("[0-9]+")
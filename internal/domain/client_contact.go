@@ -0,0 +1,321 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// ContactRoles is a slice that contains the roles of a client contact
+	ContactRoles = []string{pkg.RoleGuardian, pkg.RoleLiable, pkg.RolePayer, pkg.RoleEmergency}
+	// ContactRoutes are the roles of the contacts that receive each kind of message by priority
+	// invoices go to who pays them and notices, like the dunning of overdue invoices, to who answers for the client
+	ContactRoutes = map[string][]string{
+		pkg.RouteInvoice: {pkg.RolePayer, pkg.RoleLiable, pkg.RoleGuardian},
+		pkg.RouteNotice:  {pkg.RoleGuardian, pkg.RoleLiable, pkg.RolePayer},
+	}
+)
+
+// ClientContact represents a person to be contacted on behalf of a client, like the guardians of a child
+type ClientContact struct {
+	ID       string  `gorm:"type:varchar(50); primaryKey"`
+	ClientID string  `gorm:"type:varchar(50); not null; index"`
+	Name     string  `gorm:"type:varchar(100); not null; index"`
+	Role     string  `gorm:"type:varchar(20); not null; index"`
+	Email    *string `gorm:"type:varchar(100); null; index"`
+	Phone    *string `gorm:"type:varchar(20); null; index"`
+	Contact  string  `gorm:"type:varchar(20); not null"`
+}
+
+// NewClientContact creates a new client contact
+func NewClientContact(id, clientID, name, role, email, phone, contact string) *ClientContact {
+	c := &ClientContact{}
+	c.ID = id
+	c.ClientID = clientID
+	c.Name = name
+	c.Role = role
+	if email = strings.TrimSpace(email); email != "" {
+		c.Email = &email
+	}
+	if phone = strings.TrimSpace(phone); phone != "" {
+		c.Phone = &phone
+	}
+	c.Contact = contact
+	return c
+}
+
+// Format formats the client contact
+func (c *ClientContact) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := c.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatClientID(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatName(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatRole(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatEmail(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatPhone(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatContact(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := c.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the client contact
+func (c *ClientContact) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, c, c.ID, false)
+}
+
+// GetID is a method that returns the id of the client contact
+func (c *ClientContact) GetID() string {
+	return c.ID
+}
+
+// Get is a method that returns the client contact
+func (c *ClientContact) Get() port.Domain {
+	return c
+}
+
+// GetEmpty is a method that returns an empty client contact
+func (c *ClientContact) GetEmpty() port.Domain {
+	return &ClientContact{}
+}
+
+// TableName returns the table name for database
+func (c *ClientContact) TableName() string {
+	return "client_contact"
+}
+
+// Addresses returns the addresses of the contact by channel according to its contact preference
+func (c *ClientContact) Addresses() map[string]string {
+	ret := map[string]string{}
+	if c.Email != nil && *c.Email != "" && (c.Contact == pkg.ContactEmail || c.Contact == pkg.ContactAll) {
+		ret[pkg.ContactEmail] = *c.Email
+	}
+	if c.Phone != nil && *c.Phone != "" && (c.Contact == pkg.ContactWhatsapp || c.Contact == pkg.ContactAll) {
+		ret[pkg.ContactWhatsapp] = *c.Phone
+	}
+	return ret
+}
+
+// Recipients returns the contacts of the client that receive a kind of message
+// they are the contacts of the first role of the route that the client has or, if none, the client itself
+func Recipients(repo port.Repository, client *Client, route string) ([]*ClientContact, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &ClientContact{ClientID: client.ID}, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	contacts := []*ClientContact{}
+	if base != nil {
+		for _, c := range *base.(*[]ClientContact) {
+			contacts = append(contacts, &c)
+		}
+	}
+	slices.SortFunc(contacts, func(a, b *ClientContact) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, role := range ContactRoutes[route] {
+		ret := []*ClientContact{}
+		for _, c := range contacts {
+			if c.Role == role {
+				ret = append(ret, c)
+			}
+		}
+		if len(ret) > 0 {
+			return ret, nil
+		}
+	}
+	email, phone := client.Email, client.Phone
+	self := &ClientContact{ClientID: client.ID, Name: client.Name, Role: pkg.RoleClient, Email: &email, Phone: &phone,
+		Contact: client.Contact}
+	if self.Contact == "" {
+		self.Contact = pkg.DefaultContact
+	}
+	return []*ClientContact{self}, nil
+}
+
+// formatID is a method that formats the id of the client contact
+func (c *ClientContact) formatID(filled bool) error {
+	id := c.formatString(c.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 50 {
+		return errors.New(pkg.ErrLongID50)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	c.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatClientID is a method that formats the client of the contact
+func (c *ClientContact) formatClientID(repo port.Repository, filled bool) error {
+	c.ClientID = c.formatString(c.ClientID)
+	if c.ClientID == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyClientID)
+	}
+	client := &Client{ID: c.ClientID}
+	if exists, err := client.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrClientNotFound)
+	}
+	return nil
+}
+
+// formatName is a method that formats the name of the contact
+func (c *ClientContact) formatName(filled bool) error {
+	name := c.formatString(c.Name)
+	if name == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyName)
+	}
+	if len(name) > 100 {
+		return errors.New(pkg.ErrLongName)
+	}
+	c.Name = cases.Title(language.Und).String(name)
+	return nil
+}
+
+// formatRole is a method that formats the role of the contact
+func (c *ClientContact) formatRole(filled bool) error {
+	role := strings.ToLower(c.formatString(c.Role))
+	if role == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrRoleNotProvided)
+	}
+	if !slices.Contains(ContactRoles, role) {
+		return fmt.Errorf(pkg.ErrInvalidContactRole, strings.Join(ContactRoles, ", "))
+	}
+	c.Role = role
+	return nil
+}
+
+// formatEmail is a method that formats the email of the contact
+func (c *ClientContact) formatEmail() error {
+	if c.Email == nil {
+		return nil
+	}
+	a, err := mail.ParseAddress(c.formatString(*c.Email))
+	if err != nil {
+		return errors.New(pkg.ErrInvalidEmail)
+	}
+	if len(a.Address) > 100 {
+		return errors.New(pkg.ErrLongEmail)
+	}
+	c.Email = &a.Address
+	return nil
+}
+
+// formatPhone is a method that formats the phone of the contact on E164 format
+func (c *ClientContact) formatPhone() error {
+	if c.Phone == nil {
+		return nil
+	}
+	phone, err := pkg.NormalizePhone(*c.Phone)
+	if err != nil {
+		return err
+	}
+	c.Phone = &phone
+	return nil
+}
+
+// formatContact is a method that formats the preferred channel of the contact
+// it defaults to e-mail when the contact has e-mail and whatsapp otherwise
+func (c *ClientContact) formatContact(filled bool) error {
+	contact := strings.ToLower(c.formatString(c.Contact))
+	if contact == "" {
+		if filled {
+			return nil
+		}
+		contact = pkg.ContactWhatsapp
+		if c.Email != nil {
+			contact = pkg.ContactEmail
+		}
+	}
+	if len(contact) > 20 {
+		return errors.New(pkg.ErrLongContact)
+	}
+	if !slices.Contains(ContactWays, contact) {
+		return fmt.Errorf(pkg.ErrInvalidContact, strings.Join(ContactWays, ", "))
+	}
+	c.Contact = contact
+	if filled {
+		return nil
+	}
+	if (contact == pkg.ContactEmail || contact == pkg.ContactAll) && c.Email == nil {
+		return errors.New(pkg.ErrEmptyEmail)
+	}
+	if (contact == pkg.ContactWhatsapp || contact == pkg.ContactAll) && c.Phone == nil {
+		return errors.New(pkg.ErrEmptyPhone)
+	}
+	return nil
+}
+
+// formatString is a method that formats a string
+func (c *ClientContact) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a client contact
+func (c *ClientContact) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &ClientContact{}, c.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, c.ID)
+	}
+	return nil
+}
@@ -22,21 +22,25 @@ var (
 
 // Contract represents the contract entity
 type Contract struct {
-	ID          string     `gorm:"type:varchar(50); primaryKey"`
-	Date        time.Time  `gorm:"type:datetime; not null; index"`
-	ClientID    string     `gorm:"type:varchar(50); not null; index"`
-	SponsorID   *string    `gorm:"type:varchar(50); null; index"`
-	PackageID   string     `gorm:"type:varchar(50); not null; index"`
-	BillingType string     `gorm:"type:varchar(50); not null; index"`
-	DueDay      *int64     `gorm:"type:numeric(20); null; index"`
-	Start       time.Time  `gorm:"type:datetime; not null; index"`
-	End         *time.Time `gorm:"type:datetime; null; index"`
-	Bond        *string    `gorm:"type:varchar(50); null; index"`
-	Locked      *bool      `gorm:"type:boolean;null; index"`
+	ID           string     `gorm:"type:varchar(50); primaryKey"`
+	Date         time.Time  `gorm:"type:datetime; not null; index"`
+	ClientID     string     `gorm:"type:varchar(50); not null; index"`
+	SponsorID    *string    `gorm:"type:varchar(50); null; index"`
+	PackageID    string     `gorm:"type:varchar(50); not null; index"`
+	BillingType  string     `gorm:"type:varchar(50); not null; index"`
+	DueDay       *int64     `gorm:"type:numeric(20); null; index"`
+	Start        time.Time  `gorm:"type:datetime; not null; index"`
+	End          *time.Time `gorm:"type:datetime; null; index"`
+	Bond         *string    `gorm:"type:varchar(50); null; index"`
+	EndReason    *string    `gorm:"type:varchar(100); null"`
+	Origin       *string    `gorm:"type:varchar(50); null; index"`
+	Professional *string    `gorm:"type:varchar(50); null; index"`
+	Locked       *bool      `gorm:"type:boolean;null; index"`
 }
 
 // NewContract creates a new contract
-func NewContract(id, date, clientID, SponsorID, packageID, billingType, dueDay, start, end, bond string) *Contract {
+func NewContract(id, date, clientID, SponsorID, packageID, billingType, dueDay, start, end, bond, endReason,
+	origin, professional string) *Contract {
 	contract := &Contract{}
 	contract.ID = id
 	date = strings.TrimSpace(date)
@@ -58,6 +62,15 @@ func NewContract(id, date, clientID, SponsorID, packageID, billingType, dueDay,
 	if bond != "" {
 		contract.Bond = &bond
 	}
+	if endReason != "" {
+		contract.EndReason = &endReason
+	}
+	if origin != "" {
+		contract.Origin = &origin
+	}
+	if professional != "" {
+		contract.Professional = &professional
+	}
 	return contract
 }
 
@@ -93,9 +106,18 @@ func (c *Contract) Format(repo port.Repository, args ...string) error {
 	if err := c.formatEnd(); err != nil {
 		msg += err.Error() + " | "
 	}
+	if err := c.formatEndReason(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatOrigin(repo); err != nil {
+		msg += err.Error() + " | "
+	}
 	if err := c.formatBond(repo); err != nil {
 		msg += err.Error() + " | "
 	}
+	if err := c.formatProfessional(); err != nil {
+		msg += err.Error() + " | "
+	}
 	tx := repo.Begin()
 	defer repo.Rollback(tx)
 	if err := c.validateDuplicity(repo, tx, noduplicity); err != nil {
@@ -143,6 +165,26 @@ func (c *Contract) GetBond(repo port.Repository) (*Contract, error) {
 	return bond, nil
 }
 
+// GetPauses is a method that returns the pauses of the contract overlapping an interval of dates
+func (c *Contract) GetPauses(repo port.Repository, start, end time.Time) ([]*ContractPause, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	p1 := fmt.Sprintf("start <= '%s'", end.Format("2006-01-02 15:04:05"))
+	p2 := fmt.Sprintf("end >= '%s'", start.Format("2006-01-02"))
+	base, _, err := repo.Find(tx, &ContractPause{ContractID: c.ID}, -1, false, p1, p2)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*ContractPause{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, p := range *base.(*[]ContractPause) {
+		ret = append(ret, &p)
+	}
+	return ret, nil
+}
+
 // Lock is a method that locks the contract
 func (c *Contract) Lock(repo port.Repository) error {
 	var locked = true
@@ -323,6 +365,49 @@ func (c *Contract) formatEnd() error {
 	return nil
 }
 
+// formatEndReason is a method that formats the reason of the contract end
+func (c *Contract) formatEndReason() error {
+	if c.EndReason == nil {
+		return nil
+	}
+	reason := c.formatString(*c.EndReason)
+	if len(reason) > 100 {
+		return errors.New(pkg.ErrLongReason)
+	}
+	c.EndReason = &reason
+	return nil
+}
+
+// formatProfessional is a method that formats the professional attending the agendas of the contract
+func (c *Contract) formatProfessional() error {
+	if c.Professional == nil {
+		return nil
+	}
+	professional := strings.ToLower(c.formatString(*c.Professional))
+	if len(professional) > 50 {
+		return errors.New(pkg.ErrLongProfessional)
+	}
+	if len(strings.Split(professional, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidProfessional)
+	}
+	c.Professional = &professional
+	return nil
+}
+
+// formatOrigin is a method that formats the contract that was changed to this one
+func (c *Contract) formatOrigin(repo port.Repository) error {
+	if c.Origin == nil {
+		return nil
+	}
+	origin := &Contract{ID: *c.Origin}
+	if ok, err := origin.Load(repo); err != nil {
+		return err
+	} else if !ok {
+		return errors.New(pkg.ErrOriginNotFound)
+	}
+	return nil
+}
+
 // formatBond is a method that formats the bond of the contract
 func (c *Contract) formatBond(repo port.Repository) error {
 	if c.Bond == nil {
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractPause represents a period that a contract is suspended, like client vacations
+// from and to days informed are stored as start and end
+type ContractPause struct {
+	ID         string    `gorm:"type:varchar(50); primaryKey"`
+	ContractID string    `gorm:"type:varchar(50); not null; index"`
+	Start      time.Time `gorm:"type:datetime; not null; index"`
+	End        time.Time `gorm:"type:datetime; not null; index"`
+	Reason     string    `gorm:"type:varchar(100); null"`
+}
+
+// NewContractPause creates a new contract pause
+func NewContractPause(id, contractID, from, to, reason string) *ContractPause {
+	local, _ := time.LoadLocation(pkg.Location)
+	pause := &ContractPause{}
+	pause.ID = id
+	pause.ContractID = contractID
+	pause.Start, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(from), local)
+	pause.End, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(to), local)
+	pause.Reason = reason
+	return pause
+}
+
+// Format formats the contract pause
+func (p *ContractPause) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := p.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatContractID(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatPeriod(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatReason(); err != nil {
+		msg += err.Error() + " | "
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := p.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the contract pause
+func (p *ContractPause) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, p, p.ID, false)
+}
+
+// GetID is a method that returns the id of the contract pause
+func (p *ContractPause) GetID() string {
+	return p.ID
+}
+
+// Get is a method that returns the contract pause
+func (p *ContractPause) Get() port.Domain {
+	return p
+}
+
+// GetEmpty is a method that returns an empty contract pause
+func (p *ContractPause) GetEmpty() port.Domain {
+	return &ContractPause{}
+}
+
+// TableName returns the table name for database
+func (p *ContractPause) TableName() string {
+	return "contract_pause"
+}
+
+// Contains checks if a moment is inside the pause, considering the whole last day
+func (p *ContractPause) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End.AddDate(0, 0, 1))
+}
+
+// formatID is a method that formats the id of the contract pause
+func (p *ContractPause) formatID(filled bool) error {
+	id := p.formatString(p.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 50 {
+		return errors.New(pkg.ErrLongID50)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	p.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatContractID is a method that formats the contract id of the pause
+func (p *ContractPause) formatContractID(repo port.Repository, filled bool) error {
+	p.ContractID = p.formatString(p.ContractID)
+	if p.ContractID == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyContractID)
+	}
+	contract := &Contract{ID: p.ContractID}
+	if exists, err := contract.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrContractNotFound)
+	}
+	return nil
+}
+
+// formatPeriod is a method that formats the from and to dates of the pause stored as start and end
+func (p *ContractPause) formatPeriod(filled bool) error {
+	if p.Start.IsZero() && !filled {
+		return fmt.Errorf(pkg.ErrInvalidFrom, pkg.DateFormat)
+	}
+	if p.End.IsZero() && !filled {
+		return fmt.Errorf(pkg.ErrInvalidTo, pkg.DateFormat)
+	}
+	if !p.Start.IsZero() && !p.End.IsZero() && p.Start.After(p.End) {
+		return errors.New(pkg.ErrFromAfterTo)
+	}
+	return nil
+}
+
+// formatReason is a method that formats the reason of the pause
+func (p *ContractPause) formatReason() error {
+	p.Reason = p.formatString(p.Reason)
+	if len(p.Reason) > 100 {
+		return errors.New(pkg.ErrLongReason)
+	}
+	return nil
+}
+
+// formatString is a method that formats a string
+func (p *ContractPause) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a contract pause
+func (p *ContractPause) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &ContractPause{}, p.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, p.ID)
+	}
+	return nil
+}
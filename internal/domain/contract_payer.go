@@ -0,0 +1,277 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractPayer represents a client that pays a share of the contract billing, like parents or a health plan
+// the share is a fixed amount of each billed value or, when percent, a percentage of it
+type ContractPayer struct {
+	ID         string   `gorm:"type:varchar(50); primaryKey"`
+	ContractID string   `gorm:"type:varchar(50); not null; index"`
+	ClientID   string   `gorm:"type:varchar(50); not null; index"`
+	Share      *float64 `gorm:"type:decimal(10,2); not null"`
+	Percent    *bool    `gorm:"type:boolean; null"`
+}
+
+// NewContractPayer creates a new contract payer. Shares ended by % are percentages
+func NewContractPayer(id, contractID, clientID, share string) *ContractPayer {
+	payer := &ContractPayer{}
+	payer.ID = id
+	payer.ContractID = contractID
+	payer.ClientID = clientID
+	if share = strings.TrimSpace(share); share != "" {
+		percent := strings.HasSuffix(share, "%")
+		s, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(share, "%")), 64)
+		if err != nil {
+			s = math.NaN()
+		}
+		payer.Share = &s
+		payer.Percent = &percent
+	}
+	return payer
+}
+
+// Format formats the contract payer
+func (p *ContractPayer) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := p.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatContractID(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatClientID(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatShare(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := p.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the contract payer
+func (p *ContractPayer) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, p, p.ID, false)
+}
+
+// GetID is a method that returns the id of the contract payer
+func (p *ContractPayer) GetID() string {
+	return p.ID
+}
+
+// Get is a method that returns the contract payer
+func (p *ContractPayer) Get() port.Domain {
+	return p
+}
+
+// GetEmpty is a method that returns an empty contract payer
+func (p *ContractPayer) GetEmpty() port.Domain {
+	return &ContractPayer{}
+}
+
+// TableName returns the table name for database
+func (p *ContractPayer) TableName() string {
+	return "contract_payer"
+}
+
+// IsPercent returns if the share of the payer is a percentage of the billed value
+func (p *ContractPayer) IsPercent() bool {
+	return p.Percent != nil && *p.Percent
+}
+
+// ShareText returns the share formatted as a percentage or as an amount
+func (p *ContractPayer) ShareText() string {
+	if p.Share == nil {
+		return ""
+	}
+	if p.IsPercent() {
+		return strconv.FormatFloat(*p.Share, 'f', -1, 64) + "%"
+	}
+	return fmt.Sprintf("%.2f", *p.Share)
+}
+
+// SplitValue splits a billed value between the payers returning the share of each one and the rest
+// fixed amounts are covered first, limited to the value, and percentages are applied over what remains of them
+// the rounding remainder of the percentages goes to the last percentage payer, so the shares and the rest
+// always sum exactly to the value
+func SplitValue(value float64, payers []*ContractPayer) ([]float64, float64) {
+	shares := make([]float64, len(payers))
+	rest := value
+	for i, p := range payers {
+		if p.Share == nil || p.IsPercent() {
+			continue
+		}
+		shares[i] = math.Round(math.Min(*p.Share, math.Max(rest, 0))*100) / 100
+		rest -= shares[i]
+	}
+	base := math.Round(rest*100) / 100
+	percent, last, split := 0.0, -1, 0.0
+	for i, p := range payers {
+		if p.Share == nil || !p.IsPercent() {
+			continue
+		}
+		shares[i] = math.Round(base**p.Share) / 100
+		percent += *p.Share
+		last = i
+		split += shares[i]
+	}
+	if last >= 0 {
+		shares[last] = math.Round((shares[last]+math.Round(base*percent)/100-split)*100) / 100
+		rest = base - math.Round(base*percent)/100
+	}
+	return shares, math.Round(rest*100) / 100
+}
+
+// LoadContractPayers returns the payers of a contract sorted by id
+func LoadContractPayers(repo port.Repository, contractID string) ([]*ContractPayer, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &ContractPayer{ContractID: contractID}, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*ContractPayer{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, p := range *base.(*[]ContractPayer) {
+		ret = append(ret, &p)
+	}
+	slices.SortFunc(ret, func(a, b *ContractPayer) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ret, nil
+}
+
+// formatID is a method that formats the id of the contract payer
+func (p *ContractPayer) formatID(filled bool) error {
+	id := p.formatString(p.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 50 {
+		return errors.New(pkg.ErrLongID50)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	p.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatContractID is a method that formats the contract id of the payer
+func (p *ContractPayer) formatContractID(repo port.Repository, filled bool) error {
+	p.ContractID = p.formatString(p.ContractID)
+	if p.ContractID == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyContractID)
+	}
+	contract := &Contract{ID: p.ContractID}
+	if exists, err := contract.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrContractNotFound)
+	}
+	if p.ClientID != "" && contract.ClientID == p.formatString(p.ClientID) {
+		return errors.New(pkg.ErrPayerIsClient)
+	}
+	return nil
+}
+
+// formatClientID is a method that formats the client that pays the share
+func (p *ContractPayer) formatClientID(repo port.Repository, filled bool) error {
+	p.ClientID = p.formatString(p.ClientID)
+	if p.ClientID == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyClientID)
+	}
+	client := &Client{ID: p.ClientID}
+	if exists, err := client.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrClientNotFound)
+	}
+	return nil
+}
+
+// formatShare is a method that formats the share of the payer
+// the percentages of all payers of the contract should not be over 100%
+func (p *ContractPayer) formatShare(repo port.Repository, filled bool) error {
+	if p.Share == nil {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyShare)
+	}
+	if math.IsNaN(*p.Share) || *p.Share <= 0 || (p.IsPercent() && *p.Share > 100) {
+		return errors.New(pkg.ErrInvalidShare)
+	}
+	if !p.IsPercent() || p.ContractID == "" {
+		return nil
+	}
+	payers, err := LoadContractPayers(repo, p.ContractID)
+	if err != nil {
+		return err
+	}
+	total := *p.Share
+	for _, other := range payers {
+		if other.ID != p.ID && other.IsPercent() {
+			total += *other.Share
+		}
+	}
+	if total > 100 {
+		return errors.New(pkg.ErrSharesOver100)
+	}
+	return nil
+}
+
+// formatString is a method that formats a string
+func (p *ContractPayer) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a contract payer
+func (p *ContractPayer) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &ContractPayer{}, p.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, p.ID)
+	}
+	return nil
+}
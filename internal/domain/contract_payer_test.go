@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSplitValue(t *testing.T) {
+	share := func(value float64, percent bool) *ContractPayer {
+		return &ContractPayer{Share: &value, Percent: &percent}
+	}
+	cases := []struct {
+		value  float64
+		payers []*ContractPayer
+		shares []float64
+		rest   float64
+	}{
+		{10.01, []*ContractPayer{share(50, true), share(50, true)}, []float64{5.01, 5}, 0},
+		{100, []*ContractPayer{share(33.33, true), share(33.33, true), share(33.34, true)}, []float64{33.33, 33.33, 33.34}, 0},
+		{100, []*ContractPayer{share(33.33, true), share(33.33, true)}, []float64{33.33, 33.33}, 33.34},
+		{0.05, []*ContractPayer{share(1.0/3*100, true), share(1.0/3*100, true), share(1.0/3*100, true)}, []float64{0.02, 0.02, 0.01}, 0},
+		{150.55, []*ContractPayer{share(50, false), share(50, true)}, []float64{50, 50.28}, 50.27},
+		{30, []*ContractPayer{share(50, false), share(10, true)}, []float64{30, 0}, 0},
+	}
+	for _, c := range cases {
+		shares, rest := SplitValue(c.value, c.payers)
+		sum := rest
+		for i := range shares {
+			sum += shares[i]
+			if shares[i] != c.shares[i] {
+				t.Errorf("SplitValue(%v) shares = %v, want %v", c.value, shares, c.shares)
+				break
+			}
+		}
+		if rest != c.rest {
+			t.Errorf("SplitValue(%v) rest = %v, want %v", c.value, rest, c.rest)
+		}
+		if math.Round(sum*100) != math.Round(c.value*100) {
+			t.Errorf("SplitValue(%v) sums %v", c.value, sum)
+		}
+	}
+}
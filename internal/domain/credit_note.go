@@ -0,0 +1,335 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// CreditNoteStatus are the status of a credit note by the use of the value credited over the invoice balance
+	CreditNoteStatus = []string{pkg.CreditNoteStatusOpen, pkg.CreditNoteStatusApplied, pkg.CreditNoteStatusRefunded}
+)
+
+// CreditNote represents a refund or a partial cancellation of an invoice, of one of its items or of a billed agenda
+// the value not owed anymore on the invoice reduces its balance and the value already paid is refunded
+// or carried as a credit to the next invoice of the client
+type CreditNote struct {
+	ID        string    `gorm:"type:varchar(150); primaryKey"`
+	Date      time.Time `gorm:"type:datetime; not null; index"`
+	InvoiceID string    `gorm:"type:varchar(150); not null; index"`
+	ClientID  string    `gorm:"type:varchar(50); not null; index"`
+	ItemID    *string   `gorm:"type:varchar(150); null; index"`
+	AgendaID  *string   `gorm:"type:varchar(50); null; index"`
+	Value     float64   `gorm:"type:numeric(20,2); not null"`
+	Credit    float64   `gorm:"type:numeric(20,2); not null"`
+	Reason    string    `gorm:"type:varchar(100); not null"`
+	Status    string    `gorm:"type:varchar(50); not null; index"`
+	AppliedID *string   `gorm:"type:varchar(150); null; index"`
+}
+
+// NewCreditNote creates a new credit note domain entity
+func NewCreditNote(id, date, invoiceID, itemID, agendaID, value, reason string) *CreditNote {
+	local, _ := time.LoadLocation(pkg.Location)
+	note := &CreditNote{}
+	note.ID = id
+	note.Date, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(date), local)
+	note.InvoiceID = invoiceID
+	if itemID = strings.TrimSpace(itemID); itemID != "" {
+		note.ItemID = &itemID
+	}
+	if agendaID = strings.TrimSpace(agendaID); agendaID != "" {
+		note.AgendaID = &agendaID
+	}
+	var err error
+	if note.Value, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+		note.Value = math.NaN()
+	}
+	note.Reason = reason
+	note.Status = pkg.CreditNoteStatusApplied
+	return note
+}
+
+// Format formats the credit note
+func (c *CreditNote) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := c.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if c.Date.IsZero() && !filled {
+		msg += fmt.Sprintf(pkg.ErrInvalidDateFormat, pkg.DateFormat) + " | "
+	}
+	if err := c.formatReason(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := c.formatStatus(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if !filled {
+		if err := c.formatInvoice(repo); err != nil {
+			msg += err.Error() + " | "
+		}
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := c.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the credit note
+func (c *CreditNote) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, c, c.ID, false)
+}
+
+// GetID is a method that returns the id of the credit note
+func (c *CreditNote) GetID() string {
+	return c.ID
+}
+
+// Get is a method that returns the credit note
+func (c *CreditNote) Get() port.Domain {
+	return c
+}
+
+// GetEmpty is a method that returns an empty credit note
+func (c *CreditNote) GetEmpty() port.Domain {
+	return &CreditNote{}
+}
+
+// TableName returns the table name for database
+func (c *CreditNote) TableName() string {
+	return "credit_note"
+}
+
+// Reduction returns the value of the credit note that reduces the balance of its invoice
+// the value carried as credit to a next invoice does not reduce it
+func (c *CreditNote) Reduction() float64 {
+	return c.Value - c.Credit
+}
+
+// LoadCreditNotes returns the credit notes of an invoice
+func LoadCreditNotes(repo port.Repository, invoiceID string) ([]*CreditNote, error) {
+	return findCreditNotes(repo, &CreditNote{InvoiceID: invoiceID})
+}
+
+// OpenCredits returns the credit notes of a client with value carried as credit not applied yet ordered by date
+func OpenCredits(repo port.Repository, clientID string) ([]*CreditNote, error) {
+	ret, err := findCreditNotes(repo, &CreditNote{ClientID: clientID, Status: pkg.CreditNoteStatusOpen}, "credit > 0")
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(ret, func(a, b *CreditNote) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ret, nil
+}
+
+// CreditedValues returns the sum of the credit notes values that reduce the balance by invoice
+func CreditedValues(repo port.Repository) (map[string]float64, error) {
+	notes, err := findCreditNotes(repo, &CreditNote{})
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]float64{}
+	for _, n := range notes {
+		ret[n.InvoiceID] += n.Reduction()
+	}
+	return ret, nil
+}
+
+// findCreditNotes returns the credit notes found by a filter
+func findCreditNotes(repo port.Repository, filter *CreditNote, extras ...interface{}) ([]*CreditNote, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, filter, -1, false, extras...)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*CreditNote{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, n := range *base.(*[]CreditNote) {
+		ret = append(ret, &n)
+	}
+	return ret, nil
+}
+
+// formatID is a method that formats the id of the credit note
+func (c *CreditNote) formatID(filled bool) error {
+	id := c.formatString(c.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 150 {
+		return errors.New(pkg.ErrLongID150)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	c.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatReason is a method that formats the reason of the credit note
+func (c *CreditNote) formatReason(filled bool) error {
+	c.Reason = c.formatString(c.Reason)
+	if c.Reason == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyReason)
+	}
+	if len(c.Reason) > 100 {
+		return errors.New(pkg.ErrLongReason)
+	}
+	return nil
+}
+
+// formatStatus is a method that formats the status of the credit note
+func (c *CreditNote) formatStatus(filled bool) error {
+	c.Status = strings.ToLower(c.formatString(c.Status))
+	if c.Status == "" && filled {
+		return nil
+	}
+	if !slices.Contains(CreditNoteStatus, c.Status) {
+		return fmt.Errorf(pkg.ErrInvalidCreditNoteStatus, strings.Join(CreditNoteStatus, ", "))
+	}
+	return nil
+}
+
+// formatInvoice is a method that validates the invoice, the item or the agenda credited and the value of the credit note
+// setting the client of the invoice and the item of the agenda. The value is limited to the value not credited yet
+func (c *CreditNote) formatInvoice(repo port.Repository) error {
+	available, err := c.Available(repo)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(c.Value) || c.Value <= 0 {
+		return errors.New(pkg.ErrCreditNoteValue)
+	}
+	if c.Value > available+0.005 {
+		return fmt.Errorf(pkg.ErrCreditNoteOverValue, c.Value, math.Max(available, 0))
+	}
+	return nil
+}
+
+// Available returns the value of the invoice or of the item credited not credited yet by other credit notes
+// setting the client of the invoice and the item of the agenda credited
+func (c *CreditNote) Available(repo port.Repository) (float64, error) {
+	c.InvoiceID = strings.ToLower(c.formatString(c.InvoiceID))
+	if c.InvoiceID == "" {
+		return 0, errors.New(pkg.ErrEmptyInvoice)
+	}
+	invoice := &Invoice{ID: c.InvoiceID}
+	if ok, err := invoice.Load(repo); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, errors.New(pkg.ErrInvoiceNotFound)
+	}
+	if invoice.Value <= 0 {
+		return 0, errors.New(pkg.ErrCreditNoteRefundInvoice)
+	}
+	c.ClientID = invoice.ClientID
+	item, err := c.creditedItem(repo)
+	if err != nil {
+		return 0, err
+	}
+	notes, err := LoadCreditNotes(repo, c.InvoiceID)
+	if err != nil {
+		return 0, err
+	}
+	ret := invoice.Value
+	for _, n := range notes {
+		if n.ID != c.ID {
+			ret -= n.Value
+		}
+	}
+	if item != nil {
+		itemLimit := item.Value
+		for _, n := range notes {
+			if n.ID != c.ID && n.ItemID != nil && *n.ItemID == item.ID {
+				itemLimit -= n.Value
+			}
+		}
+		ret = math.Min(ret, itemLimit)
+	}
+	return math.Round(ret*100) / 100, nil
+}
+
+// creditedItem returns the invoice item credited by the credit note found by its id or by its agenda
+func (c *CreditNote) creditedItem(repo port.Repository) (*InvoiceItem, error) {
+	if c.ItemID != nil {
+		item := &InvoiceItem{ID: strings.ToLower(c.formatString(*c.ItemID))}
+		if ok, err := item.Load(repo); err != nil {
+			return nil, err
+		} else if !ok || item.InvoiceID != c.InvoiceID {
+			return nil, fmt.Errorf(pkg.ErrCreditNoteItem, item.ID, c.InvoiceID)
+		}
+		if c.AgendaID != nil && (item.AgendaID == nil || *item.AgendaID != *c.AgendaID) {
+			return nil, fmt.Errorf(pkg.ErrCreditNoteAgenda, *c.AgendaID, c.InvoiceID)
+		}
+		c.ItemID, c.AgendaID = &item.ID, item.AgendaID
+		return item, nil
+	}
+	if c.AgendaID == nil {
+		return nil, nil
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &InvoiceItem{InvoiceID: c.InvoiceID, AgendaID: c.AgendaID}, 1, false)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, fmt.Errorf(pkg.ErrCreditNoteAgenda, *c.AgendaID, c.InvoiceID)
+	}
+	item := (*base.(*[]InvoiceItem))[0]
+	c.ItemID = &item.ID
+	return &item, nil
+}
+
+// formatString is a method that formats a string
+func (c *CreditNote) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a credit note
+func (c *CreditNote) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &CreditNote{}, c.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, c.ID)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// Dunning represents a step of the dunning sequence of an overdue invoice sent to the client contacts
+type Dunning struct {
+	ID        string    `gorm:"type:varchar(150); primaryKey"`
+	Date      time.Time `gorm:"type:datetime; not null; index"`
+	InvoiceID string    `gorm:"type:varchar(150); not null; index"`
+	ClientID  string    `gorm:"type:varchar(50); not null; index"`
+	Step      int64     `gorm:"type:numeric(20); not null; index"`
+	Days      int64     `gorm:"type:numeric(20); not null"`
+	Fine      float64   `gorm:"type:numeric(20,2); not null"`
+	Interest  float64   `gorm:"type:numeric(20,2); not null"`
+	Sent      string    `gorm:"type:varchar(255); null"`
+	Status    string    `gorm:"type:varchar(50); not null; index"`
+}
+
+// Format formats the dunning step
+func (d *Dunning) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := d.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	d.InvoiceID = d.formatString(d.InvoiceID)
+	d.ClientID = d.formatString(d.ClientID)
+	d.Status = d.formatString(d.Status)
+	if d.InvoiceID == "" && !filled {
+		msg += pkg.ErrEmptyInvoice + " | "
+	}
+	if d.ClientID == "" && !filled {
+		msg += pkg.ErrEmptyClientID + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the dunning step
+func (d *Dunning) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, d, d.ID, false)
+}
+
+// GetID is a method that returns the id of the dunning step
+func (d *Dunning) GetID() string {
+	return d.ID
+}
+
+// Get is a method that returns the dunning step
+func (d *Dunning) Get() port.Domain {
+	return d
+}
+
+// GetEmpty is a method that returns an empty dunning step
+func (d *Dunning) GetEmpty() port.Domain {
+	return &Dunning{}
+}
+
+// TableName returns the table name for database
+func (d *Dunning) TableName() string {
+	return "dunning"
+}
+
+// LastDunningStep returns the last step of the dunning sequence already sent for an invoice or zero if none
+func LastDunningStep(repo port.Repository, invoiceID string) (int64, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &Dunning{InvoiceID: invoiceID}, -1, false)
+	if err != nil {
+		return 0, err
+	}
+	if base == nil {
+		return 0, nil
+	}
+	ret := int64(0)
+	for _, d := range *base.(*[]Dunning) {
+		if d.Status == pkg.DunningStatusSent && d.Step > ret {
+			ret = d.Step
+		}
+	}
+	return ret, nil
+}
+
+// formatID is a method that formats the id of the dunning step
+func (d *Dunning) formatID(filled bool) error {
+	id := d.formatString(d.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 150 {
+		return errors.New(pkg.ErrLongID150)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	d.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatString is a method that formats a string
+func (d *Dunning) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
@@ -31,17 +31,22 @@ var (
 
 // Invoice represents the invoice entity
 type Invoice struct {
-	ID            string    `gorm:"type:varchar(150); primaryKey"`
-	Date          time.Time `gorm:"type:datetime; not null; index"`
-	ClientID      string    `gorm:"type:varchar(50); not null; index"`
-	Value         float64   `gorm:"type:numeric(20,2); not null; index"`
-	Status        string    `gorm:"type:varchar(50); not null; index"`
-	SendStatus    string    `gorm:"type:varchar(50); not null; index"`
-	PaymentStatus string    `gorm:"type:varchar(50); not null; index"`
+	ID            string     `gorm:"type:varchar(150); primaryKey"`
+	Date          time.Time  `gorm:"type:datetime; not null; index"`
+	ClientID      string     `gorm:"type:varchar(50); not null; index"`
+	Value         float64    `gorm:"type:numeric(20,2); not null; index"`
+	Status        string     `gorm:"type:varchar(50); not null; index"`
+	SendStatus    string     `gorm:"type:varchar(50); not null; index"`
+	PaymentStatus string     `gorm:"type:varchar(50); not null; index"`
+	RpsNumber     *int64     `gorm:"type:bigint; null; index"`
+	Pix           *string    `gorm:"type:varchar(512); null"`
+	Due           *time.Time `gorm:"type:datetime; null; index"`
+	Fine          *float64   `gorm:"type:numeric(20,2); null"`
+	Interest      *float64   `gorm:"type:numeric(20,2); null"`
 }
 
 // NewInvoice creates a new invoice domain entity
-func NewInvoice(id, clientID, date, value, status, sendstatus, paymentstatus string) *Invoice {
+func NewInvoice(id, clientID, date, value, status, sendstatus, paymentstatus, pix, due, fine, interest string) *Invoice {
 	invoice := &Invoice{}
 	invoice.ID = id
 	invoice.ClientID = clientID
@@ -54,6 +59,19 @@ func NewInvoice(id, clientID, date, value, status, sendstatus, paymentstatus str
 	invoice.Status = status
 	invoice.SendStatus = sendstatus
 	invoice.PaymentStatus = paymentstatus
+	if pix != "" {
+		invoice.Pix = &pix
+	}
+	if due != "" {
+		d, _ := time.ParseInLocation(pkg.DateFormat, due, local)
+		invoice.Due = &d
+	}
+	if fine != "" {
+		invoice.Fine = invoice.parseCharge(fine)
+	}
+	if interest != "" {
+		invoice.Interest = invoice.parseCharge(interest)
+	}
 	return invoice
 }
 
@@ -83,6 +101,15 @@ func (i *Invoice) Format(repo port.Repository, args ...string) error {
 	if err := i.formatPaymentStatus(filled); err != nil {
 		msg += err.Error() + " | "
 	}
+	if err := i.formatPix(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := i.formatCharges(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := i.validateCancel(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
 	tx := repo.Begin()
 	defer repo.Rollback(tx)
 	if err := i.validateDuplicity(repo, tx, noduplicity); err != nil {
@@ -226,6 +253,72 @@ func (c *Invoice) formatPaymentStatus(filled bool) error {
 	return nil
 }
 
+// formatPix is a method that checks the crc of the pix payload of the invoice
+func (c *Invoice) formatPix() error {
+	if c.Pix == nil {
+		return nil
+	}
+	if *c.Pix = strings.TrimSpace(*c.Pix); *c.Pix == "" {
+		return nil
+	}
+	if !pkg.ValidPixPayload(*c.Pix) {
+		return errors.New(pkg.ErrInvalidPix)
+	}
+	return nil
+}
+
+// formatCharges is a method that validates the due date, the fine and the interest of the invoice
+func (c *Invoice) formatCharges() error {
+	if c.Due != nil && c.Due.IsZero() {
+		return fmt.Errorf(pkg.ErrInvalidDueDate, pkg.DateFormat)
+	}
+	if (c.Fine != nil && (math.IsNaN(*c.Fine) || *c.Fine < 0)) ||
+		(c.Interest != nil && (math.IsNaN(*c.Interest) || *c.Interest < 0)) {
+		return errors.New(pkg.ErrInvalidCharge)
+	}
+	return nil
+}
+
+// validateCancel is a method that validates that a canceled invoice has credit notes of its full value
+func (c *Invoice) validateCancel(repo port.Repository, filled bool) error {
+	if filled || c.Status != pkg.InvoiceStatusCanceled || math.IsNaN(c.Value) || c.Value <= 0 {
+		return nil
+	}
+	notes, err := LoadCreditNotes(repo, c.ID)
+	if err != nil {
+		return err
+	}
+	credited := 0.0
+	for _, n := range notes {
+		credited += n.Value
+	}
+	if credited < c.Value-0.005 {
+		return fmt.Errorf(pkg.ErrCancelWithoutCreditNote, c.Value)
+	}
+	return nil
+}
+
+// Charges returns the sum of the fine and the interest of the invoice
+func (c *Invoice) Charges() float64 {
+	ret := 0.0
+	if c.Fine != nil {
+		ret += *c.Fine
+	}
+	if c.Interest != nil {
+		ret += *c.Interest
+	}
+	return ret
+}
+
+// parseCharge parses a fine or interest value keeping NaN for invalid ones
+func (c *Invoice) parseCharge(value string) *float64 {
+	ret, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		ret = math.NaN()
+	}
+	return &ret
+}
+
 // formatString is a method that formats a string
 func (c *Invoice) formatString(str string) string {
 	str = strings.TrimSpace(str)
@@ -8,18 +8,23 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lavinas/ephemeris/internal/port"
 	"github.com/lavinas/ephemeris/pkg"
 )
 
 // InvoiceItem represents the invoice item entity
+// billed items are linked to the contract and the month billed and package prices also to their package
 type InvoiceItem struct {
-	ID          string  `gorm:"type:varchar(150); primaryKey"`
-	InvoiceID   string  `gorm:"type:varchar(150); not null"`
-	AgendaID    *string `gorm:"type:varchar(50); null"`
-	Value       float64 `gorm:"type:numeric(20,2); not null"`
-	Description string  `gorm:"type:varchar(100); not null"`
+	ID          string     `gorm:"type:varchar(150); primaryKey"`
+	InvoiceID   string     `gorm:"type:varchar(150); not null"`
+	AgendaID    *string    `gorm:"type:varchar(50); null"`
+	ContractID  *string    `gorm:"type:varchar(50); null; index"`
+	PackageID   *string    `gorm:"type:varchar(50); null; index"`
+	Month       *time.Time `gorm:"type:datetime; null; index"`
+	Value       float64    `gorm:"type:numeric(20,2); not null"`
+	Description string     `gorm:"type:varchar(100); not null"`
 }
 
 // NewInvoiceItem creates a new invoice item domain entity
@@ -98,24 +98,18 @@ func (p *Package) TableName() string {
 	return "package"
 }
 
-// GetService is a method that returns the service of the package
-func (p *Package) GetServices(repo port.Repository) ([]*Service, []*float64, error) {
+// GetServices is a method that returns the items of the package with the service and the price of each one
+func (p *Package) GetServices(repo port.Repository) ([]*PackageItem, []*Service, []*float64, error) {
 	services := []*Service{}
 	prices := []*float64{}
-	tx := repo.Begin()
-	defer repo.Rollback(tx)
-	i, _, err := repo.Find(tx, &PackageItem{PackageID: p.ID}, -1, false)
+	items, err := p.GetItems(repo)
 	if err != nil {
-		return nil, nil, err
-	}
-	items := i.(*[]PackageItem)
-	if len(*items) == 0 {
-		return nil, nil, errors.New(pkg.ErrServiceNotFound)
+		return nil, nil, nil, err
 	}
-	for _, item := range *items {
+	for _, item := range items {
 		service, error := item.GetService(repo)
 		if error != nil {
-			return nil, nil, error
+			return nil, nil, nil, error
 		}
 		services = append(services, service)
 		if p.Price != nil && *p.Price > 0 {
@@ -124,7 +118,28 @@ func (p *Package) GetServices(repo port.Repository) ([]*Service, []*float64, err
 			prices = append(prices, item.Price)
 		}
 	}
-	return services, prices, nil
+	return items, services, prices, nil
+}
+
+// GetItems is a method that returns the items of the package sorted by id
+func (p *Package) GetItems(repo port.Repository) ([]*PackageItem, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	i, _, err := repo.Find(tx, &PackageItem{PackageID: p.ID}, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	if i == nil || len(*i.(*[]PackageItem)) == 0 {
+		return nil, errors.New(pkg.ErrServiceNotFound)
+	}
+	items := []*PackageItem{}
+	for _, item := range *i.(*[]PackageItem) {
+		items = append(items, &item)
+	}
+	slices.SortFunc(items, func(a, b *PackageItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return items, nil
 }
 
 // GetRecurrence is a method that returns the recurrence of the package
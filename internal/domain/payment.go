@@ -0,0 +1,276 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// PaymentMatchStatus are the status of a payment received according to its matching with an invoice
+	// or of a payment out refunding a credit note
+	PaymentMatchStatus = []string{pkg.PaymentStatusMatched, pkg.PaymentStatusAmbiguous, pkg.PaymentStatusUnmatched,
+		pkg.PaymentStatusRefund}
+)
+
+// Payment represents a payment received on the bank that settles an invoice when matched
+type Payment struct {
+	ID        string    `gorm:"type:varchar(150); primaryKey"`
+	Date      time.Time `gorm:"type:datetime; not null; index"`
+	Value     float64   `gorm:"type:numeric(20,2); not null; index"`
+	InvoiceID *string   `gorm:"type:varchar(150); null; index"`
+	ClientID  *string   `gorm:"type:varchar(50); null; index"`
+	Reference string    `gorm:"type:varchar(150); null"`
+	Document  *string   `gorm:"type:varchar(20); null; index"`
+	Name      string    `gorm:"type:varchar(100); null"`
+	Status    string    `gorm:"type:varchar(50); not null; index"`
+}
+
+// NewPayment creates a new payment domain entity
+func NewPayment(id, date, value, invoiceID, clientID, reference, document, name, status string) *Payment {
+	local, _ := time.LoadLocation(pkg.Location)
+	payment := &Payment{}
+	payment.ID = id
+	payment.Date, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(date), local)
+	var err error
+	if payment.Value, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+		payment.Value = math.NaN()
+	}
+	if invoiceID = strings.TrimSpace(invoiceID); invoiceID != "" {
+		payment.InvoiceID = &invoiceID
+	}
+	if clientID = strings.TrimSpace(clientID); clientID != "" {
+		payment.ClientID = &clientID
+	}
+	payment.Reference = reference
+	if document = strings.TrimSpace(document); document != "" {
+		payment.Document = &document
+	}
+	payment.Name = name
+	payment.Status = status
+	return payment
+}
+
+// Format formats the payment
+func (p *Payment) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := p.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatDate(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatValue(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatInvoiceID(repo); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatClientID(repo); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatTexts(); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatStatus(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := p.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the payment
+func (p *Payment) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, p, p.ID, false)
+}
+
+// GetID is a method that returns the id of the payment
+func (p *Payment) GetID() string {
+	return p.ID
+}
+
+// Get is a method that returns the payment
+func (p *Payment) Get() port.Domain {
+	return p
+}
+
+// GetEmpty is a method that returns an empty payment
+func (p *Payment) GetEmpty() port.Domain {
+	return &Payment{}
+}
+
+// TableName returns the table name for database
+func (p *Payment) TableName() string {
+	return "payment"
+}
+
+// PaidValues returns the sum of the matched payments by invoice net of the refunds paid out
+func PaidValues(repo port.Repository) (map[string]float64, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &Payment{}, -1, false, "invoice_id is not null",
+		fmt.Sprintf("status in ('%s', '%s')", pkg.PaymentStatusMatched, pkg.PaymentStatusRefund))
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]float64{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, p := range *base.(*[]Payment) {
+		ret[*p.InvoiceID] += p.Value
+	}
+	return ret, nil
+}
+
+// formatID is a method that formats the id of the payment
+func (p *Payment) formatID(filled bool) error {
+	id := p.formatString(p.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 150 {
+		return errors.New(pkg.ErrLongID150)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	p.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatDate is a method that formats the date of the payment
+func (p *Payment) formatDate(filled bool) error {
+	if p.Date.IsZero() && !filled {
+		return fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+	}
+	return nil
+}
+
+// formatValue is a method that formats the value of the payment. Just refunds paid out are negative
+func (p *Payment) formatValue(filled bool) error {
+	if math.IsNaN(p.Value) {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrInvalidValue)
+	}
+	if p.Value < 0 && p.Status != pkg.PaymentStatusRefund {
+		return errors.New(pkg.ErrInvalidValue)
+	}
+	return nil
+}
+
+// formatInvoiceID is a method that formats the invoice of the payment setting its client
+func (p *Payment) formatInvoiceID(repo port.Repository) error {
+	if p.InvoiceID == nil {
+		return nil
+	}
+	id := strings.ToLower(p.formatString(*p.InvoiceID))
+	p.InvoiceID = &id
+	invoice := &Invoice{ID: id}
+	if exists, err := invoice.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrInvoiceNotFound)
+	}
+	if p.ClientID == nil {
+		p.ClientID = &invoice.ClientID
+	}
+	return nil
+}
+
+// formatClientID is a method that formats the client of the payment
+func (p *Payment) formatClientID(repo port.Repository) error {
+	if p.ClientID == nil {
+		return nil
+	}
+	id := p.formatString(*p.ClientID)
+	p.ClientID = &id
+	client := &Client{ID: id}
+	if exists, err := client.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrClientNotFound)
+	}
+	return nil
+}
+
+// formatTexts is a method that formats the reference, the document and the name of the payer
+func (p *Payment) formatTexts() error {
+	p.Reference = p.formatString(p.Reference)
+	if len(p.Reference) > 150 {
+		return errors.New(pkg.ErrLongReference)
+	}
+	p.Name = p.formatString(p.Name)
+	if len(p.Name) > 100 {
+		return errors.New(pkg.ErrLongName)
+	}
+	if p.Document != nil {
+		document := regexp.MustCompile(`\D`).ReplaceAllString(*p.Document, "")
+		if len(document) != 11 && len(document) != 14 {
+			return errors.New(pkg.ErrInvalidDocument)
+		}
+		p.Document = &document
+	}
+	return nil
+}
+
+// formatStatus is a method that formats the status of the payment
+func (p *Payment) formatStatus(filled bool) error {
+	p.Status = strings.ToLower(p.formatString(p.Status))
+	if p.Status == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyPaymentStatus)
+	}
+	if !slices.Contains(PaymentMatchStatus, p.Status) {
+		return fmt.Errorf(pkg.ErrInvalidPaymentStatus, strings.Join(PaymentMatchStatus, ", "))
+	}
+	return nil
+}
+
+// formatString is a method that formats a string
+func (p *Payment) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a payment
+func (p *Payment) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &Payment{}, p.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, p.ID)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// Price represents a price of a package or of one of its items in force from a start date
+// it is general for the package when contract is not informed, otherwise it is a readjustment just for the contract
+type Price struct {
+	ID         string    `gorm:"type:varchar(150); primaryKey"`
+	Date       time.Time `gorm:"type:datetime; not null; index"`
+	PackageID  string    `gorm:"type:varchar(50); not null; index"`
+	ItemID     *string   `gorm:"type:varchar(50); null; index"`
+	ContractID *string   `gorm:"type:varchar(50); null; index"`
+	Start      time.Time `gorm:"type:datetime; not null; index"`
+	Value      *float64  `gorm:"type:decimal(10,2); not null"`
+	Reason     string    `gorm:"type:varchar(100); null"`
+}
+
+// NewPrice creates a new price
+func NewPrice(id, date, packageID, itemID, contractID, start, value, reason string) *Price {
+	local, _ := time.LoadLocation(pkg.Location)
+	price := &Price{}
+	price.ID = id
+	price.Date, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(date), local)
+	price.PackageID = packageID
+	if itemID = strings.TrimSpace(itemID); itemID != "" {
+		price.ItemID = &itemID
+	}
+	if contractID = strings.TrimSpace(contractID); contractID != "" {
+		price.ContractID = &contractID
+	}
+	price.Start, _ = time.ParseInLocation(pkg.DateFormat, strings.TrimSpace(start), local)
+	if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		price.Value = &v
+	}
+	price.Reason = reason
+	return price
+}
+
+// Format formats the price
+func (p *Price) Format(repo port.Repository, args ...string) error {
+	filled := slices.Contains(args, "filled")
+	msg := ""
+	if err := p.formatID(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatPackageID(repo, filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatItemID(repo); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatContractID(repo); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatDates(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatValue(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := p.formatReason(); err != nil {
+		msg += err.Error() + " | "
+	}
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	if err := p.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
+		msg += err.Error() + " | "
+	}
+	if msg != "" {
+		return errors.New(msg[:len(msg)-3])
+	}
+	return nil
+}
+
+// Load is a method that loads the price
+func (p *Price) Load(repo port.Repository) (bool, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	return repo.Get(tx, p, p.ID, false)
+}
+
+// GetID is a method that returns the id of the price
+func (p *Price) GetID() string {
+	return p.ID
+}
+
+// Get is a method that returns the price
+func (p *Price) Get() port.Domain {
+	return p
+}
+
+// GetEmpty is a method that returns an empty price
+func (p *Price) GetEmpty() port.Domain {
+	return &Price{}
+}
+
+// TableName returns the table name for database
+func (p *Price) TableName() string {
+	return "price"
+}
+
+// LoadPrices returns the price history of a package
+func LoadPrices(repo port.Repository, packageID string) ([]*Price, error) {
+	tx := repo.Begin()
+	defer repo.Rollback(tx)
+	base, _, err := repo.Find(tx, &Price{PackageID: packageID}, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	ret := []*Price{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, p := range *base.(*[]Price) {
+		ret = append(ret, &p)
+	}
+	return ret, nil
+}
+
+// PriceInForce returns the price of the package, or of the item if informed, in force on a moment for a contract
+// the latest start wins and, on the same start, the contract readjustment wins the general price
+// It returns nil if there is no price on the history
+func PriceInForce(prices []*Price, contractID string, itemID *string, at time.Time) *Price {
+	var ret *Price
+	for _, p := range prices {
+		if (p.ItemID == nil) != (itemID == nil) || (itemID != nil && *p.ItemID != *itemID) {
+			continue
+		}
+		if p.ContractID != nil && *p.ContractID != contractID {
+			continue
+		}
+		if p.Start.After(at) {
+			continue
+		}
+		if ret == nil || p.Start.After(ret.Start) || (p.Start.Equal(ret.Start) && p.ContractID != nil) {
+			ret = p
+		}
+	}
+	return ret
+}
+
+// formatID is a method that formats the id of the price
+func (p *Price) formatID(filled bool) error {
+	id := p.formatString(p.ID)
+	if id == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if len(id) > 150 {
+		return errors.New(pkg.ErrLongID150)
+	}
+	if len(strings.Split(id, " ")) > 1 {
+		return errors.New(pkg.ErrInvalidID)
+	}
+	p.ID = strings.ToLower(id)
+	return nil
+}
+
+// formatPackageID is a method that formats the package id of the price
+func (p *Price) formatPackageID(repo port.Repository, filled bool) error {
+	p.PackageID = p.formatString(p.PackageID)
+	if p.PackageID == "" {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrEmptyPackageID)
+	}
+	pack := &Package{ID: p.PackageID}
+	if exists, err := pack.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrPackageNotFound)
+	}
+	return nil
+}
+
+// formatItemID is a method that formats the package item of the price checking it belongs to the package
+func (p *Price) formatItemID(repo port.Repository) error {
+	if p.ItemID == nil {
+		return nil
+	}
+	id := p.formatString(*p.ItemID)
+	p.ItemID = &id
+	item := &PackageItem{ID: id}
+	if exists, err := item.Load(repo); err != nil {
+		return err
+	} else if !exists || (p.PackageID != "" && item.PackageID != p.PackageID) {
+		return errors.New(pkg.ErrPackageItemNotFound)
+	}
+	return nil
+}
+
+// formatContractID is a method that formats the contract of the price checking it has the package
+func (p *Price) formatContractID(repo port.Repository) error {
+	if p.ContractID == nil {
+		return nil
+	}
+	id := p.formatString(*p.ContractID)
+	p.ContractID = &id
+	contract := &Contract{ID: id}
+	if exists, err := contract.Load(repo); err != nil {
+		return err
+	} else if !exists {
+		return errors.New(pkg.ErrContractNotFound)
+	}
+	if p.PackageID != "" && contract.PackageID != p.PackageID {
+		return errors.New(pkg.ErrContractPackageMismatch)
+	}
+	return nil
+}
+
+// formatDates is a method that formats the record and start dates of the price
+func (p *Price) formatDates(filled bool) error {
+	if p.Date.IsZero() && !filled {
+		return fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+	}
+	if p.Start.IsZero() && !filled {
+		return fmt.Errorf(pkg.ErrInvalidStartDate, pkg.DateFormat)
+	}
+	return nil
+}
+
+// formatValue is a method that formats the value of the price
+func (p *Price) formatValue(filled bool) error {
+	if p.Value == nil {
+		if filled {
+			return nil
+		}
+		return errors.New(pkg.ErrInvalidValue)
+	}
+	if *p.Value < 0 {
+		return errors.New(pkg.ErrInvalidValue)
+	}
+	return nil
+}
+
+// formatReason is a method that formats the reason of the price
+func (p *Price) formatReason() error {
+	p.Reason = p.formatString(p.Reason)
+	if len(p.Reason) > 100 {
+		return errors.New(pkg.ErrLongReason)
+	}
+	return nil
+}
+
+// formatString is a method that formats a string
+func (p *Price) formatString(str string) string {
+	str = strings.TrimSpace(str)
+	space := regexp.MustCompile(`\s+`)
+	str = space.ReplaceAllString(str, " ")
+	return str
+}
+
+// validateDuplicity is a method that validates the duplicity of a price
+func (p *Price) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
+	if noduplicity {
+		return nil
+	}
+	ok, err := repo.Get(tx, &Price{}, p.ID, false)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf(pkg.ErrAlreadyExists, p.ID)
+	}
+	return nil
+}
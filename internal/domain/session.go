@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 	"strconv"
 	"strings"
@@ -19,6 +20,11 @@ var (
 		pkg.SessionStatusMissed,
 		pkg.SessionStatusCanceled,
 	}
+	kindSession = []string{
+		pkg.SessionKindRegular,
+		pkg.SessionKindAdjust,
+		pkg.SessionKindExtra,
+	}
 	StatusProcess = []string{
 		pkg.ProcessStatusOpenned,
 		pkg.ProcessStatusUnfound,
@@ -29,25 +35,33 @@ var (
 
 // Session represents the session entity
 type Session struct {
-	ID        string    `gorm:"type:varchar(150); primaryKey"`
-	Sequence  *int      `gorm:"type:int; not null"`
-	Date      time.Time `gorm:"type:datetime; not null"`
-	ClientID  string    `gorm:"type:varchar(50); not null; index"`
-	ServiceID string    `gorm:"type:varchar(50); not null; index"`
-	At        time.Time `gorm:"type:datetime; not null"`
-	Status    string    `gorm:"type:varchar(50); not null; index"`
-	Process   string    `gorm:"type:varchar(50); not null; index"`
-	AgendaID  string    `gorm:"type:varchar(150);null,index"`
-	Locked    *bool     `gorm:"type:boolean;null; index"`
+	ID         string    `gorm:"type:varchar(150); primaryKey"`
+	Sequence   *int      `gorm:"type:int; not null"`
+	Date       time.Time `gorm:"type:datetime; not null"`
+	ClientID   string    `gorm:"type:varchar(50); not null; index"`
+	ServiceID  string    `gorm:"type:varchar(50); not null; index"`
+	At         time.Time `gorm:"type:datetime; not null"`
+	Status     string    `gorm:"type:varchar(50); not null; index"`
+	Process    string    `gorm:"type:varchar(50); not null; index"`
+	AgendaID   string    `gorm:"type:varchar(150);null,index"`
+	ContractID *string   `gorm:"type:varchar(50); null; index"`
+	Kind       string    `gorm:"type:varchar(50); null; index"`
+	Discount   *float64  `gorm:"type:decimal(10,2); null"`
+	Percent    *bool     `gorm:"type:boolean; null"`
+	Locked     *bool     `gorm:"type:boolean;null; index"`
 }
 
 // NewSession creates a new session domain entity
-func NewSession(id, sequence, date, clientID, serviceID, at, status string, process, agendaID string) *Session {
+func NewSession(id, sequence, date, clientID, serviceID, at, status string, process, agendaID, contractID, kind,
+	discount string) *Session {
 	session := &Session{}
 	session.ID = id
 	session.ClientID = clientID
 	session.ServiceID = serviceID
 	session.AgendaID = agendaID
+	if contractID != "" {
+		session.ContractID = &contractID
+	}
 	local, _ := time.LoadLocation(pkg.Location)
 	session.Date, _ = time.ParseInLocation(pkg.DateFormat, date, local)
 	var err error
@@ -60,6 +74,16 @@ func NewSession(id, sequence, date, clientID, serviceID, at, status string, proc
 	if seq, err := strconv.Atoi(sequence); err == nil {
 		session.Sequence = &seq
 	}
+	session.Kind = kind
+	if discount != "" {
+		percent := strings.HasSuffix(discount, "%")
+		d, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(discount, "%")), 64)
+		if err != nil {
+			d = math.NaN()
+		}
+		session.Discount = &d
+		session.Percent = &percent
+	}
 	return session
 }
 
@@ -94,6 +118,15 @@ func (s *Session) Format(repo port.Repository, args ...string) error {
 	if err := s.formatAgendaID(repo); err != nil {
 		msg += err.Error() + " | "
 	}
+	if err := s.formatContractID(repo); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := s.formatKind(filled); err != nil {
+		msg += err.Error() + " | "
+	}
+	if err := s.formatDiscount(); err != nil {
+		msg += err.Error() + " | "
+	}
 	tx := repo.Begin()
 	defer repo.Rollback(tx)
 	if err := s.validateDuplicity(repo, tx, slices.Contains(args, "noduplicity")); err != nil {
@@ -161,6 +194,48 @@ func (s *Session) Unlock(repo port.Repository) error {
 	return nil
 }
 
+// GetKind returns the kind of the session considering the ones without kind as regular
+func (s *Session) GetKind() string {
+	if s.Kind == "" {
+		return pkg.SessionKindRegular
+	}
+	return s.Kind
+}
+
+// IsPercent returns if the discount of the session is a percentage of the price
+func (s *Session) IsPercent() bool {
+	return s.Percent != nil && *s.Percent
+}
+
+// ApplyDiscount returns the price with the session discount applied
+func (s *Session) ApplyDiscount(price float64) float64 {
+	if s.Discount == nil {
+		return price
+	}
+	if s.IsPercent() {
+		price = price * (100 - *s.Discount) / 100
+	} else {
+		price = price - *s.Discount
+	}
+	return math.Round(math.Max(price, 0)*100) / 100
+}
+
+// NextSequence returns the next sequence of the session scope
+// reading the sessions on the transaction informed, so the sessions added on it are counted
+func (s *Session) NextSequence(repo port.Repository, tx interface{}, byContract bool) (int, error) {
+	scopes, err := LoadSessionScopes(repo, tx, s.ClientID, byContract)
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	for _, se := range scopes.Sessions[scopes.Scope(s)] {
+		if se.ID != s.ID && se.Sequence != nil && *se.Sequence >= next {
+			next = *se.Sequence + 1
+		}
+	}
+	return next, nil
+}
+
 // TableName returns the table name for database
 func (s *Session) TableName() string {
 	return "session"
@@ -270,7 +345,8 @@ func (s *Session) formatProcess(filled bool) error {
 	return nil
 }
 
-// FormatSequence is a method that formats the package item entity
+// FormatSequence is a method that formats the session sequence
+// sessions added without sequence get the next one of the client or contract before being formatted
 func (s *Session) formatSequence(filled bool) error {
 	if s.Sequence == nil {
 		if filled {
@@ -298,6 +374,50 @@ func (s *Session) formatAgendaID(repo port.Repository) error {
 	return nil
 }
 
+// formatContractID formats the contract id checking if it belongs to the session client
+func (s *Session) formatContractID(repo port.Repository) error {
+	if s.ContractID == nil {
+		return nil
+	}
+	contract := &Contract{ID: *s.ContractID}
+	if ok, err := contract.Load(repo); err != nil {
+		return err
+	} else if !ok {
+		return errors.New(pkg.ErrContractNotFound)
+	}
+	if s.ClientID != "" && contract.ClientID != s.ClientID {
+		return errors.New(pkg.ErrContractClientMismatch)
+	}
+	return nil
+}
+
+// formatKind formats the session kind assuming the default kind for sessions without it
+func (s *Session) formatKind(filled bool) error {
+	s.Kind = strings.ToLower(strings.TrimSpace(s.Kind))
+	if s.Kind == "" {
+		if !filled {
+			s.Kind = pkg.DefaultSessionKind
+		}
+		return nil
+	}
+	if !slices.Contains(kindSession, s.Kind) {
+		return fmt.Errorf(pkg.ErrInvalidKind, strings.Join(kindSession, ", "))
+	}
+	return nil
+}
+
+// formatDiscount formats the session discount
+// percentual discounts should be between 0 and 100 and absolute ones should not be negative
+func (s *Session) formatDiscount() error {
+	if s.Discount == nil {
+		return nil
+	}
+	if math.IsNaN(*s.Discount) || *s.Discount < 0 || (s.IsPercent() && *s.Discount > 100) {
+		return errors.New(pkg.ErrInvalidDiscount)
+	}
+	return nil
+}
+
 // validateDuplicity is a method that validates the duplicity of a client
 func (s *Session) validateDuplicity(repo port.Repository, tx interface{}, noduplicity bool) error {
 	if noduplicity {
@@ -312,3 +432,74 @@ func (s *Session) validateDuplicity(repo port.Repository, tx interface{}, nodupl
 	}
 	return nil
 }
+
+// SessionScopes groups the sessions of a client by sequence scope
+// by contract, sessions are numbered per contract informed or of the tied agenda,
+// the ones without contract are numbered per client
+type SessionScopes struct {
+	ByContract bool
+	Contracts  map[string]string
+	Sessions   map[string][]*Session
+}
+
+// LoadSessionScopes loads the sessions of the client grouped by sequence scope and sorted by date
+// on the transaction informed or on a new one if it is nil
+func LoadSessionScopes(repo port.Repository, tx interface{}, clientID string, byContract bool) (*SessionScopes, error) {
+	scopes := &SessionScopes{ByContract: byContract, Contracts: map[string]string{}, Sessions: map[string][]*Session{}}
+	if tx == nil {
+		tx = repo.Begin()
+		defer repo.Rollback(tx)
+	}
+	if byContract {
+		agendas, _, err := repo.Find(tx, &Agenda{ClientID: clientID}, -1, false, "contract_id is not null")
+		if err != nil {
+			return nil, err
+		}
+		if agendas != nil {
+			for _, a := range *agendas.(*[]Agenda) {
+				scopes.Contracts[a.ID] = *a.ContractID
+			}
+		}
+	}
+	sessions, _, err := repo.Find(tx, &Session{ClientID: clientID}, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		return scopes, nil
+	}
+	for _, se := range *sessions.(*[]Session) {
+		scope := scopes.Scope(&se)
+		scopes.Sessions[scope] = append(scopes.Sessions[scope], &se)
+	}
+	for _, ss := range scopes.Sessions {
+		slices.SortFunc(ss, func(a, b *Session) int {
+			if c := a.At.Compare(b.At); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ID, b.ID)
+		})
+	}
+	return scopes, nil
+}
+
+// Scope returns the sequence scope of a session: the contract id or empty for the client scope
+func (c *SessionScopes) Scope(s *Session) string {
+	if !c.ByContract {
+		return ""
+	}
+	if s.ContractID != nil {
+		return *s.ContractID
+	}
+	return c.Contracts[s.AgendaID]
+}
+
+// Keys returns the sorted scopes keys
+func (c *SessionScopes) Keys() []string {
+	keys := []string{}
+	for k := range c.Sessions {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -12,19 +12,46 @@ import (
 
 func All() []interface{} {
 	return []interface{}{
+		&AccountingExport{},
 		&AgendaCrud{},
 		&AgendaMake{},
+		&AgendaCheckin{},
 		&ClientCrud{},
+		&ClientContactCrud{},
+		&ClientNormalize{},
+		&ClientStatement{},
 		&ContractCrud{},
+		&ContractPauseCrud{},
+		&ContractPayerCrud{},
+		&ContractEnd{},
+		&ContractChange{},
+		&ContractReadjust{},
+		&CreditNoteCrud{},
 		&InvoiceCrud{},
+		&InvoiceMake{},
+		&InvoiceSend{},
+		&InvoiceNfse{},
+		&InvoicePix{},
+		&InvoiceOverdue{},
+		&InvoiceCredit{},
+		&InvoiceRender{},
 		&InvoiceItemCrud{},
+		&PaymentCrud{},
+		&PaymentImport{},
 		&PackageCrud{},
 		&PackageAppend{},
+		&PriceCrud{},
 		&RecurrenceCrud{},
+		&ReportRevenue{},
+		&ReportAging{},
+		&ReportForecast{},
+		&ReportSponsor{},
+		&ReportAttendance{},
 		&ServiceCrud{},
 		&SessionCrud{},
 		&SessionTie{},
 		&SessionForce{},
+		&SessionSequence{},
 	}
 }
 
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// AccountingExport represents the dto for exporting the journal entries of a month to the accounting
+type AccountingExport struct {
+	Base
+	Object string `json:"-" command:"name:accounting;key;pos:2-"`
+	Action string `json:"-" command:"name:export;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	Month  string `json:"month" command:"name:month;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// AccountingExportOut represents the dto for the journal entries exported on output
+type AccountingExportOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	Date     string `json:"date" command:"name:date"`
+	Debit    string `json:"debit" command:"name:debit"`
+	Credit   string `json:"credit" command:"name:credit"`
+	Value    string `json:"value" command:"name:value"`
+	Document string `json:"document" command:"name:document"`
+	History  string `json:"history" command:"name:history"`
+}
+
+// Validate is a method that validates the dto
+func (a *AccountingExport) Validate() error {
+	if strings.TrimSpace(a.Month) == "" {
+		return errors.New(pkg.ErrMonthEmpty)
+	}
+	if _, _, err := a.GetRange(); err != nil {
+		return err
+	}
+	return validateReport(nil, "", a.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (a *AccountingExport) GetCommand() string {
+	return a.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (a *AccountingExport) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{}}
+}
+
+// GetOut is a method that returns the dto out
+func (a *AccountingExport) GetOut() port.DTOOut {
+	return &AccountingExportOut{Sort: a.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (a *AccountingExport) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetRange returns the first and the last moment of the month exported
+func (a *AccountingExport) GetRange() (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	month, err := time.ParseInLocation(pkg.MonthFormat, strings.TrimSpace(a.Month), local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+	}
+	return checkRange(month, month)
+}
+
+// GetFormat returns the output format of the entries or empty for the table
+func (a *AccountingExport) GetFormat() string {
+	return reportFormat(a.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (a *AccountingExportOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*AccountingExportOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, a.Sort)
+	return ret
+}
@@ -0,0 +1,122 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	checkinStatus = []string{pkg.AgendaStatusDone, pkg.AgendaStatusMissed}
+)
+
+// AgendaCheckin represents the dto for checking in agendas and creating its sessions
+type AgendaCheckin struct {
+	Base
+	Object   string `json:"-" command:"name:agenda;key;pos:2-"`
+	Action   string `json:"-" command:"name:checkin,done;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Day      string `json:"day" command:"name:day;pos:3+"`
+	Week     string `json:"week" command:"name:week;pos:3+"`
+	Status   string `json:"status" command:"name:status;pos:3+"`
+}
+
+// AgendaCheckinOut represents the dto for checking in agendas on output
+type AgendaCheckinOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	ID        string `json:"id" command:"name:id"`
+	ClientID  string `json:"client" command:"name:client"`
+	Start     string `json:"start" command:"name:start"`
+	Status    string `json:"status" command:"name:status"`
+	SessionID string `json:"session" command:"name:session/error"`
+}
+
+// Validate is a method that validates the dto
+func (a *AgendaCheckin) Validate() error {
+	if a.ID == "" && a.Day == "" && a.Week == "" {
+		return errors.New(pkg.ErrCheckinParams)
+	}
+	if a.ID != "" && (a.Day != "" || a.Week != "" || a.ClientID != "") {
+		return errors.New(pkg.ErrCheckinIDAndRange)
+	}
+	if a.Day != "" && a.Week != "" {
+		return errors.New(pkg.ErrCheckinDayAndWeek)
+	}
+	if _, _, err := a.GetRange(); err != nil {
+		return err
+	}
+	if a.Status != "" && !slices.Contains(checkinStatus, a.Status) {
+		return fmt.Errorf(pkg.ErrInvalidStatus, strings.Join(checkinStatus, ", "))
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (a *AgendaCheckin) GetCommand() string {
+	return a.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (a *AgendaCheckin) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Agenda{ID: a.ID, ClientID: a.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (a *AgendaCheckin) GetOut() port.DTOOut {
+	return &AgendaCheckinOut{Sort: a.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (a *AgendaCheckin) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetStatus is a method that returns the status to be set on agendas and sessions
+func (a *AgendaCheckin) GetStatus() string {
+	if a.Status == "" {
+		return pkg.AgendaStatusDone
+	}
+	return a.Status
+}
+
+// GetRange is a method that returns the interval of dates of the day or week informed
+// the week starts on monday of the week of the informed date
+func (a *AgendaCheckin) GetRange() (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	switch {
+	case a.Day != "":
+		day, err := time.ParseInLocation(pkg.DateFormat, a.Day, local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+		}
+		return day, day.AddDate(0, 0, 1).Add(time.Nanosecond * -1), nil
+	case a.Week != "":
+		day, err := time.ParseInLocation(pkg.DateFormat, a.Week, local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+		}
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7).Add(time.Nanosecond * -1), nil
+	default:
+		return time.Time{}, time.Time{}, nil
+	}
+}
+
+// GetDTO is a method that returns the dto out
+func (a *AgendaCheckinOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		ret = append(ret, slice.(*AgendaCheckinOut))
+	}
+	pkg.NewCommands().Sort(ret, a.Sort)
+	return ret
+}
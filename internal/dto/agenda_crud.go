@@ -14,28 +14,29 @@ import (
 // AgendaCrud represents the dto for getting a agenda
 type AgendaCrud struct {
 	Base
-	Object     string `json:"-" command:"name:agenda;key;pos:2-"`
-	Action     string `json:"-" command:"name:add,get,up;key;pos:2-"`
-	Sort       string `json:"sort" command:"name:sort;pos:3+"`
-	Csv        string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
-	ID         string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
-	Date       string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
-	ClientID   string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
-	ServiceID  string `json:"service" command:"name:service;pos:3+;trans:service_id,string" csv:"service"`
-	ContractID string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
-	Start      string `json:"start" command:"name:start;pos:3+;trans:start,time" csv:"start"`
-	End        string `json:"end" command:"name:end;pos:3+;trans:end,time" csv:"end"`
-	Price      string `json:"price" command:"name:price;pos:3+;trans:price,float" csv:"price"`
-	Kind       string `json:"kind" command:"name:kind;pos:3+;trans:kind,string" csv:"kind"`
-	Status     string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
-	Bond       string `json:"bond" command:"name:bond;pos:3+;trans:bond,string" csv:"bond"`
-	Billing    string `json:"billing" command:"name:billing;pos:3+;trans:billing_month,time" csv:"billing"`
+	Object       string `json:"-" command:"name:agenda;key;pos:2-"`
+	Action       string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort         string `json:"sort" command:"name:sort;pos:3+"`
+	Csv          string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID           string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Date         string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	ClientID     string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	ServiceID    string `json:"service" command:"name:service;pos:3+;trans:service_id,string" csv:"service"`
+	ContractID   string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
+	Start        string `json:"start" command:"name:start;pos:3+;trans:start,time" csv:"start"`
+	End          string `json:"end" command:"name:end;pos:3+;trans:end,time" csv:"end"`
+	Price        string `json:"price" command:"name:price;pos:3+;trans:price,float" csv:"price"`
+	Kind         string `json:"kind" command:"name:kind;pos:3+;trans:kind,string" csv:"kind"`
+	Status       string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
+	Bond         string `json:"bond" command:"name:bond;pos:3+;trans:bond,string" csv:"bond"`
+	Billing      string `json:"billing" command:"name:billing;pos:3+;trans:billing_month,time" csv:"billing"`
+	Professional string `json:"professional" command:"name:professional;pos:3+;trans:professional,string" csv:"professional"`
 }
 
 // Validate is a method that validates the dto
 func (a *AgendaCrud) Validate() error {
 	if a.Csv != "" && (a.ID != "" || a.Date != "" || a.ClientID != "" || a.ContractID != "" || a.Start != "" || a.End != "" ||
-		a.Kind != "" || a.Status != "" || a.Bond != "" || a.Billing != "" || a.Price != "" || a.ServiceID != "") {
+		a.Kind != "" || a.Status != "" || a.Bond != "" || a.Billing != "" || a.Price != "" || a.ServiceID != "" || a.Professional != "") {
 		return errors.New(pkg.ErrCsvAndParams)
 	}
 	return nil
@@ -92,19 +93,24 @@ func (a *AgendaCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 			if ag.Price != nil {
 				price = fmt.Sprintf("%.2f", *ag.Price)
 			}
+			professional := ""
+			if ag.Professional != nil {
+				professional = *ag.Professional
+			}
 			ret = append(ret, &AgendaCrud{
-				ID:         ag.ID,
-				Date:       ag.Date.Format(pkg.DateFormat),
-				ClientID:   ag.ClientID,
-				ServiceID:  ag.ServiceID,
-				ContractID: contractID,
-				Start:      ag.Start.Format(pkg.DateTimeFormat),
-				End:        ag.End.Format(pkg.DateTimeFormat),
-				Price:      price,
-				Kind:       ag.Kind,
-				Status:     ag.Status,
-				Bond:       bond,
-				Billing:    billing,
+				ID:           ag.ID,
+				Date:         ag.Date.Format(pkg.DateFormat),
+				ClientID:     ag.ClientID,
+				ServiceID:    ag.ServiceID,
+				ContractID:   contractID,
+				Start:        ag.Start.Format(pkg.DateTimeFormat),
+				End:          ag.End.Format(pkg.DateTimeFormat),
+				Price:        price,
+				Kind:         ag.Kind,
+				Status:       ag.Status,
+				Bond:         bond,
+				Billing:      billing,
+				Professional: professional,
 			})
 		}
 	}
@@ -131,7 +137,7 @@ func (a *AgendaCrud) getDomain(one *AgendaCrud) port.Domain {
 	}
 	a.trim()
 	return domain.NewAgenda(one.ID, one.Date, one.ClientID, one.ServiceID, one.ContractID,
-		one.Start, one.End, one.Price, one.Kind, one.Status, one.Bond, one.Billing)
+		one.Start, one.End, one.Price, one.Kind, one.Status, one.Bond, one.Billing, one.Professional)
 }
 
 // trim is a method that trims the fields of the dto
@@ -148,4 +154,5 @@ func (a *AgendaCrud) trim() {
 	a.Status = strings.TrimSpace(a.Status)
 	a.Bond = strings.TrimSpace(a.Bond)
 	a.Billing = strings.TrimSpace(a.Billing)
+	a.Professional = strings.TrimSpace(a.Professional)
 }
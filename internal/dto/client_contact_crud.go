@@ -0,0 +1,113 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ClientContactCrud represents the dto for crud of client contacts
+type ClientContactCrud struct {
+	Base
+	Object   string `json:"-" command:"name:contact;key;pos:2-"`
+	Action   string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	Csv      string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID       string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	ClientID string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	Name     string `json:"name" command:"name:name;pos:3+;trans:name,string" csv:"name"`
+	Role     string `json:"role" command:"name:role;pos:3+;trans:role,string" csv:"role"`
+	Email    string `json:"email" command:"name:email;pos:3+;trans:email,string" csv:"email"`
+	Phone    string `json:"phone" command:"name:phone;pos:3+;trans:phone,string" csv:"phone"`
+	Contact  string `json:"contact" command:"name:channel;pos:3+;trans:contact,string" csv:"channel"`
+}
+
+// Validate is a method that validates the dto
+func (c *ClientContactCrud) Validate() error {
+	if c.Csv != "" && (c.ID != "" || c.ClientID != "" || c.Name != "" || c.Role != "" || c.Email != "" ||
+		c.Phone != "" || c.Contact != "") {
+		return errors.New(pkg.ErrCsvAndParams)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ClientContactCrud) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ClientContactCrud) GetDomain() []port.Domain {
+	if c.Csv != "" {
+		domains := []port.Domain{}
+		contacts := []*ClientContactCrud{}
+		c.ReadCSV(&contacts, c.Csv)
+		for _, contact := range contacts {
+			contact.Action = c.Action
+			contact.Object = c.Object
+			domains = append(domains, c.getDomain(contact))
+		}
+		return domains
+	}
+	return []port.Domain{c.getDomain(c)}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ClientContactCrud) GetOut() port.DTOOut {
+	return c
+}
+
+// GetDTO is a method that returns the dto
+func (c *ClientContactCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		contacts := slice.(*[]domain.ClientContact)
+		for _, contact := range *contacts {
+			email := ""
+			if contact.Email != nil {
+				email = *contact.Email
+			}
+			phone := ""
+			if contact.Phone != nil {
+				phone = pkg.FormatPhone(*contact.Phone)
+			}
+			ret = append(ret, &ClientContactCrud{
+				ID:       contact.ID,
+				ClientID: contact.ClientID,
+				Name:     contact.Name,
+				Role:     contact.Role,
+				Email:    email,
+				Phone:    phone,
+				Contact:  contact.Contact,
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (c *ClientContactCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return c.getInstructions(c, domain)
+}
+
+// getDomain is a method that returns the domain of one client contact
+func (c *ClientContactCrud) getDomain(one *ClientContactCrud) port.Domain {
+	one.trim()
+	return domain.NewClientContact(one.ID, one.ClientID, one.Name, one.Role, one.Email, one.Phone, one.Contact)
+}
+
+// trim is a method that trims the dto
+func (c *ClientContactCrud) trim() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.ClientID = strings.TrimSpace(c.ClientID)
+	c.Name = strings.TrimSpace(c.Name)
+	c.Role = strings.TrimSpace(c.Role)
+	c.Email = strings.TrimSpace(c.Email)
+	c.Phone = strings.TrimSpace(c.Phone)
+	c.Contact = strings.TrimSpace(c.Contact)
+}
@@ -13,22 +13,34 @@ import (
 // ClientGet represents the dto for getting a client
 type ClientCrud struct {
 	Base
-	Object   string `json:"-" command:"name:client;key;pos:2-"`
-	Action   string `json:"-" command:"name:add,get,up;key;pos:2-"`
-	Sort     string `json:"sort" command:"name:sort;pos:3+"`
-	Csv      string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
-	ID       string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
-	Date     string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
-	Name     string `json:"name" command:"name:name;pos:3+;trans:name,string" csv:"name"`
-	Email    string `json:"email" command:"name:email;pos:3+;trans:email,string" csv:"email"`
-	Phone    string `json:"phone" command:"name:phone;pos:3+;trans:phone,string" csv:"phone"`
-	Document string `json:"document" command:"name:document;pos:3+;trans:document,string" csv:"document"`
-	Contact  string `json:"contact" command:"name:contact;pos:3+;trans:contact,string" csv:"contact"`
+	Object       string `json:"-" command:"name:client;key;pos:2-"`
+	Action       string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort         string `json:"sort" command:"name:sort;pos:3+"`
+	Csv          string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID           string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Date         string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	Name         string `json:"name" command:"name:name;pos:3+;trans:name,string" csv:"name"`
+	Email        string `json:"email" command:"name:email;pos:3+;trans:email,string" csv:"email"`
+	Phone        string `json:"phone" command:"name:phone;pos:3+;trans:phone,string" csv:"phone"`
+	Document     string `json:"document" command:"name:document;pos:3+;trans:document,string" csv:"document"`
+	Contact      string `json:"contact" command:"name:contact;pos:3+;trans:contact,string" csv:"contact"`
+	ZipCode      string `json:"zip" command:"name:zip;pos:3+;trans:zip_code,string" csv:"zip"`
+	Street       string `json:"street" command:"name:street;pos:3+;trans:street,string" csv:"street"`
+	Number       string `json:"number" command:"name:number;pos:3+;trans:number,string" csv:"number"`
+	Complement   string `json:"complement" command:"name:complement;pos:3+;trans:complement,string" csv:"complement"`
+	District     string `json:"district" command:"name:district;pos:3+;trans:district,string" csv:"district"`
+	City         string `json:"city" command:"name:city;pos:3+;trans:city,string" csv:"city"`
+	CityCode     string `json:"ibge" command:"name:ibge;pos:3+;trans:city_code,string" csv:"ibge"`
+	State        string `json:"state" command:"name:state;pos:3+;trans:state,string" csv:"state"`
+	MunicipalReg string `json:"registration" command:"name:registration;pos:3+;trans:municipal_reg,string" csv:"registration"`
+	TaxRegime    string `json:"regime" command:"name:regime;pos:3+;trans:tax_regime,string" csv:"regime"`
 }
 
 // Validate is a method that validates the dto
 func (c *ClientCrud) Validate() error {
-	if c.Csv != "" && (c.ID != "" || c.Date != "" || c.Name != "" || c.Email != "" || c.Phone != "" || c.Document != "" || c.Contact != "") {
+	if c.Csv != "" && (c.ID != "" || c.Date != "" || c.Name != "" || c.Email != "" || c.Phone != "" || c.Document != "" || c.Contact != "" ||
+		c.ZipCode != "" || c.Street != "" || c.Number != "" || c.Complement != "" || c.District != "" || c.City != "" ||
+		c.CityCode != "" || c.State != "" || c.MunicipalReg != "" || c.TaxRegime != "") {
 		return errors.New(pkg.ErrCsvAndParams)
 	}
 	return nil
@@ -71,14 +83,30 @@ func (c *ClientCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 			if client.Document != nil {
 				doc = *client.Document
 			}
+			value := func(field *string) string {
+				if field == nil {
+					return ""
+				}
+				return *field
+			}
 			dto := ClientCrud{
-				ID:       client.ID,
-				Date:     client.Date.Format(pkg.DateFormat),
-				Name:     client.Name,
-				Email:    client.Email,
-				Phone:    client.Phone,
-				Document: doc,
-				Contact:  client.Contact,
+				ID:           client.ID,
+				Date:         client.Date.Format(pkg.DateFormat),
+				Name:         client.Name,
+				Email:        client.Email,
+				Phone:        pkg.FormatPhone(client.Phone),
+				Document:     doc,
+				Contact:      client.Contact,
+				ZipCode:      value(client.ZipCode),
+				Street:       value(client.Street),
+				Number:       value(client.Number),
+				Complement:   value(client.Complement),
+				District:     value(client.District),
+				City:         value(client.City),
+				CityCode:     value(client.CityCode),
+				State:        value(client.State),
+				MunicipalReg: value(client.MunicipalReg),
+				TaxRegime:    value(client.TaxRegime),
 			}
 			ret = append(ret, &dto)
 		}
@@ -102,7 +130,9 @@ func (c *ClientCrud) getDomain(one *ClientCrud) port.Domain {
 		one.Contact = pkg.DefaultContact
 	}
 	one.trim()
-	return domain.NewClient(one.ID, one.Date, one.Name, one.Email, one.Phone, one.Document, one.Contact)
+	return domain.NewClient(one.ID, one.Date, one.Name, one.Email, one.Phone, one.Document, one.Contact, one.ZipCode,
+		one.Street, one.Number, one.Complement, one.District, one.City, one.CityCode, one.State, one.MunicipalReg,
+		one.TaxRegime)
 }
 
 // trim is a method that trims the fields of the dto
@@ -114,4 +144,14 @@ func (c *ClientCrud) trim() {
 	c.Phone = strings.TrimSpace(c.Phone)
 	c.Document = strings.TrimSpace(c.Document)
 	c.Contact = strings.TrimSpace(c.Contact)
+	c.ZipCode = strings.TrimSpace(c.ZipCode)
+	c.Street = strings.TrimSpace(c.Street)
+	c.Number = strings.TrimSpace(c.Number)
+	c.Complement = strings.TrimSpace(c.Complement)
+	c.District = strings.TrimSpace(c.District)
+	c.City = strings.TrimSpace(c.City)
+	c.CityCode = strings.TrimSpace(c.CityCode)
+	c.State = strings.TrimSpace(c.State)
+	c.MunicipalReg = strings.TrimSpace(c.MunicipalReg)
+	c.TaxRegime = strings.TrimSpace(c.TaxRegime)
 }
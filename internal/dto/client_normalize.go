@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ClientNormalize represents the dto for normalizing the stored phones of clients and their contacts
+type ClientNormalize struct {
+	Base
+	Object string `json:"-" command:"name:client;key;pos:2-"`
+	Action string `json:"-" command:"name:normalize;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	ID     string `json:"id" command:"name:id;pos:3+"`
+}
+
+// ClientNormalizeOut represents the dto for normalizing phones on output
+type ClientNormalizeOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID string `json:"client" command:"name:client"`
+	Contact  string `json:"contact" command:"name:contact"`
+	Old      string `json:"old" command:"name:old"`
+	New      string `json:"new" command:"name:new"`
+	Result   string `json:"result" command:"name:result"`
+}
+
+// Validate is a method that validates the dto
+func (c *ClientNormalize) Validate() error {
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ClientNormalize) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ClientNormalize) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Client{ID: c.ID}}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ClientNormalize) GetOut() port.DTOOut {
+	return &ClientNormalizeOut{Sort: c.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (c *ClientNormalize) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (c *ClientNormalizeOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ClientNormalizeOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
@@ -0,0 +1,117 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// outputFormats are the output formats of the client statement and of the reports
+	outputFormats = []string{pkg.OutputTable, pkg.OutputCSV, pkg.OutputJSON}
+)
+
+// ClientStatement represents the dto for the account statement of a client
+type ClientStatement struct {
+	Base
+	Object string `json:"-" command:"name:client;key;pos:2-"`
+	Action string `json:"-" command:"name:statement;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	ID     string `json:"id" command:"name:id;pos:3+"`
+	From   string `json:"from" command:"name:from;pos:3+"`
+	To     string `json:"to" command:"name:to;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ClientStatementOut represents the dto for the lines of the account statement on output
+type ClientStatementOut struct {
+	Sort        string `json:"sort" command:"name:sort;pos:3+"`
+	Date        string `json:"date" command:"name:date"`
+	Type        string `json:"type" command:"name:type"`
+	Document    string `json:"document" command:"name:document"`
+	Description string `json:"description" command:"name:description"`
+	Debit       string `json:"debit" command:"name:debit"`
+	Credit      string `json:"credit" command:"name:credit"`
+	Balance     string `json:"balance" command:"name:balance"`
+}
+
+// Validate is a method that validates the dto
+func (c *ClientStatement) Validate() error {
+	if strings.TrimSpace(c.ID) == "" {
+		return errors.New(pkg.ErrEmptyClientID)
+	}
+	if _, _, err := c.GetRange(); err != nil {
+		return err
+	}
+	if c.Format != "" && !slices.Contains(outputFormats, c.Format) {
+		return fmt.Errorf(pkg.ErrInvalidOutput, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ClientStatement) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ClientStatement) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Client{ID: strings.TrimSpace(c.ID)}}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ClientStatement) GetOut() port.DTOOut {
+	return &ClientStatementOut{Sort: c.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (c *ClientStatement) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetRange returns the first and the last moment of the statement period
+// the from date is zero when not informed and the to date is the end of today when not informed
+func (c *ClientStatement) GetRange() (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	from, to := time.Time{}, time.Now().In(local)
+	var err error
+	if c.From != "" {
+		if from, err = time.ParseInLocation(pkg.DateFormat, c.From, local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidFrom, pkg.DateFormat)
+		}
+	}
+	if c.To != "" {
+		if to, err = time.ParseInLocation(pkg.DateFormat, c.To, local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidTo, pkg.DateFormat)
+		}
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, local).AddDate(0, 0, 1).Add(time.Nanosecond * -1)
+	if !from.IsZero() && from.After(to) {
+		return time.Time{}, time.Time{}, errors.New(pkg.ErrFromAfterTo)
+	}
+	return from, to, nil
+}
+
+// GetFormat returns the output format of the statement or empty for the table
+func (c *ClientStatement) GetFormat() string {
+	if c.Format == pkg.OutputTable {
+		return ""
+	}
+	return c.Format
+}
+
+// GetDTO is a method that returns the dto out
+func (c *ClientStatementOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ClientStatementOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractChange represents the dto for changing the package of a contract
+type ContractChange struct {
+	Base
+	Object    string `json:"-" command:"name:contract;key;pos:2-"`
+	Action    string `json:"-" command:"name:change;key;pos:2-"`
+	ID        string `json:"id" command:"name:id;pos:3+"`
+	PackageID string `json:"package" command:"name:package;pos:3+"`
+	From      string `json:"from" command:"name:from;pos:3+"`
+}
+
+// ContractChangeOut represents the dto for changing the package of a contract on output
+type ContractChangeOut struct {
+	ID          string `json:"id" command:"name:id"`
+	SuccessorID string `json:"successor" command:"name:successor"`
+	From        string `json:"from" command:"name:from"`
+	Deleted     string `json:"deleted" command:"name:deleted"`
+	Canceled    string `json:"canceled" command:"name:canceled"`
+	Agendas     string `json:"agendas" command:"name:agendas"`
+	InvoiceID   string `json:"invoice" command:"name:adjustment"`
+	Value       string `json:"value" command:"name:value"`
+}
+
+// Validate is a method that validates the dto
+func (c *ContractChange) Validate() error {
+	if c.ID == "" {
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if c.PackageID == "" {
+		return errors.New(pkg.ErrEmptyPackageID)
+	}
+	if _, err := c.GetFrom(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ContractChange) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ContractChange) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Contract{ID: c.ID}}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ContractChange) GetOut() port.DTOOut {
+	return &ContractChangeOut{}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (c *ContractChange) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetFrom is a method that returns the effective date of the change
+func (c *ContractChange) GetFrom() (time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	from, err := time.ParseInLocation(pkg.DateFormat, c.From, local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(pkg.ErrInvalidFrom, pkg.DateFormat)
+	}
+	return from, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (c *ContractChangeOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	return []port.DTOOut{domainIn.(*ContractChangeOut)}
+}
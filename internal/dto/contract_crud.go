@@ -14,27 +14,31 @@ import (
 // ContractCrud represents the dto for getting a contract
 type ContractCrud struct {
 	Base
-	Object      string `json:"-" command:"name:contract;key;pos:2-"`
-	Action      string `json:"-" command:"name:add,get,up;key;pos:2-"`
-	Sort        string `json:"sort" command:"name:sort;pos:3+"`
-	Csv         string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
-	ID          string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
-	Date        string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
-	ClientID    string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
-	SponsorID   string `json:"sponsor" command:"name:sponsor;pos:3+;trans:sponsor_id,string" csv:"sponsor"`
-	PackageID   string `json:"package" command:"name:package;pos:3+;trans:package_id,string" csv:"package"`
-	BillingType string `json:"billing" command:"name:billing;pos:3+;trans:billing_type,string" csv:"billing"`
-	DueDay      string `json:"due" command:"name:due;pos:3+;trans:due_day,int64" csv:"due"`
-	Start       string `json:"start" command:"name:start;pos:3+;trans:start,time" csv:"start"`
-	End         string `json:"end" command:"name:end;pos:3+;trans:end,time" csv:"end"`
-	Bond        string `json:"bond" command:"name:bond;pos:3+;trans:bond,string" csv:"bond"`
-	Locked      string `json:"locked" command:"name:locked;pos:3+;trans:locked,string" csv:"locked"`
+	Object       string `json:"-" command:"name:contract;key;pos:2-"`
+	Action       string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort         string `json:"sort" command:"name:sort;pos:3+"`
+	Csv          string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID           string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Date         string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	ClientID     string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	SponsorID    string `json:"sponsor" command:"name:sponsor;pos:3+;trans:sponsor_id,string" csv:"sponsor"`
+	PackageID    string `json:"package" command:"name:package;pos:3+;trans:package_id,string" csv:"package"`
+	BillingType  string `json:"billing" command:"name:billing;pos:3+;trans:billing_type,string" csv:"billing"`
+	DueDay       string `json:"due" command:"name:due;pos:3+;trans:due_day,int64" csv:"due"`
+	Start        string `json:"start" command:"name:start;pos:3+;trans:start,time" csv:"start"`
+	End          string `json:"end" command:"name:end;pos:3+;trans:end,time" csv:"end"`
+	Bond         string `json:"bond" command:"name:bond;pos:3+;trans:bond,string" csv:"bond"`
+	EndReason    string `json:"reason" command:"name:reason;pos:3+;trans:end_reason,string" csv:"reason"`
+	Origin       string `json:"origin" command:"name:origin;pos:3+;trans:origin,string" csv:"origin"`
+	Professional string `json:"professional" command:"name:professional;pos:3+;trans:professional,string" csv:"professional"`
+	Locked       string `json:"locked" command:"name:locked;pos:3+;trans:locked,string" csv:"locked"`
 }
 
 // Validate is a method that validates the dto
 func (c *ContractCrud) Validate() error {
 	if c.Csv != "" && (c.ID != "" || c.Date != "" || c.ClientID != "" || c.SponsorID != "" || c.PackageID != "" ||
-		c.BillingType != "" || c.DueDay != "" || c.Start != "" || c.End != "" || c.Bond != "" || c.Locked != "") {
+		c.BillingType != "" || c.DueDay != "" || c.Start != "" || c.End != "" || c.Bond != "" || c.EndReason != "" ||
+		c.Origin != "" || c.Professional != "" || c.Locked != "") {
 		return errors.New(pkg.ErrCsvAndParams)
 	}
 	return nil
@@ -89,22 +93,37 @@ func (c *ContractCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 			if contract.Bond != nil {
 				bond = *contract.Bond
 			}
+			reason := ""
+			if contract.EndReason != nil {
+				reason = *contract.EndReason
+			}
+			origin := ""
+			if contract.Origin != nil {
+				origin = *contract.Origin
+			}
+			professional := ""
+			if contract.Professional != nil {
+				professional = *contract.Professional
+			}
 			locked := ""
 			if contract.Locked != nil && *contract.Locked {
 				locked = "******"
 			}
 			ret = append(ret, &ContractCrud{
-				ID:          contract.ID,
-				Date:        contract.Date.Format(pkg.DateFormat),
-				ClientID:    contract.ClientID,
-				SponsorID:   sponsor,
-				PackageID:   contract.PackageID,
-				BillingType: contract.BillingType,
-				DueDay:      due,
-				Start:       contract.Start.Format(pkg.DateTimeFormat),
-				End:         end,
-				Bond:        bond,
-				Locked:      locked,
+				ID:           contract.ID,
+				Date:         contract.Date.Format(pkg.DateFormat),
+				ClientID:     contract.ClientID,
+				SponsorID:    sponsor,
+				PackageID:    contract.PackageID,
+				BillingType:  contract.BillingType,
+				DueDay:       due,
+				Start:        contract.Start.Format(pkg.DateTimeFormat),
+				End:          end,
+				Bond:         bond,
+				EndReason:    reason,
+				Origin:       origin,
+				Professional: professional,
+				Locked:       locked,
 			})
 		}
 	}
@@ -133,7 +152,8 @@ func (c *ContractCrud) getDomain(one *ContractCrud) port.Domain {
 		one.BillingType = pkg.DefaultBillingType
 	}
 	one.trim()
-	return domain.NewContract(one.ID, one.Date, one.ClientID, one.SponsorID, one.PackageID, one.BillingType, one.DueDay, one.Start, one.End, one.Bond)
+	return domain.NewContract(one.ID, one.Date, one.ClientID, one.SponsorID, one.PackageID, one.BillingType, one.DueDay, one.Start, one.End, one.Bond, one.EndReason,
+		one.Origin, one.Professional)
 }
 
 func (c *ContractCrud) trim() {
@@ -147,5 +167,8 @@ func (c *ContractCrud) trim() {
 	c.Start = strings.TrimSpace(c.Start)
 	c.End = strings.TrimSpace(c.End)
 	c.Bond = strings.TrimSpace(c.Bond)
+	c.EndReason = strings.TrimSpace(c.EndReason)
+	c.Origin = strings.TrimSpace(c.Origin)
+	c.Professional = strings.TrimSpace(c.Professional)
 	c.Locked = strings.TrimSpace(c.Locked)
 }
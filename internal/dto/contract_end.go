@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractEnd represents the dto for ending a contract
+type ContractEnd struct {
+	Base
+	Object string `json:"-" command:"name:contract;key;pos:2-"`
+	Action string `json:"-" command:"name:end;key;pos:2-"`
+	ID     string `json:"id" command:"name:id;pos:3+"`
+	Date   string `json:"date" command:"name:date;pos:3+"`
+	Reason string `json:"reason" command:"name:reason;pos:3+"`
+}
+
+// ContractEndOut represents the dto for ending a contract on output
+type ContractEndOut struct {
+	ID        string `json:"id" command:"name:id"`
+	End       string `json:"end" command:"name:end"`
+	Deleted   string `json:"deleted" command:"name:deleted"`
+	Canceled  string `json:"canceled" command:"name:canceled"`
+	InvoiceID string `json:"invoice" command:"name:settlement"`
+	Value     string `json:"value" command:"name:value"`
+}
+
+// Validate is a method that validates the dto
+func (c *ContractEnd) Validate() error {
+	if c.ID == "" {
+		return errors.New(pkg.ErrEmptyID)
+	}
+	if _, err := c.GetDate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ContractEnd) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ContractEnd) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Contract{ID: c.ID}}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ContractEnd) GetOut() port.DTOOut {
+	return &ContractEndOut{}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (c *ContractEnd) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetDate is a method that returns the end date of the contract
+func (c *ContractEnd) GetDate() (time.Time, error) {
+	if c.Date == "" {
+		return time.Time{}, fmt.Errorf(pkg.ErrInvalidEndDate, pkg.DateFormat)
+	}
+	local, _ := time.LoadLocation(pkg.Location)
+	date, err := time.ParseInLocation(pkg.DateFormat, c.Date, local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(pkg.ErrInvalidEndDate, pkg.DateFormat)
+	}
+	return date, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (c *ContractEndOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	return []port.DTOOut{domainIn.(*ContractEndOut)}
+}
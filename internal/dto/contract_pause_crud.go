@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractPauseCrud represents the dto for crud of contract pauses
+type ContractPauseCrud struct {
+	Base
+	Object     string `json:"-" command:"name:pause;key;pos:2-"`
+	Action     string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	Csv        string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID         string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	ContractID string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
+	From       string `json:"from" command:"name:from;pos:3+;trans:start,time" csv:"from"`
+	To         string `json:"to" command:"name:to;pos:3+;trans:end,time" csv:"to"`
+	Reason     string `json:"reason" command:"name:reason;pos:3+;trans:reason,string" csv:"reason"`
+}
+
+// Validate is a method that validates the dto
+func (c *ContractPauseCrud) Validate() error {
+	if c.Csv != "" && (c.ID != "" || c.ContractID != "" || c.From != "" || c.To != "" || c.Reason != "") {
+		return errors.New(pkg.ErrCsvAndParams)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ContractPauseCrud) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ContractPauseCrud) GetDomain() []port.Domain {
+	if c.Csv != "" {
+		domains := []port.Domain{}
+		pauses := []*ContractPauseCrud{}
+		c.ReadCSV(&pauses, c.Csv)
+		for _, pause := range pauses {
+			pause.Action = c.Action
+			pause.Object = c.Object
+			domains = append(domains, c.getDomain(pause))
+		}
+		return domains
+	}
+	return []port.Domain{c.getDomain(c)}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ContractPauseCrud) GetOut() port.DTOOut {
+	return c
+}
+
+// GetDTO is a method that returns the dto
+func (c *ContractPauseCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		pauses := slice.(*[]domain.ContractPause)
+		for _, pause := range *pauses {
+			ret = append(ret, &ContractPauseCrud{
+				ID:         pause.ID,
+				ContractID: pause.ContractID,
+				From:       pause.Start.Format(pkg.DateFormat),
+				To:         pause.End.Format(pkg.DateFormat),
+				Reason:     pause.Reason,
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (c *ContractPauseCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return c.getInstructions(c, domain)
+}
+
+// getDomain is a method that returns the domain of one contract pause
+func (c *ContractPauseCrud) getDomain(one *ContractPauseCrud) port.Domain {
+	one.trim()
+	return domain.NewContractPause(one.ID, one.ContractID, one.From, one.To, one.Reason)
+}
+
+// trim is a method that trims the dto
+func (c *ContractPauseCrud) trim() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.ContractID = strings.TrimSpace(c.ContractID)
+	c.From = strings.TrimSpace(c.From)
+	c.To = strings.TrimSpace(c.To)
+	c.Reason = strings.TrimSpace(c.Reason)
+}
@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractPayerCrud represents the dto for crud of contract payers
+type ContractPayerCrud struct {
+	Base
+	Object     string `json:"-" command:"name:payer;key;pos:2-"`
+	Action     string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	Csv        string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID         string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	ContractID string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
+	ClientID   string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	Share      string `json:"share" command:"name:share;pos:3+" csv:"share"`
+}
+
+// Validate is a method that validates the dto
+func (c *ContractPayerCrud) Validate() error {
+	if c.Csv != "" && (c.ID != "" || c.ContractID != "" || c.ClientID != "" || c.Share != "") {
+		return errors.New(pkg.ErrCsvAndParams)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ContractPayerCrud) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ContractPayerCrud) GetDomain() []port.Domain {
+	if c.Csv != "" {
+		domains := []port.Domain{}
+		payers := []*ContractPayerCrud{}
+		c.ReadCSV(&payers, c.Csv)
+		for _, payer := range payers {
+			payer.Action = c.Action
+			payer.Object = c.Object
+			domains = append(domains, c.getDomain(payer))
+		}
+		return domains
+	}
+	return []port.Domain{c.getDomain(c)}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ContractPayerCrud) GetOut() port.DTOOut {
+	return c
+}
+
+// GetDTO is a method that returns the dto
+func (c *ContractPayerCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		payers := slice.(*[]domain.ContractPayer)
+		for _, payer := range *payers {
+			ret = append(ret, &ContractPayerCrud{
+				ID:         payer.ID,
+				ContractID: payer.ContractID,
+				ClientID:   payer.ClientID,
+				Share:      payer.ShareText(),
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (c *ContractPayerCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return c.getInstructions(c, domain)
+}
+
+// getDomain is a method that returns the domain of one contract payer
+func (c *ContractPayerCrud) getDomain(one *ContractPayerCrud) port.Domain {
+	one.trim()
+	return domain.NewContractPayer(one.ID, one.ContractID, one.ClientID, one.Share)
+}
+
+// trim is a method that trims the dto
+func (c *ContractPayerCrud) trim() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.ContractID = strings.TrimSpace(c.ContractID)
+	c.ClientID = strings.TrimSpace(c.ClientID)
+	c.Share = strings.TrimSpace(c.Share)
+}
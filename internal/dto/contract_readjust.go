@@ -0,0 +1,131 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ContractReadjust represents the dto for the yearly price readjustment of contracts
+// by a percentage or by an index table loaded from a csv file with month and value columns
+type ContractReadjust struct {
+	Base
+	Object   string `json:"-" command:"name:contract;key;pos:2-"`
+	Action   string `json:"-" command:"name:readjust;key;pos:2-"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Date     string `json:"date" command:"name:date;pos:3+"`
+	Percent  string `json:"percent" command:"name:percent;pos:3+"`
+	Index    string `json:"index" command:"name:index;pos:3+"`
+}
+
+// ContractReadjustOut represents the dto for the readjustment of contracts on output
+type ContractReadjustOut struct {
+	ContractID  string `json:"contract" command:"name:contract"`
+	Anniversary string `json:"anniversary" command:"name:anniversary"`
+	ItemID      string `json:"item" command:"name:item"`
+	Old         string `json:"old" command:"name:old"`
+	New         string `json:"new" command:"name:new"`
+	Result      string `json:"result" command:"name:result"`
+}
+
+// PriceIndex represents one month of a price index table
+type PriceIndex struct {
+	Month string `csv:"month"`
+	Value string `csv:"value"`
+}
+
+// Validate is a method that validates the dto
+func (c *ContractReadjust) Validate() error {
+	if _, err := c.GetDate(); err != nil {
+		return err
+	}
+	if (c.Percent == "") == (c.Index == "") {
+		return errors.New(pkg.ErrPercentOrIndex)
+	}
+	if c.Percent != "" {
+		if _, err := strconv.ParseFloat(c.Percent, 64); err != nil {
+			return errors.New(pkg.ErrInvalidPercent)
+		}
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *ContractReadjust) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *ContractReadjust) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Contract{ID: c.ID, ClientID: c.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (c *ContractReadjust) GetOut() port.DTOOut {
+	return &ContractReadjustOut{}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// just contracts started before the date and not ended before it are readjusted
+func (c *ContractReadjust) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	date, err := c.GetDate()
+	if err != nil {
+		return nil, nil, err
+	}
+	p1 := fmt.Sprintf("start <= '%s'", date.Format("2006-01-02 15:04:05"))
+	p2 := fmt.Sprintf("end is null or end >= '%s'", date.Format("2006-01-02 15:04:05"))
+	return domain, []interface{}{p1, p2}, nil
+}
+
+// GetDate is a method that returns the reference date of the readjustment
+func (c *ContractReadjust) GetDate() (time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	date, err := time.ParseInLocation(pkg.DateFormat, c.Date, local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+	}
+	return date, nil
+}
+
+// GetPercent is a method that returns the percentage of the readjustment
+func (c *ContractReadjust) GetPercent() float64 {
+	percent, _ := strconv.ParseFloat(c.Percent, 64)
+	return percent
+}
+
+// GetIndex is a method that returns the monthly percentages of the index table by month
+func (c *ContractReadjust) GetIndex() (map[string]float64, error) {
+	rows := []*PriceIndex{}
+	if err := c.ReadCSV(&rows, c.Index); err != nil {
+		return nil, err
+	}
+	ret := map[string]float64{}
+	for _, row := range rows {
+		month, err := time.Parse(pkg.MonthFormat, strings.TrimSpace(row.Month))
+		if err != nil {
+			return nil, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(row.Value), 64)
+		if err != nil {
+			return nil, errors.New(pkg.ErrInvalidPercent)
+		}
+		ret[month.Format(pkg.MonthFormat)] = value
+	}
+	return ret, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (c *ContractReadjustOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ContractReadjustOut) {
+		ret = append(ret, out)
+	}
+	return ret
+}
@@ -0,0 +1,114 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// CreditNoteCrud represents the dto for getting the credit notes of the invoices
+// credit notes are issued by the invoice credit command
+type CreditNoteCrud struct {
+	Base
+	Object    string `json:"-" command:"name:credit;key;pos:2-"`
+	Action    string `json:"-" command:"name:get;key;pos:2-"`
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	ID        string `json:"id" command:"name:id;pos:3+;trans:id,string"`
+	Date      string `json:"date" command:"name:date;pos:3+;trans:date,time"`
+	InvoiceID string `json:"invoice" command:"name:invoice;pos:3+;trans:invoice_id,string"`
+	ClientID  string `json:"client" command:"name:client;pos:3+;trans:client_id,string"`
+	ItemID    string `json:"item" command:"name:item;pos:3+;trans:item_id,string"`
+	AgendaID  string `json:"agenda" command:"name:agenda;pos:3+;trans:agenda_id,string"`
+	Value     string `json:"value" command:"name:value;pos:3+;trans:value,numeric"`
+	Carried   string `json:"carried" command:"name:carried;pos:3+;trans:credit,numeric"`
+	Reason    string `json:"reason" command:"name:reason;pos:3+;trans:reason,string"`
+	Status    string `json:"status" command:"name:status;pos:3+;trans:status,string"`
+	AppliedID string `json:"applied" command:"name:applied;pos:3+;trans:applied_id,string"`
+}
+
+// Validate is a method that validates the dto
+func (c *CreditNoteCrud) Validate() error {
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *CreditNoteCrud) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *CreditNoteCrud) GetDomain() []port.Domain {
+	c.trim()
+	note := &domain.CreditNote{ID: c.ID, InvoiceID: c.InvoiceID, ClientID: c.ClientID, Reason: c.Reason, Status: c.Status}
+	if c.ItemID != "" {
+		note.ItemID = &c.ItemID
+	}
+	if c.AgendaID != "" {
+		note.AgendaID = &c.AgendaID
+	}
+	if c.AppliedID != "" {
+		note.AppliedID = &c.AppliedID
+	}
+	return []port.Domain{note}
+}
+
+// GetOut is a method that returns the dto out
+func (c *CreditNoteCrud) GetOut() port.DTOOut {
+	return c
+}
+
+// GetDTO is a method that returns the dto
+func (c *CreditNoteCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		notes := slice.(*[]domain.CreditNote)
+		for _, note := range *notes {
+			item, agenda, applied := "", "", ""
+			if note.ItemID != nil {
+				item = *note.ItemID
+			}
+			if note.AgendaID != nil {
+				agenda = *note.AgendaID
+			}
+			if note.AppliedID != nil {
+				applied = *note.AppliedID
+			}
+			ret = append(ret, &CreditNoteCrud{
+				ID:        note.ID,
+				Date:      note.Date.Format(pkg.DateFormat),
+				InvoiceID: note.InvoiceID,
+				ClientID:  note.ClientID,
+				ItemID:    item,
+				AgendaID:  agenda,
+				Value:     fmt.Sprintf("%.2f", note.Value),
+				Carried:   fmt.Sprintf("%.2f", note.Credit),
+				Reason:    note.Reason,
+				Status:    note.Status,
+				AppliedID: applied,
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (c *CreditNoteCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return c.getInstructions(c, domain)
+}
+
+// trim is a method that trims the dto
+func (c *CreditNoteCrud) trim() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.InvoiceID = strings.TrimSpace(c.InvoiceID)
+	c.ClientID = strings.TrimSpace(c.ClientID)
+	c.ItemID = strings.TrimSpace(c.ItemID)
+	c.AgendaID = strings.TrimSpace(c.AgendaID)
+	c.Reason = strings.TrimSpace(c.Reason)
+	c.Status = strings.TrimSpace(c.Status)
+	c.AppliedID = strings.TrimSpace(c.AppliedID)
+}
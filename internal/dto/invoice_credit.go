@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceCredit represents the dto for issuing a credit note of an invoice, of one of its items or of a billed agenda
+type InvoiceCredit struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:credit;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ItemID   string `json:"item" command:"name:item;pos:3+"`
+	AgendaID string `json:"agenda" command:"name:agenda;pos:3+"`
+	Value    string `json:"value" command:"name:value;pos:3+"`
+	Reason   string `json:"reason" command:"name:reason;pos:3+"`
+	Date     string `json:"date" command:"name:date;pos:3+"`
+	Refund   string `json:"refund" command:"name:refund;pos:3+"`
+}
+
+// InvoiceCreditOut represents the dto for issuing a credit note on output
+type InvoiceCreditOut struct {
+	Sort          string `json:"sort" command:"name:sort;pos:3+"`
+	CreditNoteID  string `json:"credit" command:"name:credit"`
+	InvoiceID     string `json:"invoice" command:"name:invoice"`
+	ClientID      string `json:"client" command:"name:client"`
+	Value         string `json:"value" command:"name:value"`
+	Reduced       string `json:"reduced" command:"name:reduced"`
+	Carried       string `json:"carried" command:"name:carried"`
+	Refunded      string `json:"refunded" command:"name:refunded"`
+	Status        string `json:"status" command:"name:status"`
+	PaymentStatus string `json:"payment" command:"name:payment"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceCredit) Validate() error {
+	if strings.TrimSpace(i.ID) == "" {
+		return errors.New(pkg.ErrEmptyInvoice)
+	}
+	if i.Value != "" {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(i.Value), 64); err != nil {
+			return errors.New(pkg.ErrInvalidValue)
+		}
+	}
+	if i.Date != "" {
+		if _, err := time.Parse(pkg.DateFormat, strings.TrimSpace(i.Date)); err != nil {
+			return fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+		}
+	}
+	if i.Refund != "" && i.Refund != pkg.Yes {
+		return fmt.Errorf(pkg.ErrInvalidYes, "refund")
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoiceCredit) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+// the id of the credit note is set on issuing and the date is today if not informed
+func (i *InvoiceCredit) GetDomain() []port.Domain {
+	date := strings.TrimSpace(i.Date)
+	if date == "" {
+		local, _ := time.LoadLocation(pkg.Location)
+		date = time.Now().In(local).Format(pkg.DateFormat)
+	}
+	return []port.Domain{domain.NewCreditNote("", date, strings.TrimSpace(i.ID), i.ItemID, i.AgendaID, i.Value, i.Reason)}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceCredit) GetOut() port.DTOOut {
+	return &InvoiceCreditOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (i *InvoiceCredit) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// IsFull returns if the value is not informed so the credit note is of the value not credited yet
+func (i *InvoiceCredit) IsFull() bool {
+	return strings.TrimSpace(i.Value) == ""
+}
+
+// IsRefund returns if the value already paid should be refunded instead of carried to the next invoice
+func (i *InvoiceCredit) IsRefund() bool {
+	return i.Refund == pkg.Yes
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceCreditOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoiceCreditOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
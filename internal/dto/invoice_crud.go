@@ -24,11 +24,16 @@ type InvoiceCrud struct {
 	Status        string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
 	SendStatus    string `json:"send_status" command:"name:send_status;pos:3+;trans:send_status,string" csv:"send_status"`
 	PaymentStatus string `json:"payment_status" command:"name:payment_status;pos:3+;trans:payment_status,string" csv:"payment_status"`
+	Pix           string `json:"pix" command:"name:pix;pos:3+;trans:pix,string" csv:"pix"`
+	Due           string `json:"due" command:"name:due;pos:3+;trans:due,time" csv:"due"`
+	Fine          string `json:"fine" command:"name:fine;pos:3+;trans:fine,numeric" csv:"fine"`
+	Interest      string `json:"interest" command:"name:interest;pos:3+;trans:interest,numeric" csv:"interest"`
 }
 
 // Validate is a method that validates the dto
 func (i *InvoiceCrud) Validate() error {
-	if i.Csv != "" && (i.ID != "" || i.Date != "" || i.ClientID != "" || i.Value != "" || i.Status != "" || i.SendStatus != "" || i.PaymentStatus != "") {
+	if i.Csv != "" && (i.ID != "" || i.Date != "" || i.ClientID != "" || i.Value != "" || i.Status != "" || i.SendStatus != "" || i.PaymentStatus != "" || i.Pix != "" ||
+		i.Due != "" || i.Fine != "" || i.Interest != "") {
 		return errors.New(pkg.ErrCsvAndParams)
 	}
 	return nil
@@ -67,6 +72,22 @@ func (i *InvoiceCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 	for _, slice := range slices {
 		invoices := slice.(*[]domain.Invoice)
 		for _, invoice := range *invoices {
+			pix := ""
+			if invoice.Pix != nil {
+				pix = *invoice.Pix
+			}
+			due := ""
+			if invoice.Due != nil {
+				due = invoice.Due.Format(pkg.DateFormat)
+			}
+			fine := ""
+			if invoice.Fine != nil {
+				fine = strconv.FormatFloat(*invoice.Fine, 'f', 2, 64)
+			}
+			interest := ""
+			if invoice.Interest != nil {
+				interest = strconv.FormatFloat(*invoice.Interest, 'f', 2, 64)
+			}
 			ret = append(ret, &InvoiceCrud{
 				ID:            invoice.ID,
 				Date:          invoice.Date.Format(pkg.DateFormat),
@@ -75,6 +96,10 @@ func (i *InvoiceCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 				Status:        invoice.Status,
 				SendStatus:    invoice.SendStatus,
 				PaymentStatus: invoice.PaymentStatus,
+				Pix:           pix,
+				Due:           due,
+				Fine:          fine,
+				Interest:      interest,
 			})
 		}
 	}
@@ -103,7 +128,8 @@ func (i *InvoiceCrud) getDomain(one *InvoiceCrud) port.Domain {
 		one.PaymentStatus = pkg.DefaultInvoicePaymentStatus
 	}
 	one.trim()
-	return domain.NewInvoice(one.ID, one.ClientID, one.Date, one.Value, one.Status, one.SendStatus, one.PaymentStatus)
+	return domain.NewInvoice(one.ID, one.ClientID, one.Date, one.Value, one.Status, one.SendStatus, one.PaymentStatus, one.Pix,
+		one.Due, one.Fine, one.Interest)
 }
 
 // trim is a method that trims the dto
@@ -115,4 +141,8 @@ func (i *InvoiceCrud) trim() {
 	i.Status = strings.TrimSpace(i.Status)
 	i.SendStatus = strings.TrimSpace(i.SendStatus)
 	i.PaymentStatus = strings.TrimSpace(i.PaymentStatus)
+	i.Pix = strings.TrimSpace(i.Pix)
+	i.Due = strings.TrimSpace(i.Due)
+	i.Fine = strings.TrimSpace(i.Fine)
+	i.Interest = strings.TrimSpace(i.Interest)
 }
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceMake represents the dto for making the invoices of a month
+type InvoiceMake struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:make,generate;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Month    string `json:"month" command:"name:month;pos:3+"`
+}
+
+// InvoiceMakeOut represents the dto for making invoices on output
+type InvoiceMakeOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id"`
+	ClientID string `json:"client" command:"name:client"`
+	Month    string `json:"month" command:"name:month"`
+	Items    string `json:"items" command:"name:items"`
+	Value    string `json:"value" command:"name:value"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceMake) Validate() error {
+	if i.Month == "" {
+		return errors.New(pkg.ErrMonthEmpty)
+	}
+	if _, err := time.Parse(pkg.MonthFormat, i.Month); err != nil {
+		return fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+// make is also an agenda command, so invoice make is always routed as generate
+func (i *InvoiceMake) GetCommand() string {
+	return "generate"
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoiceMake) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Contract{ClientID: i.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceMake) GetOut() port.DTOOut {
+	return &InvoiceMakeOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// it filters the contracts active on the month or on the next one, billed in advance when pre-paid
+func (i *InvoiceMake) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	firstday, lastday, err := i.GetMonth()
+	if err != nil {
+		return nil, nil, err
+	}
+	lastday = firstday.AddDate(0, 2, 0).Add(time.Nanosecond * -1)
+	p1 := fmt.Sprintf("start <= '%s'", lastday.Format("2006-01-02 15:04:05"))
+	p2 := fmt.Sprintf("end is null or end >= '%s'", firstday.Format("2006-01-02 15:04:05"))
+	return domain, []interface{}{p1, p2}, nil
+}
+
+// GetMonth is a method that returns the first and last moment of the month
+func (i *InvoiceMake) GetMonth() (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	month, err := time.ParseInLocation(pkg.MonthFormat, i.Month, local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+	}
+	return month, month.AddDate(0, 1, 0).Add(time.Nanosecond * -1), nil
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceMakeOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		ret = append(ret, slice.(*InvoiceMakeOut))
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceNfse represents the dto for generating the NFS-e xml of the invoices
+// the xml is checked by a subset of the ABRASF 2.02 layout, not by the official schemas
+type InvoiceNfse struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:nfse;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Month    string `json:"month" command:"name:month;pos:3+"`
+}
+
+// InvoiceNfseOut represents the dto for generating the NFS-e xml on output
+type InvoiceNfseOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	InvoiceID string `json:"invoice" command:"name:invoice"`
+	ClientID  string `json:"client" command:"name:client"`
+	Rps       string `json:"rps" command:"name:rps"`
+	Value     string `json:"value" command:"name:value"`
+	Iss       string `json:"iss" command:"name:iss"`
+	File      string `json:"file" command:"name:file"`
+	Result    string `json:"result" command:"name:result"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceNfse) Validate() error {
+	if i.ID == "" && i.Month == "" {
+		return errors.New(pkg.ErrInvoiceSendParams)
+	}
+	if i.Month != "" {
+		if _, err := time.Parse(pkg.MonthFormat, i.Month); err != nil {
+			return fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoiceNfse) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoiceNfse) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{ID: i.ID, ClientID: i.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceNfse) GetOut() port.DTOOut {
+	return &InvoiceNfseOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// it filters the active invoices of the month by the month prefix of their ids
+func (i *InvoiceNfse) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	extras := []interface{}{fmt.Sprintf("status = '%s'", pkg.InvoiceStatusActive)}
+	if i.Month != "" {
+		month, err := time.Parse(pkg.MonthFormat, i.Month)
+		if err != nil {
+			return nil, nil, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+		extras = append(extras, fmt.Sprintf("id like '%s%%'", month.Format("2006_01_")))
+	}
+	return domain, extras, nil
+}
+
+// GetMonth is a method that returns the month of the batch or a zero time if a single invoice was asked
+func (i *InvoiceNfse) GetMonth() time.Time {
+	if i.ID != "" || i.Month == "" {
+		return time.Time{}
+	}
+	month, _ := time.Parse(pkg.MonthFormat, i.Month)
+	return month
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceNfseOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoiceNfseOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceOverdue represents the dto for the daily process of the overdue invoices
+type InvoiceOverdue struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:overdue;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Date     string `json:"date" command:"name:date;pos:3+"`
+}
+
+// InvoiceOverdueOut represents the dto for the overdue invoices on output
+type InvoiceOverdueOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	InvoiceID string `json:"invoice" command:"name:invoice"`
+	ClientID  string `json:"client" command:"name:client"`
+	Due       string `json:"due" command:"name:due"`
+	Days      string `json:"days" command:"name:days"`
+	Value     string `json:"value" command:"name:value"`
+	Fine      string `json:"fine" command:"name:fine"`
+	Interest  string `json:"interest" command:"name:interest"`
+	Dunning   string `json:"dunning" command:"name:dunning"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceOverdue) Validate() error {
+	_, err := i.GetDate()
+	return err
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoiceOverdue) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoiceOverdue) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{ClientID: i.ClientID, Status: pkg.InvoiceStatusActive}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceOverdue) GetOut() port.DTOOut {
+	return &InvoiceOverdueOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// it filters the invoices not paid nor refunds with the due date before the date of the process
+func (i *InvoiceOverdue) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	date, err := i.GetDate()
+	if err != nil {
+		return nil, nil, err
+	}
+	open := []string{pkg.InvoicePaymentStatusOpen, pkg.InvoicePaymentStatusLate, pkg.InvoicePaymentStatusUnder}
+	extras := []interface{}{
+		"due is not null",
+		"value > 0",
+		fmt.Sprintf("due < '%s'", date.Format(pkg.DefaultDateFormat)),
+		fmt.Sprintf("payment_status in ('%s')", strings.Join(open, "', '")),
+	}
+	return domain, extras, nil
+}
+
+// GetDate is a method that returns the date of the process or today if it is not informed
+func (i *InvoiceOverdue) GetDate() (time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	if i.Date == "" {
+		now := time.Now().In(local)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, local), nil
+	}
+	date, err := time.ParseInLocation(pkg.DateFormat, i.Date, local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(pkg.ErrInvalidDateFormat, pkg.DateFormat)
+	}
+	return date, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceOverdueOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoiceOverdueOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoicePix represents the dto for generating the PIX payload and QR code of the invoices
+type InvoicePix struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:pix;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Month    string `json:"month" command:"name:month;pos:3+"`
+}
+
+// InvoicePixOut represents the dto for generating the PIX payload on output
+type InvoicePixOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	InvoiceID string `json:"invoice" command:"name:invoice"`
+	ClientID  string `json:"client" command:"name:client"`
+	Value     string `json:"value" command:"name:value"`
+	File      string `json:"file" command:"name:file"`
+	Pix       string `json:"pix" command:"name:pix"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoicePix) Validate() error {
+	if i.ID == "" && i.Month == "" {
+		return errors.New(pkg.ErrInvoiceSendParams)
+	}
+	if i.Month != "" {
+		if _, err := time.Parse(pkg.MonthFormat, i.Month); err != nil {
+			return fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoicePix) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoicePix) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{ID: i.ID, ClientID: i.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoicePix) GetOut() port.DTOOut {
+	return &InvoicePixOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// it filters the active invoices of the month by the month prefix of their ids
+func (i *InvoicePix) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	extras := []interface{}{fmt.Sprintf("status = '%s'", pkg.InvoiceStatusActive)}
+	if i.Month != "" {
+		month, err := time.Parse(pkg.MonthFormat, i.Month)
+		if err != nil {
+			return nil, nil, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+		extras = append(extras, fmt.Sprintf("id like '%s%%'", month.Format("2006_01_")))
+	}
+	return domain, extras, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoicePixOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoicePixOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceRender represents the dto for rendering an invoice to a pdf or html file with the templates
+type InvoiceRender struct {
+	Base
+	Object string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action string `json:"-" command:"name:render;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	ID     string `json:"id" command:"name:id;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// InvoiceRenderOut represents the dto for rendering an invoice on output
+type InvoiceRenderOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	InvoiceID string `json:"invoice" command:"name:invoice"`
+	ClientID  string `json:"client" command:"name:client"`
+	Format    string `json:"format" command:"name:format"`
+	File      string `json:"file" command:"name:file"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceRender) Validate() error {
+	if strings.TrimSpace(i.ID) == "" {
+		return errors.New(pkg.ErrEmptyInvoice)
+	}
+	if format := i.GetFormat(); format != pkg.RenderPDF && format != pkg.RenderHTML {
+		return fmt.Errorf(pkg.ErrInvalidOutput, strings.Join([]string{pkg.RenderPDF, pkg.RenderHTML}, ", "))
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoiceRender) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoiceRender) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{ID: strings.ToLower(strings.TrimSpace(i.ID))}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceRender) GetOut() port.DTOOut {
+	return &InvoiceRenderOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (i *InvoiceRender) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetFormat returns the format of the file rendered or pdf if not informed
+func (i *InvoiceRender) GetFormat() string {
+	format := strings.ToLower(strings.TrimSpace(i.Format))
+	if format == "" {
+		return pkg.DefaultInvoiceRender
+	}
+	return format
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceRenderOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoiceRenderOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceSend represents the dto for sending the invoices to the contacts of the clients
+type InvoiceSend struct {
+	Base
+	Object   string `json:"-" command:"name:invoice;key;pos:2-"`
+	Action   string `json:"-" command:"name:send;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ID       string `json:"id" command:"name:id;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+	Month    string `json:"month" command:"name:month;pos:3+"`
+}
+
+// InvoiceSendOut represents the dto for sending invoices on output
+type InvoiceSendOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	InvoiceID string `json:"invoice" command:"name:invoice"`
+	ClientID  string `json:"client" command:"name:client"`
+	Name      string `json:"name" command:"name:name"`
+	Role      string `json:"role" command:"name:role"`
+	Channel   string `json:"channel" command:"name:channel"`
+	Address   string `json:"address" command:"name:address"`
+	Result    string `json:"result" command:"name:result"`
+}
+
+// Validate is a method that validates the dto
+func (i *InvoiceSend) Validate() error {
+	if i.ID == "" && i.Month == "" {
+		return errors.New(pkg.ErrInvoiceSendParams)
+	}
+	if i.Month != "" {
+		if _, err := time.Parse(pkg.MonthFormat, i.Month); err != nil {
+			return fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (i *InvoiceSend) GetCommand() string {
+	return i.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (i *InvoiceSend) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{ID: i.ID, ClientID: i.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (i *InvoiceSend) GetOut() port.DTOOut {
+	return &InvoiceSendOut{Sort: i.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+// it filters the active and not sent invoices of the month by the month prefix of their ids
+func (i *InvoiceSend) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	extras := []interface{}{
+		fmt.Sprintf("status = '%s'", pkg.InvoiceStatusActive),
+		fmt.Sprintf("send_status = '%s'", pkg.InvoiceSendStatusNotSent),
+	}
+	if i.Month != "" {
+		month, err := time.Parse(pkg.MonthFormat, i.Month)
+		if err != nil {
+			return nil, nil, fmt.Errorf(pkg.ErrMonthInvalid, pkg.MonthFormat)
+		}
+		extras = append(extras, fmt.Sprintf("id like '%s%%'", month.Format("2006_01_")))
+	}
+	return domain, extras, nil
+}
+
+// GetDTO is a method that returns the dto out
+func (i *InvoiceSendOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*InvoiceSendOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, i.Sort)
+	return ret
+}
@@ -0,0 +1,137 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// PaymentCrud represents the dto for crud of the payments received
+type PaymentCrud struct {
+	Base
+	Object    string `json:"-" command:"name:payment;key;pos:2-"`
+	Action    string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	Csv       string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID        string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Date      string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	Value     string `json:"value" command:"name:value;pos:3+;trans:value,numeric" csv:"value"`
+	InvoiceID string `json:"invoice" command:"name:invoice;pos:3+;trans:invoice_id,string" csv:"invoice"`
+	ClientID  string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	Reference string `json:"reference" command:"name:reference;pos:3+;trans:reference,string" csv:"reference"`
+	Document  string `json:"document" command:"name:document;pos:3+;trans:document,string" csv:"document"`
+	Name      string `json:"name" command:"name:name;pos:3+;trans:name,string" csv:"name"`
+	Status    string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
+}
+
+// Validate is a method that validates the dto
+func (p *PaymentCrud) Validate() error {
+	if p.Csv != "" && (p.ID != "" || p.Date != "" || p.Value != "" || p.InvoiceID != "" || p.ClientID != "" ||
+		p.Reference != "" || p.Document != "" || p.Name != "" || p.Status != "") {
+		return errors.New(pkg.ErrCsvAndParams)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (p *PaymentCrud) GetCommand() string {
+	return p.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (p *PaymentCrud) GetDomain() []port.Domain {
+	if p.Csv != "" {
+		domains := []port.Domain{}
+		payments := []*PaymentCrud{}
+		p.ReadCSV(&payments, p.Csv)
+		for _, payment := range payments {
+			payment.Action = p.Action
+			payment.Object = p.Object
+			domains = append(domains, p.getDomain(payment))
+		}
+		return domains
+	}
+	return []port.Domain{p.getDomain(p)}
+}
+
+// GetOut is a method that returns the dto out
+func (p *PaymentCrud) GetOut() port.DTOOut {
+	return p
+}
+
+// GetDTO is a method that returns the dto
+func (p *PaymentCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		payments := slice.(*[]domain.Payment)
+		for _, payment := range *payments {
+			invoice := ""
+			if payment.InvoiceID != nil {
+				invoice = *payment.InvoiceID
+			}
+			client := ""
+			if payment.ClientID != nil {
+				client = *payment.ClientID
+			}
+			document := ""
+			if payment.Document != nil {
+				document = *payment.Document
+			}
+			ret = append(ret, &PaymentCrud{
+				ID:        payment.ID,
+				Date:      payment.Date.Format(pkg.DateFormat),
+				Value:     fmt.Sprintf("%.2f", payment.Value),
+				InvoiceID: invoice,
+				ClientID:  client,
+				Reference: payment.Reference,
+				Document:  document,
+				Name:      payment.Name,
+				Status:    payment.Status,
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, p.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (p *PaymentCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return p.getInstructions(p, domain)
+}
+
+// getDomain is a method that returns the domain of one payment
+// a payment added with an invoice is matched and without it is unmatched if the status is not informed
+func (p *PaymentCrud) getDomain(one *PaymentCrud) port.Domain {
+	if one.Action == "add" && one.Date == "" {
+		time.Local, _ = time.LoadLocation(pkg.Location)
+		one.Date = time.Now().Format(pkg.DateFormat)
+	}
+	if one.Action == "add" && one.Status == "" {
+		one.Status = pkg.PaymentStatusUnmatched
+		if strings.TrimSpace(one.InvoiceID) != "" {
+			one.Status = pkg.PaymentStatusMatched
+		}
+	}
+	one.trim()
+	return domain.NewPayment(one.ID, one.Date, one.Value, one.InvoiceID, one.ClientID, one.Reference, one.Document,
+		one.Name, one.Status)
+}
+
+// trim is a method that trims the dto
+func (p *PaymentCrud) trim() {
+	p.ID = strings.TrimSpace(p.ID)
+	p.Date = strings.TrimSpace(p.Date)
+	p.Value = strings.TrimSpace(p.Value)
+	p.InvoiceID = strings.TrimSpace(p.InvoiceID)
+	p.ClientID = strings.TrimSpace(p.ClientID)
+	p.Reference = strings.TrimSpace(p.Reference)
+	p.Document = strings.TrimSpace(p.Document)
+	p.Name = strings.TrimSpace(p.Name)
+	p.Status = strings.TrimSpace(p.Status)
+}
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"errors"
+	"os"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// PaymentImport represents the dto for importing the payments of an OFX statement or a CNAB return file
+type PaymentImport struct {
+	Base
+	Object string `json:"-" command:"name:payment;key;pos:2-"`
+	Action string `json:"-" command:"name:import;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	File   string `json:"file" command:"name:file;pos:3+"`
+}
+
+// PaymentImportOut represents the dto for importing payments on output
+type PaymentImportOut struct {
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	Line       string `json:"line" command:"name:line"`
+	PaymentID  string `json:"payment" command:"name:payment"`
+	Date       string `json:"date" command:"name:date"`
+	Value      string `json:"value" command:"name:value"`
+	InvoiceID  string `json:"invoice" command:"name:invoice"`
+	ClientID   string `json:"client" command:"name:client"`
+	Result     string `json:"result" command:"name:result"`
+	Candidates string `json:"candidates" command:"name:candidates"`
+}
+
+// Validate is a method that validates the dto
+func (p *PaymentImport) Validate() error {
+	if p.File == "" {
+		return errors.New(pkg.ErrFileNotInformed)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (p *PaymentImport) GetCommand() string {
+	return p.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (p *PaymentImport) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Payment{}}
+}
+
+// GetOut is a method that returns the dto out
+func (p *PaymentImport) GetOut() port.DTOOut {
+	return &PaymentImportOut{Sort: p.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (p *PaymentImport) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetCredits is a method that reads and parses the bank file returning its format and credits
+func (p *PaymentImport) GetCredits() (string, []*pkg.BankCredit, error) {
+	data, err := os.ReadFile(p.File)
+	if err != nil {
+		return "", nil, err
+	}
+	return pkg.ParseBankFile(data)
+}
+
+// GetDTO is a method that returns the dto out
+func (p *PaymentImportOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*PaymentImportOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, p.Sort)
+	return ret
+}
@@ -0,0 +1,126 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// PriceCrud represents the dto for crud of the price history of packages and items
+type PriceCrud struct {
+	Base
+	Object     string `json:"-" command:"name:price;key;pos:2-"`
+	Action     string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	Csv        string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID         string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Date       string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	PackageID  string `json:"package" command:"name:package;pos:3+;trans:package_id,string" csv:"package"`
+	ItemID     string `json:"item" command:"name:item;pos:3+;trans:item_id,string" csv:"item"`
+	ContractID string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
+	Start      string `json:"start" command:"name:start;pos:3+;trans:start,time" csv:"start"`
+	Value      string `json:"value" command:"name:value;pos:3+;trans:value,numeric" csv:"value"`
+	Reason     string `json:"reason" command:"name:reason;pos:3+;trans:reason,string" csv:"reason"`
+}
+
+// Validate is a method that validates the dto
+func (c *PriceCrud) Validate() error {
+	if c.Csv != "" && (c.ID != "" || c.Date != "" || c.PackageID != "" || c.ItemID != "" || c.ContractID != "" ||
+		c.Start != "" || c.Value != "" || c.Reason != "") {
+		return errors.New(pkg.ErrCsvAndParams)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (c *PriceCrud) GetCommand() string {
+	return c.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (c *PriceCrud) GetDomain() []port.Domain {
+	if c.Csv != "" {
+		domains := []port.Domain{}
+		prices := []*PriceCrud{}
+		c.ReadCSV(&prices, c.Csv)
+		for _, price := range prices {
+			price.Action = c.Action
+			price.Object = c.Object
+			domains = append(domains, c.getDomain(price))
+		}
+		return domains
+	}
+	return []port.Domain{c.getDomain(c)}
+}
+
+// GetOut is a method that returns the dto out
+func (c *PriceCrud) GetOut() port.DTOOut {
+	return c
+}
+
+// GetDTO is a method that returns the dto
+func (c *PriceCrud) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		prices := slice.(*[]domain.Price)
+		for _, price := range *prices {
+			item := ""
+			if price.ItemID != nil {
+				item = *price.ItemID
+			}
+			contract := ""
+			if price.ContractID != nil {
+				contract = *price.ContractID
+			}
+			value := ""
+			if price.Value != nil {
+				value = fmt.Sprintf("%.2f", *price.Value)
+			}
+			ret = append(ret, &PriceCrud{
+				ID:         price.ID,
+				Date:       price.Date.Format(pkg.DateFormat),
+				PackageID:  price.PackageID,
+				ItemID:     item,
+				ContractID: contract,
+				Start:      price.Start.Format(pkg.DateFormat),
+				Value:      value,
+				Reason:     price.Reason,
+			})
+		}
+	}
+	pkg.NewCommands().Sort(ret, c.Sort)
+	return ret
+}
+
+// Getinstructions is a method that returns the instructions of the dto for given domain
+func (c *PriceCrud) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return c.getInstructions(c, domain)
+}
+
+// getDomain is a method that returns the domain of one price
+func (c *PriceCrud) getDomain(one *PriceCrud) port.Domain {
+	if one.Action == "add" && one.Date == "" {
+		time.Local, _ = time.LoadLocation(pkg.Location)
+		one.Date = time.Now().Format(pkg.DateFormat)
+	}
+	one.trim()
+	return domain.NewPrice(one.ID, one.Date, one.PackageID, one.ItemID, one.ContractID, one.Start, one.Value, one.Reason)
+}
+
+// trim is a method that trims the dto
+func (c *PriceCrud) trim() {
+	c.ID = strings.TrimSpace(c.ID)
+	c.Date = strings.TrimSpace(c.Date)
+	c.PackageID = strings.TrimSpace(c.PackageID)
+	c.ItemID = strings.TrimSpace(c.ItemID)
+	c.ContractID = strings.TrimSpace(c.ContractID)
+	c.Start = strings.TrimSpace(c.Start)
+	c.Value = strings.TrimSpace(c.Value)
+	c.Reason = strings.TrimSpace(c.Reason)
+}
@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ReportAging represents the dto for the receivables aging report of the clients
+type ReportAging struct {
+	Base
+	Object string `json:"-" command:"name:report;key;pos:2-"`
+	Action string `json:"-" command:"name:aging;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	At     string `json:"at" command:"name:at;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ReportAgingOut represents the dto for the lines of the receivables aging report on output
+type ReportAgingOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID string `json:"client" command:"name:client"`
+	Name     string `json:"name" command:"name:name"`
+	Days30   string `json:"0-30" command:"name:0-30"`
+	Days60   string `json:"31-60" command:"name:31-60"`
+	Days90   string `json:"61-90" command:"name:61-90"`
+	Over90   string `json:"90+" command:"name:90+"`
+	Total    string `json:"total" command:"name:total"`
+}
+
+// Validate is a method that validates the dto
+func (r *ReportAging) Validate() error {
+	if _, err := atDate(r.At); err != nil {
+		return err
+	}
+	return validateReport(nil, "", r.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (r *ReportAging) GetCommand() string {
+	return r.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (r *ReportAging) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{}}
+}
+
+// GetOut is a method that returns the dto out
+func (r *ReportAging) GetOut() port.DTOOut {
+	return &ReportAgingOut{Sort: r.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (r *ReportAging) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetAt returns the last moment of the date of the report
+func (r *ReportAging) GetAt() time.Time {
+	at, _ := atDate(r.At)
+	return at
+}
+
+// GetFormat returns the output format of the report or empty for the table
+func (r *ReportAging) GetFormat() string {
+	return reportFormat(r.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (r *ReportAgingOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ReportAgingOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, r.Sort)
+	return ret
+}
+
+// atDate returns the last moment of the date of a report or of today if not informed
+func atDate(at string) (time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	date := time.Now().In(local)
+	if at = strings.TrimSpace(at); at != "" {
+		var err error
+		if date, err = time.ParseInLocation(pkg.DateFormat, at, local); err != nil {
+			return time.Time{}, fmt.Errorf(pkg.ErrInvalidAtDate, pkg.DateFormat)
+		}
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, local).AddDate(0, 0, 1).Add(time.Nanosecond * -1), nil
+}
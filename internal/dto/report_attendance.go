@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// attendanceGroups are the groups of the attendance report
+	attendanceGroups = []string{pkg.ReportByClient, pkg.ReportByService, pkg.ReportByProfessional}
+)
+
+// ReportAttendance represents the dto for the attendance and no-show report by client, service or professional
+type ReportAttendance struct {
+	Base
+	Object string `json:"-" command:"name:report;key;pos:2-"`
+	Action string `json:"-" command:"name:attendance;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	From   string `json:"from" command:"name:from;pos:3+"`
+	To     string `json:"to" command:"name:to;pos:3+"`
+	By     string `json:"by" command:"name:by;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ReportAttendanceOut represents the dto for the lines of the attendance report on output
+type ReportAttendanceOut struct {
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	By         string `json:"by" command:"name:by"`
+	Key        string `json:"key" command:"name:key"`
+	Name       string `json:"name" command:"name:name"`
+	Scheduled  string `json:"scheduled" command:"name:scheduled"`
+	Done       string `json:"done" command:"name:done"`
+	Missed     string `json:"missed" command:"name:missed"`
+	Notice     string `json:"notice" command:"name:notice"`
+	NoNotice   string `json:"no_notice" command:"name:no_notice"`
+	Attendance string `json:"attendance" command:"name:attendance"`
+	NoShow     string `json:"no_show" command:"name:no_show"`
+	Trend      string `json:"trend" command:"name:trend"`
+	Flag       string `json:"flag" command:"name:flag"`
+}
+
+// Validate is a method that validates the dto
+func (r *ReportAttendance) Validate() error {
+	if _, _, err := r.GetRange(); err != nil {
+		return err
+	}
+	return validateReport(attendanceGroups, r.By, r.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (r *ReportAttendance) GetCommand() string {
+	return r.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (r *ReportAttendance) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Agenda{}}
+}
+
+// GetOut is a method that returns the dto out
+func (r *ReportAttendance) GetOut() port.DTOOut {
+	return &ReportAttendanceOut{Sort: r.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (r *ReportAttendance) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetRange returns the first moment of the from month and the last moment of the to month
+// the to month is the current one when not informed and the from month is the to month when not informed
+func (r *ReportAttendance) GetRange() (time.Time, time.Time, error) {
+	return pastRange(r.From, r.To)
+}
+
+// GetPrevious returns the period with the same months just before the period of the report
+func (r *ReportAttendance) GetPrevious() (time.Time, time.Time) {
+	from, to, _ := r.GetRange()
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	return from.AddDate(0, -months, 0), from.Add(time.Nanosecond * -1)
+}
+
+// GetBy returns the group of the report or the client if not informed
+func (r *ReportAttendance) GetBy() string {
+	if r.By == "" {
+		return pkg.DefaultAttendanceBy
+	}
+	return r.By
+}
+
+// GetFormat returns the output format of the report or empty for the table
+func (r *ReportAttendance) GetFormat() string {
+	return reportFormat(r.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (r *ReportAttendanceOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ReportAttendanceOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, r.Sort)
+	return ret
+}
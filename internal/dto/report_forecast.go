@@ -0,0 +1,99 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ReportForecast represents the dto for the forecast revenue report of the future openned agendas
+type ReportForecast struct {
+	Base
+	Object string `json:"-" command:"name:report;key;pos:2-"`
+	Action string `json:"-" command:"name:forecast;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	From   string `json:"from" command:"name:from;pos:3+"`
+	To     string `json:"to" command:"name:to;pos:3+"`
+	By     string `json:"by" command:"name:by;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ReportForecastOut represents the dto for the lines of the forecast revenue report on output
+type ReportForecastOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	Month    string `json:"month" command:"name:month"`
+	By       string `json:"by" command:"name:by"`
+	Key      string `json:"key" command:"name:key"`
+	Agendas  string `json:"agendas" command:"name:agendas"`
+	Priced   string `json:"priced" command:"name:priced"`
+	Forecast string `json:"forecast" command:"name:forecast"`
+	Share    string `json:"share" command:"name:share"`
+}
+
+// Validate is a method that validates the dto
+func (r *ReportForecast) Validate() error {
+	if _, _, err := r.GetRange(); err != nil {
+		return err
+	}
+	return validateReport(reportGroups, r.By, r.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (r *ReportForecast) GetCommand() string {
+	return r.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (r *ReportForecast) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Agenda{}}
+}
+
+// GetOut is a method that returns the dto out
+func (r *ReportForecast) GetOut() port.DTOOut {
+	return &ReportForecastOut{Sort: r.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (r *ReportForecast) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetRange returns the first moment of the from month and the last moment of the to month
+// the from month is the current one when not informed and the to month closes the default forecast months
+func (r *ReportForecast) GetRange() (time.Time, time.Time, error) {
+	from, to, err := monthRange(r.From, r.To)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if from.IsZero() {
+		local, _ := time.LoadLocation(pkg.Location)
+		now := time.Now().In(local)
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, local)
+	}
+	if to.IsZero() {
+		to = from.AddDate(0, pkg.DefaultForecastMonths-1, 0)
+	}
+	return checkRange(from, to)
+}
+
+// GetBy returns the group of the report or the default one if not informed
+func (r *ReportForecast) GetBy() string {
+	return reportBy(r.By)
+}
+
+// GetFormat returns the output format of the report or empty for the table
+func (r *ReportForecast) GetFormat() string {
+	return reportFormat(r.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (r *ReportForecastOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ReportForecastOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, r.Sort)
+	return ret
+}
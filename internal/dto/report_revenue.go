@@ -0,0 +1,171 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// reportGroups are the groups of the revenue and forecast reports
+	reportGroups = []string{pkg.ReportByService, pkg.ReportByPackage, pkg.ReportByProfessional}
+)
+
+// ReportRevenue represents the dto for the monthly revenue report by service, package or professional
+type ReportRevenue struct {
+	Base
+	Object string `json:"-" command:"name:report;key;pos:2-"`
+	Action string `json:"-" command:"name:revenue;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	From   string `json:"from" command:"name:from;pos:3+"`
+	To     string `json:"to" command:"name:to;pos:3+"`
+	By     string `json:"by" command:"name:by;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ReportRevenueOut represents the dto for the lines of the revenue report on output
+type ReportRevenueOut struct {
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	Month    string `json:"month" command:"name:month"`
+	By       string `json:"by" command:"name:by"`
+	Key      string `json:"key" command:"name:key"`
+	Items    string `json:"items" command:"name:items"`
+	Billed   string `json:"billed" command:"name:billed"`
+	Credited string `json:"credited" command:"name:credited"`
+	Revenue  string `json:"revenue" command:"name:revenue"`
+	Share    string `json:"share" command:"name:share"`
+}
+
+// Validate is a method that validates the dto
+func (r *ReportRevenue) Validate() error {
+	if _, _, err := r.GetRange(); err != nil {
+		return err
+	}
+	return validateReport(reportGroups, r.By, r.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (r *ReportRevenue) GetCommand() string {
+	return r.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (r *ReportRevenue) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Invoice{}}
+}
+
+// GetOut is a method that returns the dto out
+func (r *ReportRevenue) GetOut() port.DTOOut {
+	return &ReportRevenueOut{Sort: r.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (r *ReportRevenue) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetRange returns the first moment of the from month and the last moment of the to month
+// the to month is the current one when not informed and the from month is the to month when not informed
+func (r *ReportRevenue) GetRange() (time.Time, time.Time, error) {
+	return pastRange(r.From, r.To)
+}
+
+// GetBy returns the group of the report or the default one if not informed
+func (r *ReportRevenue) GetBy() string {
+	return reportBy(r.By)
+}
+
+// GetFormat returns the output format of the report or empty for the table
+func (r *ReportRevenue) GetFormat() string {
+	return reportFormat(r.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (r *ReportRevenueOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ReportRevenueOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, r.Sort)
+	return ret
+}
+
+// validateReport validates the group between the groups of a report and the output format
+func validateReport(groups []string, by, format string) error {
+	if by != "" && !slices.Contains(groups, by) {
+		return fmt.Errorf(pkg.ErrInvalidReportBy, strings.Join(groups, ", "))
+	}
+	if format != "" && !slices.Contains(outputFormats, format) {
+		return fmt.Errorf(pkg.ErrInvalidOutput, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// monthRange parses the from and to months of a report returning zero for the ones not informed
+func monthRange(from, to string) (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	start, end := time.Time{}, time.Time{}
+	var err error
+	if from = strings.TrimSpace(from); from != "" {
+		if start, err = time.ParseInLocation(pkg.MonthFormat, from, local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidFromMonth, pkg.MonthFormat)
+		}
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		if end, err = time.ParseInLocation(pkg.MonthFormat, to, local); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf(pkg.ErrInvalidToMonth, pkg.MonthFormat)
+		}
+	}
+	return start, end, nil
+}
+
+// pastRange returns the first moment of the from month and the last moment of the to month
+// the to month is the current one when not informed and the from month is the to month when not informed
+func pastRange(from, to string) (time.Time, time.Time, error) {
+	start, end, err := monthRange(from, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if end.IsZero() {
+		local, _ := time.LoadLocation(pkg.Location)
+		end = time.Now().In(local)
+	}
+	if start.IsZero() {
+		start = end
+	}
+	return checkRange(start, end)
+}
+
+// checkRange returns the first moment of the from month and the last moment of the to month
+// checking if the from month is not after the to month
+func checkRange(from, to time.Time) (time.Time, time.Time, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, local)
+	to = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, local).AddDate(0, 1, 0).Add(time.Nanosecond * -1)
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New(pkg.ErrFromAfterTo)
+	}
+	return from, to, nil
+}
+
+// reportBy returns the group of a report or the default one if not informed
+func reportBy(by string) string {
+	if by == "" {
+		return pkg.DefaultReportBy
+	}
+	return by
+}
+
+// reportFormat returns the output format of a report or empty for the table
+func reportFormat(format string) string {
+	if format == pkg.OutputTable {
+		return ""
+	}
+	return format
+}
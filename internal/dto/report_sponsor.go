@@ -0,0 +1,82 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ReportSponsor represents the dto for the exposure report of the sponsors of contracts
+type ReportSponsor struct {
+	Base
+	Object string `json:"-" command:"name:report;key;pos:2-"`
+	Action string `json:"-" command:"name:sponsor;key;pos:2-"`
+	Sort   string `json:"sort" command:"name:sort;pos:3+"`
+	At     string `json:"at" command:"name:at;pos:3+"`
+	Format string `json:"format" command:"name:format;pos:3+"`
+}
+
+// ReportSponsorOut represents the dto for the lines of the sponsor exposure report on output
+type ReportSponsorOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	SponsorID string `json:"sponsor" command:"name:sponsor"`
+	Name      string `json:"name" command:"name:name"`
+	Contracts string `json:"contracts" command:"name:contracts"`
+	Clients   string `json:"clients" command:"name:clients"`
+	Packages  string `json:"packages" command:"name:packages"`
+	Forecast  string `json:"forecast" command:"name:forecast"`
+	Open      string `json:"open" command:"name:open"`
+	Overdue   string `json:"overdue" command:"name:overdue"`
+	Exposure  string `json:"exposure" command:"name:exposure"`
+}
+
+// Validate is a method that validates the dto
+func (r *ReportSponsor) Validate() error {
+	if _, err := atDate(r.At); err != nil {
+		return err
+	}
+	return validateReport(nil, "", r.Format)
+}
+
+// GetCommand is a method that returns the command of the dto
+func (r *ReportSponsor) GetCommand() string {
+	return r.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (r *ReportSponsor) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Contract{}}
+}
+
+// GetOut is a method that returns the dto out
+func (r *ReportSponsor) GetOut() port.DTOOut {
+	return &ReportSponsorOut{Sort: r.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (r *ReportSponsor) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// GetAt returns the last moment of the date of the report
+func (r *ReportSponsor) GetAt() time.Time {
+	at, _ := atDate(r.At)
+	return at
+}
+
+// GetFormat returns the output format of the report or empty for the table
+func (r *ReportSponsor) GetFormat() string {
+	return reportFormat(r.Format)
+}
+
+// GetDTO is a method that returns the dto out
+func (r *ReportSponsorOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	for _, out := range domainIn.([]*ReportSponsorOut) {
+		ret = append(ret, out)
+	}
+	pkg.NewCommands().Sort(ret, r.Sort)
+	return ret
+}
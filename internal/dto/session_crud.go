@@ -2,6 +2,7 @@ package dto
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -14,25 +15,29 @@ import (
 // SessionCrud represents the dto for getting a session
 type SessionCrud struct {
 	Base
-	Object    string `json:"-" command:"name:session;key;pos:2-"`
-	Action    string `json:"-" command:"name:add,get,up;key;pos:2-"`
-	Sort      string `json:"sort" command:"name:sort;pos:3+"`
-	Csv       string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
-	ID        string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
-	Sequence  string `json:"seq" command:"name:seq;pos:3+;trans:sequence,int" csv:"seq"`
-	Date      string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
-	ClientID  string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
-	ServiceID string `json:"service" command:"name:service;pos:3+;trans:service_id,string" csv:"service"`
-	At        string `json:"at" command:"name:at;pos:3+;trans:at,time" csv:"at"`
-	Status    string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
-	Process   string `json:"process" command:"name:process;pos:3+;trans:process,string" csv:"process"`
-	AgendaID  string `json:"agenda" command:"name:agenda;pos:3+;trans:agenda_id,string" csv:"agenda"`
+	Object     string `json:"-" command:"name:session;key;pos:2-"`
+	Action     string `json:"-" command:"name:add,get,up;key;pos:2-"`
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	Csv        string `json:"csv" command:"name:csv;pos:3+;" csv:"file"`
+	ID         string `json:"id" command:"name:id;pos:3+;trans:id,string" csv:"id"`
+	Sequence   string `json:"seq" command:"name:seq;pos:3+;trans:sequence,int" csv:"seq"`
+	Date       string `json:"date" command:"name:date;pos:3+;trans:date,time" csv:"date"`
+	ClientID   string `json:"client" command:"name:client;pos:3+;trans:client_id,string" csv:"client"`
+	ServiceID  string `json:"service" command:"name:service;pos:3+;trans:service_id,string" csv:"service"`
+	At         string `json:"at" command:"name:at;pos:3+;trans:at,time" csv:"at"`
+	Status     string `json:"status" command:"name:status;pos:3+;trans:status,string" csv:"status"`
+	Process    string `json:"process" command:"name:process;pos:3+;trans:process,string" csv:"process"`
+	AgendaID   string `json:"agenda" command:"name:agenda;pos:3+;trans:agenda_id,string" csv:"agenda"`
+	ContractID string `json:"contract" command:"name:contract;pos:3+;trans:contract_id,string" csv:"contract"`
+	Kind       string `json:"kind" command:"name:kind;pos:3+;trans:kind,string" csv:"kind"`
+	Discount   string `json:"discount" command:"name:discount;pos:3+" csv:"discount"`
 }
 
 // Validate is a method that validates the dto
 func (s *SessionCrud) Validate() error {
 	if s.Csv != "" && (s.ID != "" || s.Date != "" || s.ClientID != "" || s.ServiceID != "" || s.At != "" ||
-		s.Status != "" || s.Process != "" || s.Sequence != "" || s.AgendaID != "") {
+		s.Status != "" || s.Process != "" || s.Sequence != "" || s.AgendaID != "" || s.ContractID != "" || s.Kind != "" ||
+		s.Discount != "") {
 		return errors.New(pkg.ErrCsvAndParams)
 	}
 	return nil
@@ -74,16 +79,23 @@ func (s *SessionCrud) GetDTO(domainIn interface{}) []port.DTOOut {
 	for _, slice := range slices {
 		sessions := slice.(*[]domain.Session)
 		for _, se := range *sessions {
+			contract := ""
+			if se.ContractID != nil {
+				contract = *se.ContractID
+			}
 			ret = append(ret, &SessionCrud{
-				ID:        se.ID,
-				Sequence:  strconv.Itoa(*se.Sequence),
-				Date:      se.Date.Format(pkg.DateFormat),
-				ClientID:  se.ClientID,
-				ServiceID: se.ServiceID,
-				At:        se.At.Format(pkg.DateTimeFormat),
-				Status:    se.Status,
-				Process:   se.Process,
-				AgendaID:  se.AgendaID,
+				ID:         se.ID,
+				Sequence:   strconv.Itoa(*se.Sequence),
+				Date:       se.Date.Format(pkg.DateFormat),
+				ClientID:   se.ClientID,
+				ServiceID:  se.ServiceID,
+				At:         se.At.Format(pkg.DateTimeFormat),
+				Status:     se.Status,
+				Process:    se.Process,
+				AgendaID:   se.AgendaID,
+				ContractID: contract,
+				Kind:       se.Kind,
+				Discount:   s.formatDiscount(&se),
 			})
 		}
 	}
@@ -114,17 +126,34 @@ func (s *SessionCrud) getDomain(one *SessionCrud) port.Domain {
 		if err == nil {
 			at = t.Format("2006-01-02-15-04")
 		}
-		one.ID = at + "_" + one.ClientID + "_" + one.ServiceID + "_" + one.Sequence
+		one.ID = at + "_" + one.ClientID + "_" + one.ServiceID
+		if one.Sequence != "" {
+			one.ID += "_" + one.Sequence
+		}
+	}
+	if one.Action == "add" && one.Kind == "" {
+		one.Kind = pkg.DefaultSessionKind
 	}
-	if one.Action == "add" && one.Sequence == "" {
-		one.Sequence = pkg.DefaultSessionSequence
+	if one.Action == "add" && one.Discount == "" {
+		one.Discount = pkg.DefaultSessionDiscount
 	}
 	if one.Action == "add" {
 		one.Process = pkg.DefaultSessionProcess
 	}
 	one.trim()
 	return domain.NewSession(one.ID, one.Sequence, one.Date, one.ClientID, one.ServiceID, one.At, one.Status,
-		one.Process, one.AgendaID)
+		one.Process, one.AgendaID, one.ContractID, one.Kind, one.Discount)
+}
+
+// formatDiscount is a method that returns the discount of the session as a string
+func (s *SessionCrud) formatDiscount(se *domain.Session) string {
+	if se.Discount == nil {
+		return ""
+	}
+	if se.IsPercent() {
+		return strconv.FormatFloat(*se.Discount, 'f', -1, 64) + "%"
+	}
+	return fmt.Sprintf("%.2f", *se.Discount)
 }
 
 // trim is a method that trims the dto
@@ -138,4 +167,7 @@ func (s *SessionCrud) trim() {
 	s.Status = strings.TrimSpace(s.Status)
 	s.Process = strings.TrimSpace(s.Process)
 	s.AgendaID = strings.TrimSpace(s.AgendaID)
+	s.ContractID = strings.TrimSpace(s.ContractID)
+	s.Kind = strings.TrimSpace(s.Kind)
+	s.Discount = strings.TrimSpace(s.Discount)
 }
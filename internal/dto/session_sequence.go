@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"errors"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// SessionSequence represents the dto for checking and renumbering the sessions sequence
+type SessionSequence struct {
+	Base
+	Object   string `json:"-" command:"name:session;key;pos:2-"`
+	Action   string `json:"-" command:"name:check,renumber;key;pos:2-"`
+	Sort     string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID string `json:"client" command:"name:client;pos:3+"`
+}
+
+// SessionSequenceOut represents the dto for checking and renumbering the sessions sequence on output
+type SessionSequenceOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	ClientID  string `json:"client" command:"name:client"`
+	Scope     string `json:"scope" command:"name:scope"`
+	Sequence  string `json:"seq" command:"name:seq"`
+	SessionID string `json:"session" command:"name:session"`
+	Result    string `json:"result" command:"name:result"`
+}
+
+// Validate is a method that validates the dto
+func (s *SessionSequence) Validate() error {
+	if s.Action == "renumber" && s.ClientID == "" {
+		return errors.New(pkg.ErrRenumberWithoutClient)
+	}
+	return nil
+}
+
+// GetCommand is a method that returns the command of the dto
+func (s *SessionSequence) GetCommand() string {
+	return s.Action
+}
+
+// GetDomain is a method that returns the domain of the dto
+func (s *SessionSequence) GetDomain() []port.Domain {
+	return []port.Domain{&domain.Session{ClientID: s.ClientID}}
+}
+
+// GetOut is a method that returns the dto out
+func (s *SessionSequence) GetOut() port.DTOOut {
+	return &SessionSequenceOut{Sort: s.Sort}
+}
+
+// GetInstructions is a method that returns the instructions of the dto for a given domain
+func (s *SessionSequence) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
+	return domain, []interface{}{}, nil
+}
+
+// IsRenumber is a method that returns if the sequence should be renumbered
+func (s *SessionSequence) IsRenumber() bool {
+	return s.Action == "renumber"
+}
+
+// GetDTO is a method that returns the dto out
+func (s *SessionSequenceOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		ret = append(ret, slice.(*SessionSequenceOut))
+	}
+	pkg.NewCommands().Sort(ret, s.Sort)
+	return ret
+}
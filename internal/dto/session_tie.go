@@ -2,6 +2,10 @@ package dto
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lavinas/ephemeris/internal/domain"
@@ -12,15 +16,23 @@ import (
 // SessionTie represents the dto for tying a session
 type SessionTie struct {
 	Base
-	Object    string `json:"-" command:"name:session;key;pos:2-"`
-	Action    string `json:"-" command:"name:tie,untie,confirm;key;pos:2-"`
-	Sort      string `json:"sort" command:"name:sort;pos:3+"`
-	ID        string `json:"id" command:"name:id;pos:3+"`
-	ClientID  string `json:"client" command:"name:client;pos:3+;trans:client_id,string"`
-	ServiceID string `json:"service" command:"name:service;pos:3+;trans:service_id,string"`
-	At        string `json:"at" command:"name:at;pos:3+;trans:at,time"`
-	Status    string `json:"status" command:"name:status;pos:3+;trans:status,string"`
-	Process   string `json:"process" command:"name:process;pos:3;trans:process,string"`
+	Object     string `json:"-" command:"name:session;key;pos:2-"`
+	Action     string `json:"-" command:"name:tie,untie,confirm,recontract;key;pos:2-"`
+	Sort       string `json:"sort" command:"name:sort;pos:3+"`
+	ID         string `json:"id" command:"name:id;pos:3+"`
+	ClientID   string `json:"client" command:"name:client;pos:3+;trans:client_id,string"`
+	ServiceID  string `json:"service" command:"name:service;pos:3+;trans:service_id,string"`
+	At         string `json:"at" command:"name:at;pos:3+;trans:at,time"`
+	Status     string `json:"status" command:"name:status;pos:3+;trans:status,string"`
+	Process    string `json:"process" command:"name:process;pos:3+;trans:process,string"`
+	From       string `json:"from" command:"name:from;pos:3+"`
+	To         string `json:"to" command:"name:to;pos:3+"`
+	Jobs       string `json:"jobs" command:"name:jobs;pos:3+"`
+	Proceed    string `json:"proceed" command:"name:proceed;pos:3+"`
+	Reschedule string `json:"reschedule" command:"name:reschedule;pos:3+"`
+	Original   string `json:"original" command:"name:original;pos:3+"`
+	ContractID string `json:"contract" command:"name:contract;pos:3+"`
+	Explain    string `json:"explain" command:"name:explain;pos:3+"`
 }
 
 // SessionTieOut represents the dto for tying a session on output
@@ -35,18 +47,177 @@ type SessionTieOut struct {
 	AgendaID  string `json:"message" command:"name:agenda/error"`
 }
 
+// SessionExplainOut represents the dto for explaining the agenda candidates of a session on output
+type SessionExplainOut struct {
+	Sort      string `json:"sort" command:"name:sort;pos:3+"`
+	SessionID string `json:"session" command:"name:session"`
+	AgendaID  string `json:"agenda" command:"name:agenda"`
+	Start     string `json:"start" command:"name:start"`
+	Status    string `json:"status" command:"name:status"`
+	Client    string `json:"client" command:"name:client"`
+	Time      string `json:"time" command:"name:time(s)"`
+	Service   string `json:"service" command:"name:service"`
+	Score     string `json:"score" command:"name:score"`
+	Result    string `json:"result" command:"name:result"`
+}
+
 // Validate is a method that validates the dto
 func (s *SessionTie) Validate() error {
 	if s.ID == "" && s.ClientID == "" && s.ServiceID == "" && s.At == "" && s.Status == "" &&
-		s.Process == "" {
+		s.Process == "" && s.From == "" && s.To == "" {
 		return errors.New(pkg.ErrInvalidParameters)
 	}
-	if err := s.validateAt(); err != nil {
-		return err
+	validations := []func() error{
+		s.validateWildcards,
+		s.validateStatus,
+		s.validateAt,
+		s.validateRange,
+		s.validateJobs,
+		s.validateProceed,
+		s.validateConfirm,
+		s.validateRecontract,
+		s.validateExplain,
+	}
+	for _, v := range validations {
+		if err := v(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateWildcards is a method that validates that no filter matches all registers
+func (s *SessionTie) validateWildcards() error {
+	filters := map[string]string{"id": s.ID, "client": s.ClientID, "service": s.ServiceID}
+	for name, value := range filters {
+		if value != "" && strings.Trim(value, pkg.Wildcard) == "" {
+			return fmt.Errorf(pkg.ErrWildcardOnly, name)
+		}
+	}
+	return nil
+}
+
+// validateStatus is a method that validates the status and process fields against the valid ones
+func (s *SessionTie) validateStatus() error {
+	if s.Status != "" && !slices.Contains(domain.StatusSession, s.Status) {
+		return fmt.Errorf(pkg.ErrInvalidStatus, strings.Join(domain.StatusSession, ", "))
+	}
+	if s.Process != "" && !slices.Contains(domain.StatusProcess, s.Process) {
+		return fmt.Errorf(pkg.ErrInvalidProcess, strings.Join(domain.StatusProcess, ", "))
 	}
 	return nil
 }
 
+// validateRange is a method that validates the from and to fields
+func (s *SessionTie) validateRange() error {
+	from, err := s.getDate(s.From)
+	if err != nil {
+		return fmt.Errorf(pkg.ErrInvalidFrom, pkg.DateFormat)
+	}
+	to, err := s.getDate(s.To)
+	if err != nil {
+		return fmt.Errorf(pkg.ErrInvalidTo, pkg.DateFormat)
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return errors.New(pkg.ErrFromAfterTo)
+	}
+	return nil
+}
+
+// validateProceed is a method that validates the proceed field
+func (s *SessionTie) validateProceed() error {
+	if s.Proceed != "" && s.Proceed != pkg.Yes {
+		return fmt.Errorf(pkg.ErrInvalidYes, "proceed")
+	}
+	return nil
+}
+
+// validateConfirm is a method that validates the reschedule options of the confirm command
+func (s *SessionTie) validateConfirm() error {
+	if s.Action != "confirm" && (s.Reschedule != "" || s.Original != "") {
+		return errors.New(pkg.ErrOnlyConfirmOptions)
+	}
+	if s.Reschedule != "" && s.Reschedule != pkg.Yes {
+		return fmt.Errorf(pkg.ErrInvalidYes, "reschedule")
+	}
+	if s.Original != "" && s.Reschedule == "" {
+		return errors.New(pkg.ErrOriginalWithoutReschedule)
+	}
+	if s.Original != "" && !slices.Contains(domain.StatusOriginal, s.Original) {
+		return fmt.Errorf(pkg.ErrInvalidOriginal, strings.Join(domain.StatusOriginal, ", "))
+	}
+	return nil
+}
+
+// validateRecontract is a method that validates the contract option of the recontract command
+func (s *SessionTie) validateRecontract() error {
+	if s.Action == "recontract" && s.ContractID == "" {
+		return errors.New(pkg.ErrRecontractWithoutContract)
+	}
+	if s.Action != "recontract" && s.ContractID != "" {
+		return errors.New(pkg.ErrOnlyRecontractOptions)
+	}
+	return nil
+}
+
+// validateExplain is a method that validates the explain option
+func (s *SessionTie) validateExplain() error {
+	if s.Explain == "" {
+		return nil
+	}
+	if s.Explain != pkg.Yes {
+		return fmt.Errorf(pkg.ErrInvalidYes, "explain")
+	}
+	if s.Action != "tie" {
+		return errors.New(pkg.ErrOnlyTieExplain)
+	}
+	return nil
+}
+
+// IsExplain is a method that returns if the tie should just explain the agenda candidates
+func (s *SessionTie) IsExplain() bool {
+	return s.Explain == pkg.Yes
+}
+
+// IsReschedule is a method that returns if a new rescheduled agenda should be created on confirm
+func (s *SessionTie) IsReschedule() bool {
+	return s.Reschedule == pkg.Yes
+}
+
+// GetOriginal is a method that returns the status to be set on the original agenda on reschedule
+func (s *SessionTie) GetOriginal() string {
+	if s.Original == "" {
+		return pkg.DefaultOriginalAgendaStatus
+	}
+	return s.Original
+}
+
+// IsProceed is a method that returns if the user confirmed to process above the sessions limit
+func (s *SessionTie) IsProceed() bool {
+	return s.Proceed == pkg.Yes
+}
+
+// validateJobs is a method that validates the jobs field
+func (s *SessionTie) validateJobs() error {
+	if s.Jobs == "" {
+		return nil
+	}
+	jobs, err := strconv.Atoi(s.Jobs)
+	if err != nil || jobs < 1 || jobs > pkg.MaxSessionTieJobs {
+		return fmt.Errorf(pkg.ErrInvalidJobs, pkg.MaxSessionTieJobs)
+	}
+	return nil
+}
+
+// GetJobs is a method that returns the number of parallel jobs informed or 0 if not informed
+func (s *SessionTie) GetJobs() int {
+	jobs, err := strconv.Atoi(s.Jobs)
+	if err != nil {
+		return 0
+	}
+	return jobs
+}
+
 // validateAt is a method that validates the at field
 func (s *SessionTie) validateAt() error {
 	if s.At == "" {
@@ -76,7 +247,7 @@ func (s *SessionTie) GetCommand() string {
 // GetDomain is a method that returns a string representation of the agenda
 func (s *SessionTie) GetDomain() []port.Domain {
 	return []port.Domain{
-		domain.NewSession(s.ID, "", "", s.ClientID, s.ServiceID, s.At, s.Status, s.Process, ""),
+		domain.NewSession(s.ID, "", "", s.ClientID, s.ServiceID, s.At, s.Status, s.Process, "", "", "", ""),
 	}
 }
 
@@ -85,9 +256,34 @@ func (s *SessionTie) GetOut() port.DTOOut {
 	return &SessionTieOut{Sort: s.Sort}
 }
 
+// GetExplainOut is a method that returns the output dto for explain mode
+func (s *SessionTie) GetExplainOut() port.DTOOut {
+	return &SessionExplainOut{Sort: s.Sort}
+}
+
 // Getinstructions is a method that returns the instructions of the dto for given domain
 func (s *SessionTie) GetInstructions(domain port.Domain) (port.Domain, []interface{}, error) {
-	return s.getInstructions(s, domain)
+	domain, extras, err := s.getInstructions(s, domain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from, err := s.getDate(s.From); err == nil && !from.IsZero() {
+		extras = append(extras, fmt.Sprintf("at >= '%s'", from.Format("2006-01-02 15:04:05")))
+	}
+	if to, err := s.getDate(s.To); err == nil && !to.IsZero() {
+		to = to.AddDate(0, 0, 1).Add(time.Nanosecond * -1)
+		extras = append(extras, fmt.Sprintf("at <= '%s'", to.Format("2006-01-02 15:04:05")))
+	}
+	return domain, extras, nil
+}
+
+// getDate is a method that parses a date field returning zero time if not informed
+func (s *SessionTie) getDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	local, _ := time.LoadLocation(pkg.Location)
+	return time.ParseInLocation(pkg.DateFormat, date, local)
 }
 
 // GetDTO is a method that returns the dto
@@ -109,3 +305,14 @@ func (s *SessionTieOut) GetDTO(domainIn interface{}) []port.DTOOut {
 	pkg.NewCommands().Sort(ret, s.Sort)
 	return ret
 }
+
+// GetDTO is a method that returns the dto
+func (s *SessionExplainOut) GetDTO(domainIn interface{}) []port.DTOOut {
+	ret := []port.DTOOut{}
+	slices := domainIn.([]interface{})
+	for _, slice := range slices {
+		ret = append(ret, slice.(*SessionExplainOut))
+	}
+	pkg.NewCommands().Sort(ret, s.Sort)
+	return ret
+}
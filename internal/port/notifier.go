@@ -0,0 +1,8 @@
+package port
+
+// Notifier is an interface that defines the methods for sending messages to clients
+type Notifier interface {
+	// Send is a method that sends a message to an address of a channel like e-mail or whatsapp
+	// with the files attached if informed
+	Send(channel, address, subject, body string, attachments ...string) error
+}
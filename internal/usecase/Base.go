@@ -3,7 +3,11 @@ package usecase
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"strconv"
+	"sync"
 
 	"github.com/lavinas/ephemeris/internal/port"
 	"github.com/lavinas/ephemeris/pkg"
@@ -11,32 +15,64 @@ import (
 
 var (
 	runMap = map[string]func(*Usecase, interface{}) error{
-		"add":     (*Usecase).Add,
-		"get":     (*Usecase).Get,
-		"up":      (*Usecase).Up,
-		"make":    (*Usecase).AgendaMake,
-		"tie":     (*Usecase).SessionTie,
-		"untie":   (*Usecase).SessionTie,
-		"confirm": (*Usecase).SessionTie,
-		"force":   (*Usecase).SessionForce,
+		"add":        (*Usecase).Add,
+		"get":        (*Usecase).Get,
+		"up":         (*Usecase).Up,
+		"make":       (*Usecase).AgendaMake,
+		"tie":        (*Usecase).SessionTie,
+		"untie":      (*Usecase).SessionTie,
+		"confirm":    (*Usecase).SessionTie,
+		"recontract": (*Usecase).SessionTie,
+		"force":      (*Usecase).SessionForce,
+		"checkin":    (*Usecase).AgendaCheckin,
+		"done":       (*Usecase).AgendaCheckin,
+		"check":      (*Usecase).SessionSequence,
+		"renumber":   (*Usecase).SessionSequence,
+		"generate":   (*Usecase).InvoiceMake,
+		"end":        (*Usecase).ContractEnd,
+		"change":     (*Usecase).ContractChange,
+		"readjust":   (*Usecase).ContractReadjust,
+		"send":       (*Usecase).InvoiceSend,
+		"nfse":       (*Usecase).InvoiceNfse,
+		"pix":        (*Usecase).InvoicePix,
+		"import":     (*Usecase).PaymentImport,
+		"overdue":    (*Usecase).InvoiceOverdue,
+		"credit":     (*Usecase).InvoiceCredit,
+		"render":     (*Usecase).InvoiceRender,
+		"statement":  (*Usecase).ClientStatement,
+		"normalize":  (*Usecase).ClientNormalize,
+		"revenue":    (*Usecase).ReportRevenue,
+		"aging":      (*Usecase).ReportAging,
+		"forecast":   (*Usecase).ReportForecast,
+		"sponsor":    (*Usecase).ReportSponsor,
+		"attendance": (*Usecase).ReportAttendance,
+		"export":     (*Usecase).AccountingExport,
 	}
 )
 
 // Usecase is a struct that groups the crud usecase
 type Usecase struct {
-	Repo    port.Repository
-	Log     port.Logger
-	Out     []port.DTOOut
-	Limited bool
+	Repo     port.Repository
+	Config   port.Config
+	Log      port.Logger
+	Notifier port.Notifier
+	Out      []port.DTOOut
+	Output   string
+	Limited  bool
+	Progress io.Writer
+	locks    sync.Map
 }
 
 // NewAdd is a function that returns a new Add struct
-func NewUsecase(repo port.Repository, log port.Logger) *Usecase {
+func NewUsecase(repo port.Repository, config port.Config, log port.Logger, notifier port.Notifier) *Usecase {
 	return &Usecase{
-		Repo:    repo,
-		Log:     log,
-		Out:     nil,
-		Limited: false,
+		Repo:     repo,
+		Config:   config,
+		Log:      log,
+		Notifier: notifier,
+		Out:      nil,
+		Limited:  false,
+		Progress: os.Stderr,
 	}
 }
 
@@ -58,14 +94,22 @@ func (c *Usecase) Interface() (interface{}, bool) {
 }
 
 // String is a method that returns a string representation of the output dto
+// as a table or as csv or json when the command sets the output format
+// csv and json keep the empty columns so every line has the same fields
 func (c *Usecase) String() string {
 	if c.Out == nil {
 		return ""
 	}
+	args := []string{"nokeys", "counter"}
 	if c.Limited {
-		return pkg.NewCommands().Marshal(c.Out, "trim", "nokeys", "more", "counter")
+		args = append(args, "more")
 	}
-	return pkg.NewCommands().Marshal(c.Out, "trim", "nokeys", "counter")
+	if c.Output != "" {
+		args = append(args, c.Output)
+	} else {
+		args = append(args, "trim")
+	}
+	return pkg.NewCommands().Marshal(c.Out, args...)
 }
 
 // sliceOf is a method that returns a slice of a struct
@@ -91,6 +135,38 @@ func (c *Usecase) merge(source interface{}, target interface{}) error {
 	return nil
 }
 
+// configInt is a method that returns a numeric config value or the default value if not informed or invalid
+func (c *Usecase) configInt(key string, def int) int {
+	if c.Config == nil {
+		return def
+	}
+	val, err := strconv.Atoi(c.Config.Get(key))
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// configFloat is a method that returns a float config value or the default value if not informed or invalid
+func (c *Usecase) configFloat(key string, def float64) float64 {
+	if c.Config == nil {
+		return def
+	}
+	val, err := strconv.ParseFloat(c.Config.Get(key), 64)
+	if err != nil || val < 0 {
+		return def
+	}
+	return val
+}
+
+// lock is a method that locks a key among the goroutines of the usecase and returns the unlock function
+func (c *Usecase) lock(key string) func() {
+	m, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // error is a function that logs an error and returns it
 func (c *Usecase) error(prefix string, err string, line int, lines int) error {
 	err = prefix + ": " + err
@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	journalCSVFormat   = "journal_%s.csv"
+	journalFixedFormat = "journal_%s.txt"
+)
+
+var (
+	// bankFormats are the bank files of the payments imported that are received on the bank
+	bankFormats = []string{pkg.BankFileOFX, pkg.BankFileCNAB240, pkg.BankFileCNAB400}
+)
+
+// AccountingExport generates the journal entries of a month from the items of the active invoices,
+// their iss, the credit notes and the payments and refunds, writing them on the csv and on the fixed width
+// layouts. The files of the month are replaced on each run, so exporting a month again does not duplicate entries
+func (u *Usecase) AccountingExport(dtoIn interface{}) error {
+	dtoExport := dtoIn.(*dto.AccountingExport)
+	if err := dtoExport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, to, _ := dtoExport.GetRange()
+	chart, err := u.accountingChart()
+	if err != nil {
+		return err
+	}
+	keys := u.reportKeys(pkg.ReportByService)
+	entries, err := u.invoiceEntries(chart, keys, from, to)
+	if err != nil {
+		return err
+	}
+	notes, err := u.noteEntries(chart, keys, from, to)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, notes...)
+	payments, err := u.paymentEntries(chart, from, to)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, payments...)
+	slices.SortStableFunc(entries, func(a, b *pkg.JournalEntry) int {
+		return a.Date.Compare(b.Date)
+	})
+	if err := u.writeJournal(from, entries); err != nil {
+		return err
+	}
+	ret := []*dto.AccountingExportOut{}
+	for _, e := range entries {
+		ret = append(ret, &dto.AccountingExportOut{Date: e.Date.Format(pkg.DateFormat), Debit: e.Debit,
+			Credit: e.Credit, Value: fmt.Sprintf("%.2f", e.Value), Document: e.Document, History: e.History})
+	}
+	u.Output = dtoExport.GetFormat()
+	u.Out = dtoExport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// accountingChart returns the chart of accounts mapping of the config over the default one
+func (u *Usecase) accountingChart() (map[string]string, error) {
+	chart, _ := pkg.ParseChart(pkg.DefaultAccountingChart)
+	if u.Config == nil {
+		return chart, nil
+	}
+	custom, err := pkg.ParseChart(u.Config.Get(pkg.ConfigAccountingChart))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	for key, account := range custom {
+		chart[key] = account
+	}
+	return chart, nil
+}
+
+// account returns the account of a key of the chart, or of its group when the key is not mapped
+// eg: revenue.therapy falls back to revenue
+func (u *Usecase) account(chart map[string]string, key string) (string, error) {
+	for group := key; group != ""; {
+		if account, ok := chart[strings.ToLower(group)]; ok {
+			return account, nil
+		}
+		idx := strings.LastIndex(group, ".")
+		if idx < 0 {
+			break
+		}
+		group = group[:idx]
+	}
+	return "", u.error(pkg.ErrPrefInternal, fmt.Sprintf(pkg.ErrMissingAccount, key), 0, 0)
+}
+
+// entry returns a journal entry between the accounts of two keys of the chart
+// negative values are returned as positive ones with the accounts inverted
+func (u *Usecase) entry(chart map[string]string, date time.Time, debit, credit string, value float64, document,
+	history string) (*pkg.JournalEntry, error) {
+	if value < 0 {
+		debit, credit, value = credit, debit, -value
+	}
+	debitAccount, err := u.account(chart, debit)
+	if err != nil {
+		return nil, err
+	}
+	creditAccount, err := u.account(chart, credit)
+	if err != nil {
+		return nil, err
+	}
+	return &pkg.JournalEntry{Date: date, Debit: debitAccount, Credit: creditAccount,
+		Value: math.Round(value*100) / 100, Document: document, History: history}, nil
+}
+
+// invoiceEntries returns the entries of the items of the invoices of a period by service and of their iss
+// the items of the canceled invoices are entered too, as the credit note of their full value required to cancel
+// them reverses them, but not their iss. The credits carried to an invoice are not entered again since their
+// credit note already reduced the revenue and the receivable by its full value
+func (u *Usecase) invoiceEntries(chart map[string]string, keys *reportKeys, from, to time.Time) ([]*pkg.JournalEntry, error) {
+	notes, err := u.reportNotes()
+	if err != nil {
+		return nil, err
+	}
+	applied := map[string]bool{}
+	for _, n := range notes {
+		if n.AppliedID != nil && *n.AppliedID != n.InvoiceID {
+			applied[*n.AppliedID+"|"+fmt.Sprintf(pkg.CreditNoteDescription, n.ID)] = true
+		}
+	}
+	invoices, err := u.reportInvoices(from, to)
+	if err != nil {
+		return nil, err
+	}
+	rate := 0.0
+	if u.Config != nil {
+		if r, err := strconv.ParseFloat(strings.TrimSpace(u.Config.Get(pkg.ConfigNfseIssRate)), 64); err == nil && r > 0 {
+			rate = r
+		}
+	}
+	ret := []*pkg.JournalEntry{}
+	for _, i := range invoices {
+		items, err := u.reportItems(i.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if applied[i.ID+"|"+item.Description] || item.Value == 0 {
+				continue
+			}
+			key, err := u.reportItemKey(keys, item)
+			if err != nil {
+				return nil, err
+			}
+			e, err := u.entry(chart, i.Date, pkg.AccountReceivable, u.revenueKey(key), item.Value, i.ID,
+				fmt.Sprintf(pkg.JournalInvoice, i.ID, item.Description))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+		if iss := math.Round(i.Value*rate) / 100; iss > 0 && i.Status == pkg.InvoiceStatusActive {
+			e, err := u.entry(chart, i.Date, pkg.AccountIssExpense, pkg.AccountIssPayable, iss, i.ID,
+				fmt.Sprintf(pkg.JournalIss, i.ID))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// noteEntries returns the entries of the credit notes of a period reducing the revenue of their services
+// and the receivable of the client by the full value of the note, including the part carried to other invoices
+func (u *Usecase) noteEntries(chart map[string]string, keys *reportKeys, from, to time.Time) ([]*pkg.JournalEntry, error) {
+	notes, err := u.reportNotes()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(notes, func(a, b *domain.CreditNote) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	ret := []*pkg.JournalEntry{}
+	for _, n := range notes {
+		if n.Date.Before(from) || n.Date.After(to) || n.Value == 0 {
+			continue
+		}
+		shares, err := u.creditShares(keys, n)
+		if err != nil {
+			return nil, err
+		}
+		services := []string{}
+		for key := range shares {
+			services = append(services, key)
+		}
+		slices.Sort(services)
+		for _, key := range services {
+			e, err := u.entry(chart, n.Date, u.revenueKey(key), pkg.AccountReceivable, shares[key], n.ID,
+				fmt.Sprintf(pkg.JournalCreditNote, n.ID, n.InvoiceID))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// paymentEntries returns the entries of the payments matched to invoices and of the refunds of a period
+// the payments imported from bank files are received on the bank by file format and the other ones on cash
+func (u *Usecase) paymentEntries(chart map[string]string, from, to time.Time) ([]*pkg.JournalEntry, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Payment{}, -1, false,
+		fmt.Sprintf("status in ('%s', '%s')", pkg.PaymentStatusMatched, pkg.PaymentStatusRefund),
+		fmt.Sprintf("date >= '%s'", from.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("date <= '%s'", to.Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*pkg.JournalEntry{}
+	if base == nil {
+		return ret, nil
+	}
+	payments := *base.(*[]domain.Payment)
+	slices.SortFunc(payments, func(a, b domain.Payment) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, p := range payments {
+		if p.InvoiceID == nil {
+			continue
+		}
+		method, history := pkg.AccountCash, pkg.JournalPayment
+		if format := strings.Split(p.ID, "_")[0]; slices.Contains(bankFormats, format) {
+			method = pkg.AccountBank + "." + format
+		}
+		if p.Status == pkg.PaymentStatusRefund {
+			method, history = pkg.AccountBank, pkg.JournalRefund
+		}
+		e, err := u.entry(chart, p.Date, method, pkg.AccountReceivable, p.Value, p.ID,
+			fmt.Sprintf(history, p.ID, *p.InvoiceID))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, e)
+	}
+	return ret, nil
+}
+
+// revenueKey returns the key of the chart of the revenue of a service
+func (u *Usecase) revenueKey(service string) string {
+	if service == pkg.ReportUnassigned {
+		return pkg.AccountRevenue
+	}
+	return pkg.AccountRevenue + "." + service
+}
+
+// writeJournal writes the csv and the fixed width files of the journal entries of a month
+// replacing the files of a former export of the month
+func (u *Usecase) writeJournal(month time.Time, entries []*pkg.JournalEntry) error {
+	dir := pkg.DefaultAccountingOutputDir
+	if u.Config != nil && u.Config.Get(pkg.ConfigAccountingOutputDir) != "" {
+		dir = u.Config.Get(pkg.ConfigAccountingOutputDir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	body, err := pkg.JournalCSV(entries)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	files := map[string][]byte{
+		fmt.Sprintf(journalCSVFormat, month.Format(invoiceMonthFormat)):   body,
+		fmt.Sprintf(journalFixedFormat, month.Format(invoiceMonthFormat)): pkg.JournalFixed(entries),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+func TestAccountingExportReceivableNetsToZero(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, local) }
+	str := func(s string) *string { return &s }
+	objs := []interface{}{
+		// john paid his first invoice and the note over it was carried to the second one
+		&domain.Invoice{ID: "john_1", Date: day(1), ClientID: "john", Value: 100, Status: pkg.InvoiceStatusActive},
+		&domain.InvoiceItem{ID: "john_1_1", InvoiceID: "john_1", Value: 100, Description: "package"},
+		&domain.Payment{ID: "pay_john_1", Date: day(2), Value: 100, InvoiceID: str("john_1"), ClientID: str("john"),
+			Status: pkg.PaymentStatusMatched},
+		&domain.CreditNote{ID: "note_john", Date: day(3), InvoiceID: "john_1", ClientID: "john", Value: 30,
+			Credit: 30, Status: pkg.CreditNoteStatusApplied, AppliedID: str("john_2")},
+		&domain.Invoice{ID: "john_2", Date: day(4), ClientID: "john", Value: 20, Status: pkg.InvoiceStatusActive},
+		&domain.InvoiceItem{ID: "john_2_1", InvoiceID: "john_2", Value: 50, Description: "package"},
+		&domain.InvoiceItem{ID: "john_2_2", InvoiceID: "john_2", Value: -30,
+			Description: "credit note note_john"},
+		&domain.Payment{ID: "pay_john_2", Date: day(5), Value: 20, InvoiceID: str("john_2"), ClientID: str("john"),
+			Status: pkg.PaymentStatusMatched},
+		// mary had a note over the whole invoice shared by services that do not split evenly
+		&domain.Invoice{ID: "mary_1", Date: day(1), ClientID: "mary", Value: 100, Status: pkg.InvoiceStatusActive},
+		&domain.Agenda{ID: "mary_a", ClientID: "mary", ServiceID: "psico"},
+		&domain.Agenda{ID: "mary_b", ClientID: "mary", ServiceID: "fono"},
+		&domain.Agenda{ID: "mary_c", ClientID: "mary", ServiceID: "to"},
+		&domain.InvoiceItem{ID: "mary_1_1", InvoiceID: "mary_1", AgendaID: str("mary_a"), Value: 33.33},
+		&domain.InvoiceItem{ID: "mary_1_2", InvoiceID: "mary_1", AgendaID: str("mary_b"), Value: 33.33},
+		&domain.InvoiceItem{ID: "mary_1_3", InvoiceID: "mary_1", AgendaID: str("mary_c"), Value: 33.34},
+		&domain.CreditNote{ID: "note_mary", Date: day(3), InvoiceID: "mary_1", ClientID: "mary", Value: 10,
+			Status: pkg.CreditNoteStatusOpen},
+		&domain.Payment{ID: "pay_mary_1", Date: day(5), Value: 90, InvoiceID: str("mary_1"), ClientID: str("mary"),
+			Status: pkg.PaymentStatusMatched},
+		// paul had his invoice canceled by a credit note of its full value
+		&domain.Invoice{ID: "paul_1", Date: day(1), ClientID: "paul", Value: 50, Status: pkg.InvoiceStatusCanceled},
+		&domain.InvoiceItem{ID: "paul_1_1", InvoiceID: "paul_1", Value: 50, Description: "package"},
+		&domain.CreditNote{ID: "note_paul", Date: day(2), InvoiceID: "paul_1", ClientID: "paul", Value: 50,
+			Status: pkg.CreditNoteStatusApplied, AppliedID: str("paul_1")},
+	}
+	clients := map[string]string{}
+	for _, o := range objs {
+		value := reflect.ValueOf(o).Elem()
+		if client := value.FieldByName("ClientID"); client.IsValid() {
+			if client.Kind() == reflect.Ptr {
+				client = client.Elem()
+			}
+			clients[value.FieldByName("ID").String()] = client.String()
+		}
+	}
+	u, _ := newMemoryUsecase(map[string]string{pkg.ConfigAccountingOutputDir: t.TempDir()}, objs...)
+	if err := u.AccountingExport(&dto.AccountingExport{Month: "03/2024"}); err != nil {
+		t.Fatalf("AccountingExport() error = %v", err)
+	}
+	chart, _ := pkg.ParseChart(pkg.DefaultAccountingChart)
+	receivable, booked := map[string]float64{}, map[string]int{}
+	for _, out := range u.Out {
+		e := out.(*dto.AccountingExportOut)
+		value, _ := strconv.ParseFloat(e.Value, 64)
+		if e.Debit == chart[pkg.AccountReceivable] {
+			receivable[clients[e.Document]] += value
+			booked[clients[e.Document]]++
+		}
+		if e.Credit == chart[pkg.AccountReceivable] {
+			receivable[clients[e.Document]] -= value
+			booked[clients[e.Document]]++
+		}
+	}
+	for _, client := range []string{"john", "mary", "paul"} {
+		if booked[client] == 0 {
+			t.Errorf("AccountingExport() receivable of %s has no entries", client)
+		}
+		if math.Round(receivable[client]*100) != 0 {
+			t.Errorf("AccountingExport() receivable of %s = %.2f, want 0", client, receivable[client])
+		}
+	}
+}
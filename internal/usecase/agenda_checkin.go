@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// AgendaCheckin sets agendas as done or missed creating the linked sessions
+func (u *Usecase) AgendaCheckin(dtoIn interface{}) error {
+	dtoCheckin := dtoIn.(*dto.AgendaCheckin)
+	if err := dtoCheckin.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	agendas, err := u.findCheckinAgendas(dtoCheckin)
+	if err != nil {
+		return err
+	}
+	status := dtoCheckin.GetStatus()
+	result := []interface{}{}
+	for _, agenda := range agendas {
+		out := &dto.AgendaCheckinOut{ID: agenda.ID, ClientID: agenda.ClientID,
+			Start: agenda.Start.Format(pkg.DateTimeFormat), Status: agenda.Status}
+		unlock := u.lock(agenda.ClientID)
+		session, err := u.checkinAgenda(agenda.ID, status)
+		if err == nil {
+			_, err = u.renumberSessions(agenda.ClientID, false)
+		}
+		unlock()
+		if err != nil {
+			out.SessionID = err.Error()
+		} else {
+			out.Status = status
+			out.SessionID = session.ID
+		}
+		result = append(result, out)
+	}
+	u.Out = dtoCheckin.GetOut().GetDTO(result)
+	return nil
+}
+
+// findCheckinAgendas finds the agendas by id or the openned agendas of the day or week informed
+func (u *Usecase) findCheckinAgendas(dtoIn *dto.AgendaCheckin) ([]*domain.Agenda, error) {
+	agenda := &domain.Agenda{ID: dtoIn.ID, ClientID: dtoIn.ClientID}
+	var status []string
+	if dtoIn.ID == "" {
+		status = []string{pkg.AgendaStatusOpenned}
+	}
+	start, end, _ := dtoIn.GetRange()
+	agendas, err := agenda.LoadRange(u.Repo, start, end, status)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if len(agendas) == 0 {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrAgendaNotFound, 0, 0)
+	}
+	return agendas, nil
+}
+
+// checkinAgenda sets the agenda status and creates the linked session in one transaction
+func (u *Usecase) checkinAgenda(id string, status string) (*domain.Session, error) {
+	agendas, err := u.getLockAgenda(&domain.Agenda{ID: id}, time.Time{}, time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if agendas == nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrAgendaNotFound, 0, 0)
+	}
+	agenda := agendas[0]
+	defer u.unlockAgendas(agendas)
+	if agenda.Status != pkg.AgendaStatusOpenned {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrAgendaNotOpenned, 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	tied, _, err := u.Repo.Find(tx, &domain.Session{AgendaID: agenda.ID}, 1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if tied != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrAgendaAlreadyTied, 0, 0)
+	}
+	session := u.checkinSession(agenda, status)
+	seq, err := session.NextSequence(u.Repo, tx, u.sequenceByContract())
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	session.Sequence = &seq
+	session.ID += "_" + strconv.Itoa(seq)
+	if err := session.Format(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	agenda.Status = status
+	if err := u.Repo.Add(tx, session); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Save(tx, agenda); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return session, nil
+}
+
+// checkinSession returns a new session linked to the agenda
+func (u *Usecase) checkinSession(agenda *domain.Agenda, status string) *domain.Session {
+	id := fmt.Sprintf("%s_%s_%s", agenda.Start.Format("2006-01-02-15-04"), agenda.ClientID, agenda.ServiceID)
+	kind := pkg.SessionKindRegular
+	if agenda.Kind == pkg.AgendaKindExtra {
+		kind = pkg.SessionKindExtra
+	}
+	return &domain.Session{
+		ID:         id,
+		Date:       time.Now(),
+		ClientID:   agenda.ClientID,
+		ServiceID:  agenda.ServiceID,
+		At:         agenda.Start,
+		Status:     status,
+		Process:    pkg.ProcessStatusLinked,
+		AgendaID:   agenda.ID,
+		ContractID: agenda.ContractID,
+		Kind:       kind,
+	}
+}
@@ -156,20 +156,31 @@ func (u *Usecase) getItems(contract *domain.Contract, month time.Time) ([]*agend
 	return items, nil
 }
 
-// mounItems mounts the agenda items based on the contract and month
+// mounItems mounts the agenda items based on the contract and month skipping the paused ones
 func (u *Usecase) mountItems(contract *domain.Contract, month time.Time) ([]*agendaItem, error) {
 	beginMonth, endMonth := u.getBound(contract, month)
-	recur, services, prices, err := u.getPackageParams(contract.PackageID)
+	recur, packItems, services, prices, err := u.getPackageParams(contract.PackageID)
 	if err != nil {
 		return nil, err
 	}
+	history, err := domain.LoadPrices(u.Repo, contract.PackageID)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	pauses, err := contract.GetPauses(u.Repo, beginMonth, endMonth)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
 	items := []*agendaItem{}
 	count := 0
 	appended := 0
 	for start := &contract.Start; start != nil && !start.After(endMonth); start = recur.Next(*start) {
-		minutes, serviceId, price := u.getServicePrice(services, prices, count)
-		if !start.Before(beginMonth) && !start.After(endMonth) {
+		minutes, itemID, serviceId, price := u.getServicePrice(packItems, services, prices, count)
+		if !start.Before(beginMonth) && !start.After(endMonth) && !u.isPaused(*start, pauses) {
 			end := start.Add(time.Minute * time.Duration(minutes))
+			if price != nil {
+				price = u.priceInForce(history, contract.ID, &itemID, *start, price)
+			}
 			items = append(items, &agendaItem{start: *start, end: end, serviceId: serviceId, Price: price})
 			appended++
 		}
@@ -181,6 +192,25 @@ func (u *Usecase) mountItems(contract *domain.Contract, month time.Time) ([]*age
 	return items, nil
 }
 
+// priceInForce returns the price in force on a moment for the contract, package or item
+// It returns the fallback price if there is no price on the history
+func (u *Usecase) priceInForce(history []*domain.Price, contractID string, itemID *string, at time.Time, fallback *float64) *float64 {
+	if p := domain.PriceInForce(history, contractID, itemID, at); p != nil {
+		return p.Value
+	}
+	return fallback
+}
+
+// isPaused checks if a moment is inside one of the contract pauses
+func (u *Usecase) isPaused(t time.Time, pauses []*domain.ContractPause) bool {
+	for _, p := range pauses {
+		if p.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
 // getBound returns the bound of the contract based on the month
 func (u *Usecase) getBound(contract *domain.Contract, month time.Time) (time.Time, time.Time) {
 	beginMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
@@ -192,35 +222,35 @@ func (u *Usecase) getBound(contract *domain.Contract, month time.Time) (time.Tim
 	return beginMonth, endMonth
 }
 
-// getPackageParams returns the recurrence struct and serviice minutes of the package
-func (u *Usecase) getPackageParams(packId string) (*domain.Recurrence, []*domain.Service, []*float64, error) {
+// getPackageParams returns the recurrence struct and the items with their services and prices of the package
+func (u *Usecase) getPackageParams(packId string) (*domain.Recurrence, []*domain.PackageItem, []*domain.Service, []*float64, error) {
 	pack := domain.Package{ID: packId}
 	if ok, err := pack.Load(u.Repo); err != nil {
-		return nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		return nil, nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
 	} else if !ok {
-		return nil, nil, nil, u.error(pkg.ErrPrefInternal, pkg.ErrPackageNotFound, 0, 0)
+		return nil, nil, nil, nil, u.error(pkg.ErrPrefInternal, pkg.ErrPackageNotFound, 0, 0)
 	}
 	var err error
 	recur, err := pack.GetRecurrence(u.Repo)
 	if err != nil {
-		return nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		return nil, nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
 	}
-	services, prices, err := pack.GetServices(u.Repo)
+	items, services, prices, err := pack.GetServices(u.Repo)
 	if err != nil {
-		return nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		return nil, nil, nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
 	}
-	return recur, services, prices, nil
+	return recur, items, services, prices, nil
 }
 
-// getServicePrice returns the service price based on the count
-func (u *Usecase) getServicePrice(services []*domain.Service, prices []*float64, count int) (int, string, *float64) {
+// getServicePrice returns the package item, the service and its price based on the count
+func (u *Usecase) getServicePrice(items []*domain.PackageItem, services []*domain.Service, prices []*float64, count int) (int, string, string, *float64) {
 	idx := count % len(services)
 	m := services[idx].Minutes
 	var minutes int64 = 0
 	if m != nil {
 		minutes = *m
 	}
-	return int(minutes), services[idx].ID, prices[idx]
+	return int(minutes), items[idx].ID, services[idx].ID, prices[idx]
 }
 
 // delBound deletes the bound of the contract
@@ -265,6 +295,7 @@ func (u *Usecase) setAgenda(agenda *domain.Agenda, contract *domain.Contract, it
 	agenda.End = item.end
 	agenda.ServiceID = item.serviceId
 	agenda.Price = item.Price
+	agenda.Professional = contract.Professional
 	agenda.ID = fmt.Sprintf(idFormat, item.start.Format(idDateFormat), contract.ClientID)
 	if err := agenda.Format(u.Repo); err != nil {
 		return err
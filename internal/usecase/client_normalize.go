@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ClientNormalize migrates the phones of the clients and their contacts to the E.164 format
+// phones that can not be normalized are kept and reported as invalid
+func (u *Usecase) ClientNormalize(dtoIn interface{}) error {
+	dtoNormalize := dtoIn.(*dto.ClientNormalize)
+	if err := dtoNormalize.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	client := dtoNormalize.GetDomain()[0].(*domain.Client)
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, client, -1, false)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrClientNotFound, 0, 0)
+	}
+	result := []*dto.ClientNormalizeOut{}
+	for _, c := range *base.(*[]domain.Client) {
+		out := &dto.ClientNormalizeOut{ClientID: c.ID, Old: c.Phone}
+		if c.Phone, out.Result = u.normalizePhone(c.Phone); out.Result == pkg.PhoneNormalized {
+			if err := u.Repo.Save(tx, &c); err != nil {
+				return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+		}
+		out.New = c.Phone
+		result = append(result, out)
+		contacts, _, err := u.Repo.Find(tx, &domain.ClientContact{ClientID: c.ID}, -1, false)
+		if err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		if contacts == nil {
+			continue
+		}
+		for _, cc := range *contacts.(*[]domain.ClientContact) {
+			if cc.Phone == nil {
+				continue
+			}
+			out := &dto.ClientNormalizeOut{ClientID: c.ID, Contact: cc.ID, Old: *cc.Phone}
+			phone := ""
+			if phone, out.Result = u.normalizePhone(*cc.Phone); out.Result == pkg.PhoneNormalized {
+				cc.Phone = &phone
+				if err := u.Repo.Save(tx, &cc); err != nil {
+					return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+				}
+			}
+			out.New = *cc.Phone
+			result = append(result, out)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	u.Out = dtoNormalize.GetOut().GetDTO(result)
+	return nil
+}
+
+// normalizePhone returns the normalized phone and the result of the normalization
+// the phone is kept when it is already normalized or when it is invalid
+func (u *Usecase) normalizePhone(phone string) (string, string) {
+	normalized, err := pkg.NormalizePhone(phone)
+	if err != nil {
+		return phone, pkg.PhoneInvalid
+	}
+	if normalized == phone {
+		return phone, pkg.PhoneOk
+	}
+	return normalized, pkg.PhoneNormalized
+}
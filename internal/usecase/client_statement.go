@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+var (
+	// agingLimits are the days overdue closing each aging bucket of the statement but the last one
+	agingLimits = []int{30, 60, 90}
+)
+
+// ledgerEntry is a line of the account statement of a client
+// entries without value just inform the make-up credits and the credits applied on invoices
+type ledgerEntry struct {
+	date        time.Time
+	order       int
+	kind        string
+	document    string
+	description string
+	value       float64
+	informative bool
+}
+
+// ledger keeps the documents of a client read to mount its account statement
+type ledger struct {
+	invoices []*domain.Invoice
+	items    map[string][]*domain.InvoiceItem
+	payments []*domain.Payment
+	notes    []*domain.CreditNote
+	makeups  []*domain.Agenda
+}
+
+// ClientStatement mounts the account statement of a client on a period as a chronological ledger
+// of the invoice items, late charges, credit notes, payments and refunds with the running balance
+// and the make-up credits pending, followed by the aging of the open invoices on the end of the period
+func (u *Usecase) ClientStatement(dtoIn interface{}) error {
+	dtoStatement := dtoIn.(*dto.ClientStatement)
+	if err := dtoStatement.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, to, _ := dtoStatement.GetRange()
+	client := dtoStatement.GetDomain()[0].(*domain.Client)
+	if ok, err := client.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrClientNotFound, 0, 0)
+	}
+	l, err := u.loadLedger(client.ID)
+	if err != nil {
+		return err
+	}
+	entries := u.ledgerEntries(l)
+	ret := []*dto.ClientStatementOut{}
+	balance := 0.0
+	for _, e := range entries {
+		if e.date.Before(from) && !e.informative {
+			balance += e.value
+		}
+	}
+	if !from.IsZero() {
+		ret = append(ret, &dto.ClientStatementOut{Date: from.Format(pkg.DateFormat), Type: pkg.LedgerBalance,
+			Description: pkg.StatementOpening, Balance: u.amount(balance)})
+	}
+	for _, e := range entries {
+		if e.date.Before(from) || e.date.After(to) {
+			continue
+		}
+		out := &dto.ClientStatementOut{Date: e.date.Format(pkg.DateFormat), Type: e.kind, Document: e.document,
+			Description: e.description}
+		if !e.informative {
+			balance += e.value
+			out.Balance = u.amount(balance)
+			if e.value >= 0 {
+				out.Debit = u.amount(e.value)
+			} else {
+				out.Credit = u.amount(-e.value)
+			}
+		}
+		ret = append(ret, out)
+	}
+	ret = append(ret, &dto.ClientStatementOut{Date: to.Format(pkg.DateFormat), Type: pkg.LedgerBalance,
+		Description: pkg.StatementClosing, Balance: u.amount(balance)})
+	ret = append(ret, u.ledgerAging(l, to)...)
+	if len(l.makeups) > 0 {
+		ret = append(ret, &dto.ClientStatementOut{Type: pkg.LedgerMakeup,
+			Description: fmt.Sprintf(pkg.StatementMakeups, len(l.makeups))})
+	}
+	u.Output = dtoStatement.GetFormat()
+	u.Out = dtoStatement.GetOut().GetDTO(ret)
+	return nil
+}
+
+// loadLedger reads the invoices with their items, the payments, the credit notes
+// and the saved agendas not rescheduled yet of a client
+func (u *Usecase) loadLedger(clientID string) (*ledger, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	l := &ledger{items: map[string][]*domain.InvoiceItem{}}
+	base, _, err := u.Repo.Find(tx, &domain.Invoice{ClientID: clientID}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base != nil {
+		for _, i := range *base.(*[]domain.Invoice) {
+			l.invoices = append(l.invoices, &i)
+			items, _, err := u.Repo.Find(tx, &domain.InvoiceItem{InvoiceID: i.ID}, -1, false)
+			if err != nil {
+				return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+			if items != nil {
+				for _, item := range *items.(*[]domain.InvoiceItem) {
+					l.items[i.ID] = append(l.items[i.ID], &item)
+				}
+			}
+		}
+	}
+	base, _, err = u.Repo.Find(tx, &domain.Payment{ClientID: &clientID}, -1, false,
+		fmt.Sprintf("status in ('%s', '%s')", pkg.PaymentStatusMatched, pkg.PaymentStatusRefund))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base != nil {
+		for _, p := range *base.(*[]domain.Payment) {
+			l.payments = append(l.payments, &p)
+		}
+	}
+	base, _, err = u.Repo.Find(tx, &domain.CreditNote{ClientID: clientID}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base != nil {
+		for _, n := range *base.(*[]domain.CreditNote) {
+			l.notes = append(l.notes, &n)
+		}
+	}
+	if l.makeups, err = u.pendingMakeups(clientID); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// pendingMakeups returns the saved agendas of a client without a rescheduled agenda bonded to them
+func (u *Usecase) pendingMakeups(clientID string) ([]*domain.Agenda, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Agenda{ClientID: clientID, Kind: pkg.AgendaKindRescheduled}, -1, false,
+		"bond is not null")
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	rescheduled := map[string]bool{}
+	if base != nil {
+		for _, a := range *base.(*[]domain.Agenda) {
+			rescheduled[*a.Bond] = true
+		}
+	}
+	base, _, err = u.Repo.Find(tx, &domain.Agenda{ClientID: clientID, Status: pkg.AgendaStatusSaved}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Agenda{}
+	if base != nil {
+		for _, a := range *base.(*[]domain.Agenda) {
+			if !rescheduled[a.ID] {
+				ret = append(ret, &a)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// ledgerEntries returns the entries of the ledger in chronological order
+// the items of the credits carried to an invoice are informative since the credit note already credited them
+func (u *Usecase) ledgerEntries(l *ledger) []*ledgerEntry {
+	ret := []*ledgerEntry{}
+	applied := map[string]bool{}
+	for _, n := range l.notes {
+		if n.AppliedID != nil && *n.AppliedID != n.InvoiceID {
+			applied[*n.AppliedID+"|"+fmt.Sprintf(pkg.CreditNoteDescription, n.ID)] = true
+		}
+	}
+	paid := u.ledgerPaid(l, time.Time{})
+	for _, i := range l.invoices {
+		items := l.items[i.ID]
+		if len(items) == 0 {
+			ret = append(ret, &ledgerEntry{date: i.Date, kind: pkg.LedgerInvoice, document: i.ID,
+				description: pkg.LedgerInvoice, value: i.Value})
+		}
+		for _, item := range items {
+			ret = append(ret, &ledgerEntry{date: i.Date, kind: pkg.LedgerInvoice, document: i.ID,
+				description: item.Description, value: item.Value, informative: applied[i.ID+"|"+item.Description]})
+		}
+		if charges := u.chargesDue(i, paid[i.ID], l); charges > 0 && i.Due != nil {
+			ret = append(ret, &ledgerEntry{date: *i.Due, order: 1, kind: pkg.LedgerCharges, document: i.ID,
+				description: fmt.Sprintf(pkg.StatementCharges, i.ID), value: charges})
+		}
+	}
+	for _, n := range l.notes {
+		ret = append(ret, &ledgerEntry{date: n.Date, order: 2, kind: pkg.LedgerCredit, document: n.ID,
+			description: n.Reason, value: -n.Value})
+	}
+	for _, p := range l.payments {
+		kind, description := pkg.LedgerPayment, pkg.StatementPayment
+		if p.Status == pkg.PaymentStatusRefund {
+			kind, description = pkg.LedgerRefund, pkg.StatementRefund
+		}
+		ret = append(ret, &ledgerEntry{date: p.Date, order: 3, kind: kind, document: p.ID,
+			description: fmt.Sprintf(description, *p.InvoiceID), value: -p.Value})
+	}
+	for _, a := range l.makeups {
+		ret = append(ret, &ledgerEntry{date: a.Start, order: 4, kind: pkg.LedgerMakeup, document: a.ID,
+			description: fmt.Sprintf(pkg.StatementMakeup, a.ServiceID, a.Start.Format(pkg.DateTimeFormat)),
+			informative: true})
+	}
+	slices.SortStableFunc(ret, func(a, b *ledgerEntry) int {
+		da := time.Date(a.date.Year(), a.date.Month(), a.date.Day(), 0, 0, 0, 0, a.date.Location())
+		db := time.Date(b.date.Year(), b.date.Month(), b.date.Day(), 0, 0, 0, 0, b.date.Location())
+		if c := da.Compare(db); c != 0 {
+			return c
+		}
+		if a.order != b.order {
+			return a.order - b.order
+		}
+		return strings.Compare(a.document, b.document)
+	})
+	return ret
+}
+
+// ledgerAging returns the open value of the active invoices on a date by the days overdue
+func (u *Usecase) ledgerAging(l *ledger, at time.Time) []*dto.ClientStatementOut {
+	buckets := u.agingBuckets(l, at)
+	ret := []*dto.ClientStatementOut{}
+	for idx, value := range buckets {
+		description := pkg.StatementAgingCurrent
+		switch {
+		case idx == len(buckets)-1:
+			description = fmt.Sprintf(pkg.StatementAgingOver, agingLimits[len(agingLimits)-1])
+		case idx == 1:
+			description = fmt.Sprintf(pkg.StatementAgingRange, 1, agingLimits[0])
+		case idx > 1:
+			description = fmt.Sprintf(pkg.StatementAgingRange, agingLimits[idx-2]+1, agingLimits[idx-1])
+		}
+		ret = append(ret, &dto.ClientStatementOut{Date: at.Format(pkg.DateFormat), Type: pkg.LedgerAging,
+			Description: description, Balance: u.amount(value)})
+	}
+	return ret
+}
+
+// agingBuckets returns the open value of the active invoices on a date not due yet
+// and by the days overdue up to each aging limit and over the last one
+func (u *Usecase) agingBuckets(l *ledger, at time.Time) []float64 {
+	buckets := make([]float64, len(agingLimits)+2)
+	paid := u.ledgerPaid(l, at)
+	for _, i := range l.invoices {
+		if i.Status != pkg.InvoiceStatusActive || i.Value <= 0 || i.Date.After(at) {
+			continue
+		}
+		open := u.invoiceOpen(l, i, paid, at)
+		if open < paymentCents {
+			continue
+		}
+		due := i.Date
+		if i.Due != nil {
+			due = *i.Due
+		}
+		days := int(math.Floor(at.Sub(due).Hours() / 24))
+		bucket := len(agingLimits) + 1
+		for idx, limit := range agingLimits {
+			if days <= limit {
+				bucket = idx + 1
+				break
+			}
+		}
+		if days <= 0 {
+			bucket = 0
+		}
+		buckets[bucket] += open
+	}
+	return buckets
+}
+
+// invoiceOpen returns the open value of an invoice with its late charges less the reductions of its credit notes
+// and the value paid until a date or ever if the date is zero
+func (u *Usecase) invoiceOpen(l *ledger, i *domain.Invoice, paid map[string]float64, at time.Time) float64 {
+	open := i.Value + u.chargesDue(i, paid[i.ID], l) - paid[i.ID]
+	for _, n := range l.notes {
+		if n.InvoiceID == i.ID && (at.IsZero() || !n.Date.After(at)) {
+			open -= n.Reduction()
+		}
+	}
+	return open
+}
+
+// ledgerPaid returns the value paid net of refunds by invoice until a date or ever if the date is zero
+func (u *Usecase) ledgerPaid(l *ledger, at time.Time) map[string]float64 {
+	ret := map[string]float64{}
+	for _, p := range l.payments {
+		if at.IsZero() || !p.Date.After(at) {
+			ret[*p.InvoiceID] += p.Value
+		}
+	}
+	return ret
+}
+
+// chargesDue returns the late charges of an invoice still owed or paid
+// charges of a settled invoice count just up to the value paid over its balance
+func (u *Usecase) chargesDue(i *domain.Invoice, paid float64, l *ledger) float64 {
+	charges := i.Charges()
+	if charges <= 0 || i.PaymentStatus == pkg.InvoicePaymentStatusLate || i.PaymentStatus == pkg.InvoicePaymentStatusUnder {
+		return charges
+	}
+	if i.PaymentStatus != pkg.InvoicePaymentStatusPaid && i.PaymentStatus != pkg.InvoicePaymentStatusOver {
+		return 0
+	}
+	net := i.Value
+	for _, n := range l.notes {
+		if n.InvoiceID == i.ID {
+			net -= n.Reduction()
+		}
+	}
+	return math.Round(math.Min(charges, math.Max(paid-net, 0))*100) / 100
+}
+
+// amount formats a money value of the statement without negative zeros
+func (u *Usecase) amount(value float64) string {
+	value = math.Round(value*100) / 100
+	if value == 0 {
+		value = 0
+	}
+	return fmt.Sprintf("%.2f", value)
+}
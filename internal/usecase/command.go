@@ -13,19 +13,21 @@ import (
 // Usecase is a struct that groups all usecases of the application
 type CommandUsecase struct {
 	Repo    port.Repository
+	Config  port.Config
 	Log     port.Logger
 	UseCase port.UseCase
 }
 
 // UseCase is a function that returns a new UseCase struct
-func NewCommandUsecase(repo port.Repository, log port.Logger) *CommandUsecase {
+func NewCommandUsecase(repo port.Repository, config port.Config, log port.Logger, notifier port.Notifier) *CommandUsecase {
 	if err := repo.Migrate(domain.All()); err != nil {
 		panic(err)
 	}
 	return &CommandUsecase{
 		Repo:    repo,
+		Config:  config,
 		Log:     log,
-		UseCase: NewUsecase(repo, log),
+		UseCase: NewUsecase(repo, config, log, notifier),
 	}
 }
 
@@ -0,0 +1,314 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	successorIDFormat   = "%s_%s"
+	successorDateFormat = "20060102"
+	changeIDFormat      = "%s_%s_change"
+)
+
+// ContractChange changes the package of a contract from a date
+// the contract is ended the day before and a successor contract with the new package is created with the
+// agenda regenerated from the date. Months already billed receive an adjustment invoice with the prorated difference
+func (u *Usecase) ContractChange(dtoIn interface{}) error {
+	dtoChange := dtoIn.(*dto.ContractChange)
+	if err := dtoChange.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, _ := dtoChange.GetFrom()
+	contract := &domain.Contract{ID: dtoChange.ID}
+	if ok, err := contract.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractNotFound, 0, 0)
+	}
+	pack := &domain.Package{ID: dtoChange.PackageID}
+	if ok, err := pack.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrPackageNotFound, 0, 0)
+	}
+	if err := u.validateChange(contract, pack, from); err != nil {
+		return err
+	}
+	if err := contract.Lock(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	defer contract.Unlock(u.Repo)
+	unlock := u.lock(contract.ClientID)
+	defer unlock()
+	out, err := u.changeContract(contract, pack, from)
+	if err != nil {
+		return err
+	}
+	u.Out = dtoChange.GetOut().GetDTO(out)
+	return nil
+}
+
+// validateChange validates the change of package of a contract from a date
+func (u *Usecase) validateChange(contract *domain.Contract, pack *domain.Package, from time.Time) error {
+	if contract.PackageID == pack.ID {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrSamePackage, 0, 0)
+	}
+	first := time.Date(contract.Start.Year(), contract.Start.Month(), contract.Start.Day(), 0, 0, 0, 0, from.Location())
+	if !from.After(first) {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrChangeBeforeStart, 0, 0)
+	}
+	if contract.End != nil && from.After(*contract.End) {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrChangeAfterEnd, 0, 0)
+	}
+	if contract.IsLocked() {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractLocked, 0, 0)
+	}
+	agendas, err := (&domain.Agenda{ContractID: &contract.ID}).LoadRange(u.Repo, from, time.Time{}, nil)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	sessions, err := u.agendaSessions(contract.ClientID)
+	if err != nil {
+		return err
+	}
+	for _, a := range agendas {
+		if sessions[a.ID] != nil || (a.Status != pkg.AgendaStatusOpenned && a.Status != pkg.AgendaStatusCanceled) {
+			return u.error(pkg.ErrPrefBadRequest, pkg.ErrChangeAfterDone, 0, 0)
+		}
+	}
+	return nil
+}
+
+// changeContract ends the contract, creates the successor with its agenda and the adjustment invoices
+// the agenda and the invoices are saved after the successor, so a failure on them undoes the change
+func (u *Usecase) changeContract(contract *domain.Contract, pack *domain.Package, from time.Time) (*dto.ContractChangeOut, error) {
+	successor, err := u.successorContract(contract, pack, from)
+	if err != nil {
+		return nil, err
+	}
+	last := from.AddDate(0, 0, -1)
+	reason := fmt.Sprintf(pkg.ChangeReason, successor.ID)
+	ended := *contract
+	ended.End, ended.EndReason = &last, &reason
+	if err := ended.Format(u.Repo, "noduplicity"); err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	deleted, billed, err := u.futureAgendas(&ended, last)
+	if err != nil {
+		return nil, err
+	}
+	refunds, clients, err := u.agendaRefunds(billed)
+	if err != nil {
+		return nil, err
+	}
+	removed := append(deleted, billed...)
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Add(tx, successor); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Save(tx, &ended); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	for _, a := range removed {
+		if err := u.Repo.Delete(tx, &domain.Agenda{ID: a.ID}); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	original := *contract
+	contract.End, contract.EndReason = ended.End, ended.EndReason
+	count, err := u.successorAgendas(successor, from, removed)
+	if err != nil {
+		return nil, u.undoChange(contract, &original, successor, removed, err)
+	}
+	invoices, err := u.changeInvoices(&ended, successor, refunds, clients, from)
+	if err != nil {
+		return nil, u.undoChange(contract, &original, successor, removed, err)
+	}
+	out := &dto.ContractChangeOut{ID: contract.ID, SuccessorID: successor.ID, From: from.Format(pkg.DateFormat),
+		Deleted: strconv.Itoa(len(deleted)), Canceled: strconv.Itoa(len(billed)), Agendas: strconv.Itoa(count)}
+	out.InvoiceID, out.Value = u.adjustmentsOut(invoices)
+	return out, nil
+}
+
+// undoChange undoes a change that failed after the successor was saved in one transaction
+// deleting the successor with its agenda, restoring the end of the contract and its removed agendas
+// It returns the error of the change joined with the one of the undo if it fails too
+func (u *Usecase) undoChange(contract, original, successor *domain.Contract, removed []*domain.Agenda, cause error) error {
+	contract.End, contract.EndReason = original.End, original.EndReason
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	err := u.Repo.Delete(tx, &domain.Agenda{ContractID: &successor.ID})
+	if err == nil {
+		err = u.Repo.Delete(tx, &domain.Contract{ID: successor.ID})
+	}
+	if err == nil {
+		err = u.Repo.Save(tx, contract)
+	}
+	for _, a := range removed {
+		if err == nil {
+			err = u.Repo.Add(tx, a)
+		}
+	}
+	if err == nil {
+		err = u.Repo.Commit(tx)
+	}
+	if err != nil {
+		return errors.New(cause.Error() + " | " + u.error(pkg.ErrPrefInternal, err.Error(), 0, 0).Error())
+	}
+	return cause
+}
+
+// successorContract returns the contract that succeeds the changed one with the new package
+// it starts on the change date keeping the time of the original start
+func (u *Usecase) successorContract(contract *domain.Contract, pack *domain.Package, from time.Time) (*domain.Contract, error) {
+	id := fmt.Sprintf(successorIDFormat, contract.ID, from.Format(successorDateFormat))
+	if len(id) > 50 {
+		return nil, u.error(pkg.ErrPrefBadRequest, fmt.Sprintf(pkg.ErrLongSuccessorID, id), 0, 0)
+	}
+	start := time.Date(from.Year(), from.Month(), from.Day(), contract.Start.Hour(), contract.Start.Minute(),
+		contract.Start.Second(), 0, contract.Start.Location())
+	successor := &domain.Contract{
+		ID:           id,
+		Date:         time.Now(),
+		ClientID:     contract.ClientID,
+		SponsorID:    contract.SponsorID,
+		PackageID:    pack.ID,
+		BillingType:  contract.BillingType,
+		DueDay:       contract.DueDay,
+		Start:        start,
+		End:          contract.End,
+		Bond:         contract.Bond,
+		Origin:       &contract.ID,
+		Professional: contract.Professional,
+	}
+	if err := successor.Format(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	return successor, nil
+}
+
+// successorAgendas generates the successor agenda from the month of change until the last month
+// that had agendas removed from the changed contract. It returns the number of agendas generated
+func (u *Usecase) successorAgendas(successor *domain.Contract, from time.Time, removed []*domain.Agenda) (int, error) {
+	month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	last := month
+	for _, a := range removed {
+		if m := time.Date(a.Start.Year(), a.Start.Month(), 1, 0, 0, 0, 0, from.Location()); m.After(last) {
+			last = m
+		}
+	}
+	count := 0
+	for ; !month.After(last); month = month.AddDate(0, 1, 0) {
+		outs, err := u.GenerateAgenda(&dto.AgendaMake{}, successor, month)
+		if err != nil {
+			return 0, err
+		}
+		count += len(outs)
+	}
+	return count, nil
+}
+
+// changeInvoices returns the adjustment invoices of the months already billed for the changed contract from the change date
+// it refunds the removed billed agendas and the package price billed over the prorated price of the changed contract,
+// charges the prorated package price and the agendas of the successor. The refunds go to the client or payer
+// the items were billed to. It returns no invoices if there is nothing to adjust
+func (u *Usecase) changeInvoices(ended, successor *domain.Contract, refunds []*domain.InvoiceItem, clients []string, from time.Time) ([]*domain.Invoice, error) {
+	invoice := &domain.Invoice{
+		ID:            fmt.Sprintf(changeIDFormat, from.Format(invoiceMonthFormat), successor.ID),
+		Date:          time.Now(),
+		ClientID:      successor.ClientID,
+		Status:        pkg.DefaultInvoiceStatus,
+		SendStatus:    pkg.DefaultInvoiceSendStatus,
+		PaymentStatus: pkg.DefaultInvoicePaymentStatus,
+	}
+	items := refunds
+	charged := []*domain.Agenda{}
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	for i := 0; i < maxSettlementMonths; i, start = i+1, start.AddDate(0, 1, 0) {
+		end := start.AddDate(0, 1, 0).Add(time.Nanosecond * -1)
+		month := start
+		billed, billedClients, err := u.billedItems(&domain.InvoiceItem{ContractID: &ended.ID}, u.billedMonth(start))
+		if err != nil {
+			return nil, err
+		}
+		if len(billed) == 0 {
+			break
+		}
+		packages := []*domain.InvoiceItem{}
+		for j, b := range billed {
+			if b.PackageID != nil && *b.PackageID == ended.PackageID {
+				packages = append(packages, b)
+				clients = append(clients, billedClients[j])
+			}
+		}
+		if len(packages) > 0 {
+			due, err := u.proratedPrice(ended, start, end)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, u.refundShares(packages, due)...)
+		}
+		if successor.BillingType == pkg.BillingTypePrePaid || successor.BillingType == pkg.BillingTypePosPaid {
+			price, err := u.proratedPrice(successor, start, end)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, &domain.InvoiceItem{ContractID: &successor.ID, PackageID: &successor.PackageID,
+				Month: &month, Value: math.Max(price, 0), Description: fmt.Sprintf(pkg.ChangeDescription,
+					ended.PackageID, successor.PackageID, start.Format(pkg.MonthFormat))})
+			clients = append(clients, successor.ClientID)
+		}
+		contracts := map[string]*domain.Contract{successor.ID: successor}
+		pauses, err := successor.GetPauses(u.Repo, start, end)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		billing := start
+		if successor.BillingType == pkg.BillingTypePrePaid {
+			billing = start.AddDate(0, -1, 0)
+		}
+		agendas, err := u.billableAgendas(successor.ClientID, contracts, map[string][]*domain.ContractPause{successor.ID: pauses}, billing)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range agendas {
+			if a.ContractID == nil || *a.ContractID != successor.ID {
+				continue
+			}
+			items = append(items, u.invoiceItem(invoice.ID, 0, a, nil, month))
+			clients = append(clients, successor.ClientID)
+			a.BillingMonth = &billing
+			charged = append(charged, a)
+		}
+	}
+	invoices, invoiceItems, err := u.adjustmentInvoices(invoice, items, clients)
+	if err != nil {
+		return nil, err
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.addAdjustments(tx, invoices, invoiceItems); err != nil {
+		return nil, err
+	}
+	for _, a := range charged {
+		if err := u.Repo.Save(tx, a); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return invoices, nil
+}
@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	settlementIDFormat  = "%s_%s_end"
+	maxSettlementMonths = 12
+)
+
+// ContractEnd ends a contract on a date removing its future openned agendas
+// billed agendas are canceled instead of deleted and pre-paid contracts receive a settlement invoice
+// with the refund of what was billed after the end date. Contracts ended before, by this command or by a change,
+// have an end reason and are rejected, so their refunds are not issued again
+func (u *Usecase) ContractEnd(dtoIn interface{}) error {
+	dtoEnd := dtoIn.(*dto.ContractEnd)
+	if err := dtoEnd.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	date, _ := dtoEnd.GetDate()
+	contract := &domain.Contract{ID: dtoEnd.ID}
+	if ok, err := contract.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractNotFound, 0, 0)
+	}
+	if contract.End != nil && contract.EndReason != nil {
+		return u.error(pkg.ErrPrefBadRequest, fmt.Sprintf(pkg.ErrContractEnded, contract.End.Format(pkg.DateFormat)), 0, 0)
+	}
+	if date.Before(time.Date(contract.Start.Year(), contract.Start.Month(), contract.Start.Day(), 0, 0, 0, 0, date.Location())) {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrEndBeforeStart, 0, 0)
+	}
+	if contract.IsLocked() {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractLocked, 0, 0)
+	}
+	if err := contract.Lock(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	defer contract.Unlock(u.Repo)
+	unlock := u.lock(contract.ClientID)
+	defer unlock()
+	out, err := u.endContract(contract, date, dtoEnd.Reason)
+	if err != nil {
+		return err
+	}
+	u.Out = dtoEnd.GetOut().GetDTO(out)
+	return nil
+}
+
+// endContract ends the contract and settles it in one transaction
+// the contract is just changed in memory after commit, as it is saved again when unlocked
+func (u *Usecase) endContract(contract *domain.Contract, date time.Time, reason string) (*dto.ContractEndOut, error) {
+	ended := *contract
+	ended.End = &date
+	ended.EndReason = &reason
+	if err := ended.Format(u.Repo, "noduplicity"); err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	deleted, canceled, err := u.futureAgendas(&ended, date)
+	if err != nil {
+		return nil, err
+	}
+	invoices, items := []*domain.Invoice{}, map[string][]*domain.InvoiceItem{}
+	if ended.BillingType == pkg.BillingTypePrePaid {
+		if invoices, items, err = u.settlementInvoices(&ended, canceled, date); err != nil {
+			return nil, err
+		}
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Save(tx, &ended); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	for _, a := range deleted {
+		if err := u.Repo.Delete(tx, &domain.Agenda{ID: a.ID}); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	for _, a := range canceled {
+		a.Status = pkg.AgendaStatusCanceled
+		if err := u.Repo.Save(tx, a); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.addAdjustments(tx, invoices, items); err != nil {
+		return nil, err
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	contract.End, contract.EndReason = ended.End, ended.EndReason
+	out := &dto.ContractEndOut{ID: contract.ID, End: date.Format(pkg.DateFormat),
+		Deleted: strconv.Itoa(len(deleted)), Canceled: strconv.Itoa(len(canceled))}
+	out.InvoiceID, out.Value = u.adjustmentsOut(invoices)
+	return out, nil
+}
+
+// futureAgendas returns the openned agendas of the contract after the end date not tied to sessions
+// split in the ones to be deleted and the already billed ones to be canceled
+func (u *Usecase) futureAgendas(contract *domain.Contract, date time.Time) ([]*domain.Agenda, []*domain.Agenda, error) {
+	agenda := &domain.Agenda{ClientID: contract.ClientID, ContractID: &contract.ID}
+	agendas, err := agenda.LoadRange(u.Repo, date.AddDate(0, 0, 1), time.Time{}, []string{pkg.AgendaStatusOpenned})
+	if err != nil {
+		return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	sessions, err := u.agendaSessions(contract.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	deleted, canceled := []*domain.Agenda{}, []*domain.Agenda{}
+	for _, a := range agendas {
+		switch {
+		case sessions[a.ID] != nil:
+			continue
+		case a.BillingMonth != nil:
+			canceled = append(canceled, a)
+		default:
+			deleted = append(deleted, a)
+		}
+	}
+	return deleted, canceled, nil
+}
+
+// settlementInvoices returns the refund invoices of a pre-paid contract with the billed canceled agendas
+// and the package price billed for the days after the end date. The refunds go to the client or payer
+// the items were billed to, on one invoice each. It returns no invoices if there is nothing to refund
+func (u *Usecase) settlementInvoices(contract *domain.Contract, canceled []*domain.Agenda, date time.Time) ([]*domain.Invoice, map[string][]*domain.InvoiceItem, error) {
+	invoice := &domain.Invoice{
+		ID:            fmt.Sprintf(settlementIDFormat, date.Format(invoiceMonthFormat), contract.ID),
+		Date:          time.Now(),
+		ClientID:      contract.ClientID,
+		Status:        pkg.DefaultInvoiceStatus,
+		SendStatus:    pkg.DefaultInvoiceSendStatus,
+		PaymentStatus: pkg.DefaultInvoicePaymentStatus,
+	}
+	refunds, clients, err := u.agendaRefunds(canceled)
+	if err != nil {
+		return nil, nil, err
+	}
+	packages, packageClients, err := u.packageRefunds(contract, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	return u.adjustmentInvoices(invoice, append(refunds, packages...), append(clients, packageClients...))
+}
+
+// agendaRefunds returns the refunds of the items billed for the agendas with the clients they were billed to
+func (u *Usecase) agendaRefunds(agendas []*domain.Agenda) ([]*domain.InvoiceItem, []string, error) {
+	refunds, clients := []*domain.InvoiceItem{}, []string{}
+	for _, a := range agendas {
+		billed, billedClients, err := u.billedItems(&domain.InvoiceItem{AgendaID: &a.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, b := range billed {
+			refunds = append(refunds, u.refundItem(b, b.Value))
+		}
+		clients = append(clients, billedClients...)
+	}
+	return refunds, clients, nil
+}
+
+// packageRefunds returns the refunds of the package price billed for the days after the end date
+// on the month of the end and on the following months already billed with the clients they were billed to
+func (u *Usecase) packageRefunds(contract *domain.Contract, date time.Time) ([]*domain.InvoiceItem, []string, error) {
+	refunds, clients := []*domain.InvoiceItem{}, []string{}
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	for i := 0; i < maxSettlementMonths; i, start = i+1, start.AddDate(0, 1, 0) {
+		end := start.AddDate(0, 1, 0).Add(time.Nanosecond * -1)
+		billed, billedClients, err := u.billedItems(&domain.InvoiceItem{ContractID: &contract.ID,
+			PackageID: &contract.PackageID}, u.billedMonth(start))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(billed) == 0 {
+			break
+		}
+		due, err := u.proratedPrice(contract, start, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		refunds = append(refunds, u.refundShares(billed, due)...)
+		clients = append(clients, billedClients...)
+	}
+	return refunds, clients, nil
+}
+
+// refundShares returns the refunds of what was billed over the value due spread by the billed items
+// proportionally to their values. The rounding remainder goes to the last one
+func (u *Usecase) refundShares(billed []*domain.InvoiceItem, due float64) []*domain.InvoiceItem {
+	total := 0.0
+	for _, b := range billed {
+		total += b.Value
+	}
+	refund := math.Max(math.Round((total-due)*100)/100, 0)
+	ret := []*domain.InvoiceItem{}
+	rest := refund
+	for i, b := range billed {
+		value := rest
+		if i < len(billed)-1 {
+			value = math.Round(refund*b.Value/total*100) / 100
+		}
+		rest = math.Round((rest-value)*100) / 100
+		ret = append(ret, u.refundItem(b, value))
+	}
+	return ret
+}
+
+// refundItem returns the item refunding a value of a billed item keeping its links
+func (u *Usecase) refundItem(billed *domain.InvoiceItem, value float64) *domain.InvoiceItem {
+	return &domain.InvoiceItem{AgendaID: billed.AgendaID, ContractID: billed.ContractID, PackageID: billed.PackageID,
+		Month: billed.Month, Value: -value, Description: pkg.RefundDescription + " " + billed.Description}
+}
+
+// billedMonth returns the condition of the items billed on a month
+func (u *Usecase) billedMonth(month time.Time) string {
+	return fmt.Sprintf("month = '%s'", month.Format("2006-01-02 15:04:05"))
+}
+
+// billedItems returns the items with value of the active invoices matching the filter and the extra conditions
+// with the clients of their invoices
+func (u *Usecase) billedItems(filter *domain.InvoiceItem, extras ...interface{}) ([]*domain.InvoiceItem, []string, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, filter, -1, false, extras...)
+	if err != nil {
+		return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	items, clients := []*domain.InvoiceItem{}, []string{}
+	if base == nil {
+		return items, clients, nil
+	}
+	billed := *base.(*[]domain.InvoiceItem)
+	slices.SortFunc(billed, func(a, b domain.InvoiceItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, item := range billed {
+		if item.Value <= 0 {
+			continue
+		}
+		invoice := &domain.Invoice{ID: item.InvoiceID}
+		if ok, err := invoice.Load(u.Repo); err != nil {
+			return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		} else if !ok || invoice.Status != pkg.InvoiceStatusActive {
+			continue
+		}
+		items = append(items, &item)
+		clients = append(clients, invoice.ClientID)
+	}
+	return items, clients, nil
+}
+
+// adjustmentInvoices returns the invoices of the adjustment items grouped by the clients they are billed to
+// the base invoice is the one of the contract client and the payers have their own invoice with the payer id
+// as suffix. Items without value are discarded and the invoices with negative value are refunds
+func (u *Usecase) adjustmentInvoices(base *domain.Invoice, items []*domain.InvoiceItem, clients []string) ([]*domain.Invoice, map[string][]*domain.InvoiceItem, error) {
+	invoices := []*domain.Invoice{}
+	byClient := map[string]*domain.Invoice{}
+	invoiceItems := map[string][]*domain.InvoiceItem{}
+	for idx, item := range items {
+		if item.Value == 0 {
+			continue
+		}
+		i := byClient[clients[idx]]
+		if i == nil {
+			invoice := *base
+			if clients[idx] != base.ClientID {
+				invoice.ID = fmt.Sprintf(invoicePayerIDFormat, base.ID, clients[idx])
+				invoice.ClientID = clients[idx]
+			}
+			i = &invoice
+			byClient[clients[idx]] = i
+			invoices = append(invoices, i)
+		}
+		item.InvoiceID = i.ID
+		item.ID = fmt.Sprintf(invoiceItemIDFormat, i.ID, len(invoiceItems[i.ID])+1)
+		invoiceItems[i.ID] = append(invoiceItems[i.ID], item)
+		i.Value += item.Value
+	}
+	slices.SortFunc(invoices, func(a, b *domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, i := range invoices {
+		i.Value = math.Round(i.Value*100) / 100
+		if i.Value < 0 {
+			i.PaymentStatus = pkg.InvoicePaymentStatusRefund
+		}
+		if err := i.Format(u.Repo); err != nil {
+			return nil, nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+		}
+		pix, err := u.invoicePix(i, i.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		i.Pix = pix
+	}
+	return invoices, invoiceItems, nil
+}
+
+// addAdjustments adds the adjustment invoices and their items on the transaction
+func (u *Usecase) addAdjustments(tx interface{}, invoices []*domain.Invoice, items map[string][]*domain.InvoiceItem) error {
+	for _, invoice := range invoices {
+		if err := u.Repo.Add(tx, invoice); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		for _, item := range items[invoice.ID] {
+			if err := u.Repo.Add(tx, item); err != nil {
+				return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+		}
+	}
+	return nil
+}
+
+// adjustmentsOut returns the ids and the total value of the adjustment invoices to the output
+func (u *Usecase) adjustmentsOut(invoices []*domain.Invoice) (string, string) {
+	if len(invoices) == 0 {
+		return "", ""
+	}
+	ids, value := []string{}, 0.0
+	for _, i := range invoices {
+		ids = append(ids, i.ID)
+		value += i.Value
+	}
+	return strings.Join(ids, ","), fmt.Sprintf("%.2f", math.Round(value*100)/100)
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+func TestContractEndSettlesPrePaidOnce(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	month := func(m time.Month) *time.Time {
+		ret := time.Date(2024, m, 1, 0, 0, 0, 0, local)
+		return &ret
+	}
+	str := func(s string) *string { return &s }
+	price, due := 300.0, int64(10)
+	u, repo := newMemoryUsecase(nil,
+		&domain.Client{ID: "ana", Name: "Ana"},
+		&domain.Package{ID: "pilates", Price: &price},
+		&domain.Contract{ID: "ana_pilates", ClientID: "ana", PackageID: "pilates", BillingType: pkg.BillingTypePrePaid,
+			DueDay: &due, Date: *month(1), Start: *month(1)},
+		&domain.Invoice{ID: "2024_03_ana", Date: *month(3), ClientID: "ana", Value: 300,
+			Status: pkg.InvoiceStatusActive},
+		&domain.InvoiceItem{ID: "2024_03_ana_001", InvoiceID: "2024_03_ana", ContractID: str("ana_pilates"),
+			PackageID: str("pilates"), Month: month(4), Value: 300, Description: "pilates 04/2024"},
+		&domain.Invoice{ID: "2024_04_ana", Date: *month(4), ClientID: "ana", Value: 300,
+			Status: pkg.InvoiceStatusActive},
+		&domain.InvoiceItem{ID: "2024_04_ana_001", InvoiceID: "2024_04_ana", ContractID: str("ana_pilates"),
+			PackageID: str("pilates"), Month: month(5), Value: 300, Description: "pilates 05/2024"},
+	)
+	if err := u.ContractEnd(&dto.ContractEnd{ID: "ana_pilates", Date: "15/04/2024", Reason: "moved"}); err != nil {
+		t.Fatalf("ContractEnd() error = %v", err)
+	}
+	out := u.Out[0].(*dto.ContractEndOut)
+	if out.InvoiceID != "2024_04_ana_pilates_end" || out.Value != "-450.00" {
+		t.Errorf("ContractEnd() settlement = %s %s, want 2024_04_ana_pilates_end -450.00", out.InvoiceID, out.Value)
+	}
+	if err := u.ContractEnd(&dto.ContractEnd{ID: "ana_pilates", Date: "10/05/2024", Reason: "moved"}); err == nil ||
+		!strings.Contains(err.Error(), "already ended") {
+		t.Errorf("ContractEnd() again error = %v, want already ended", err)
+	}
+	refunds := 0.0
+	for _, o := range repo.all(&domain.InvoiceItem{}) {
+		if item := o.(*domain.InvoiceItem); item.Value < 0 {
+			refunds += item.Value
+		}
+	}
+	if refunds != -450 {
+		t.Errorf("ContractEnd() refunds = %.2f, want -450.00", refunds)
+	}
+	contract := &domain.Contract{ID: "ana_pilates"}
+	if _, err := contract.Load(u.Repo); err != nil || contract.End == nil || contract.IsLocked() {
+		t.Errorf("ContractEnd() contract = %+v", contract)
+	}
+}
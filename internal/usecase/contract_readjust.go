@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	priceIDFormat      = "%s_%s"
+	priceItemIDFormat  = "%s_%s_%s"
+	readjustDateFormat = "20060102"
+	readjustMonths     = 12
+)
+
+// readjustRate returns the percentage and the description of the readjustment for an anniversary
+type readjustRate func(anniversary time.Time) (float64, string, error)
+
+// ContractReadjust readjusts the prices of the contracts on their last anniversary until the date
+// by a percentage or by the accumulated index of the twelve months before the anniversary.
+// The new prices are recorded on the price history of the package just for the contract
+func (u *Usecase) ContractReadjust(dtoIn interface{}) error {
+	dtoReadjust := dtoIn.(*dto.ContractReadjust)
+	if err := dtoReadjust.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	date, _ := dtoReadjust.GetDate()
+	rate, err := u.readjustRate(dtoReadjust)
+	if err != nil {
+		return err
+	}
+	contract := dtoReadjust.GetDomain()[0]
+	_, extras, err := dtoReadjust.GetInstructions(contract)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, contract, -1, false, extras...)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrUnfound, 0, 0)
+	}
+	contracts := *base.(*[]domain.Contract)
+	slices.SortFunc(contracts, func(a, b domain.Contract) int {
+		if a.ID < b.ID {
+			return -1
+		}
+		if a.ID > b.ID {
+			return 1
+		}
+		return 0
+	})
+	ret := []*dto.ContractReadjustOut{}
+	for i := range contracts {
+		out, err := u.readjustContract(&contracts[i], date, rate)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, out...)
+	}
+	u.Out = dtoReadjust.GetOut().GetDTO(ret)
+	return nil
+}
+
+// readjustRate returns the function that gives the readjustment percentage of an anniversary
+// the index one accumulates the monthly values of the twelve months before the anniversary
+func (u *Usecase) readjustRate(dtoIn *dto.ContractReadjust) (readjustRate, error) {
+	if dtoIn.Index == "" {
+		percent := dtoIn.GetPercent()
+		return func(time.Time) (float64, string, error) {
+			return percent, fmt.Sprintf(pkg.ReadjustPercent, percent), nil
+		}, nil
+	}
+	index, err := dtoIn.GetIndex()
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	return func(anniversary time.Time) (float64, string, error) {
+		factor := 1.0
+		month := time.Date(anniversary.Year(), anniversary.Month(), 1, 0, 0, 0, 0, anniversary.Location())
+		for i := 0; i < readjustMonths; i++ {
+			month = month.AddDate(0, -1, 0)
+			value, ok := index[month.Format(pkg.MonthFormat)]
+			if !ok {
+				return 0, "", fmt.Errorf(pkg.ErrIndexMonthMissing, month.Format(pkg.MonthFormat))
+			}
+			factor *= 1 + value/100
+		}
+		percent := (factor - 1) * 100
+		return percent, fmt.Sprintf(pkg.ReadjustIndex, percent), nil
+	}, nil
+}
+
+// readjustContract readjusts the package price, or the item prices when the package has no price,
+// of a contract on its last anniversary until the date. It skips contracts already readjusted on it
+func (u *Usecase) readjustContract(contract *domain.Contract, date time.Time, rate readjustRate) ([]*dto.ContractReadjustOut, error) {
+	anniversary := time.Date(date.Year(), contract.Start.Month(), contract.Start.Day(), 0, 0, 0, 0, date.Location())
+	if anniversary.After(date) {
+		anniversary = anniversary.AddDate(-1, 0, 0)
+	}
+	out := &dto.ContractReadjustOut{ContractID: contract.ID, Anniversary: anniversary.Format(pkg.DateFormat)}
+	first := time.Date(contract.Start.Year(), contract.Start.Month(), contract.Start.Day(), 0, 0, 0, 0, date.Location())
+	if !anniversary.After(first) {
+		out.Result = pkg.ReadjustNotAnniversary
+		return []*dto.ContractReadjustOut{out}, nil
+	}
+	if contract.IsLocked() {
+		out.Result = pkg.Locked
+		return []*dto.ContractReadjustOut{out}, nil
+	}
+	prices, err := u.readjustTargets(contract, anniversary)
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		out.Result = pkg.ReadjustNoPrice
+		return []*dto.ContractReadjustOut{out}, nil
+	}
+	percent, reason, err := rate(anniversary)
+	if err != nil {
+		out.Result = err.Error()
+		return []*dto.ContractReadjustOut{out}, nil
+	}
+	ret := []*dto.ContractReadjustOut{}
+	adds := []*domain.Price{}
+	for _, price := range prices {
+		one := *out
+		if price.ItemID != nil {
+			one.ItemID = *price.ItemID
+		}
+		one.Old = fmt.Sprintf("%.2f", *price.Value)
+		if ok, err := price.Load(u.Repo); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		} else if ok {
+			one.New, one.Result = fmt.Sprintf("%.2f", *price.Value), pkg.ReadjustAlready
+			ret = append(ret, &one)
+			continue
+		}
+		value := math.Round(*price.Value*(1+percent/100)*100) / 100
+		price.Value = &value
+		price.Reason = fmt.Sprintf(pkg.ReadjustReason, reason)
+		if err := price.Format(u.Repo); err != nil {
+			return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+		}
+		one.New, one.Result = fmt.Sprintf("%.2f", value), pkg.ReadjustDone
+		ret = append(ret, &one)
+		adds = append(adds, price)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for _, price := range adds {
+		if err := u.Repo.Add(tx, price); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return ret, nil
+}
+
+// readjustTargets returns the prices of the contract to be readjusted on the anniversary
+// with the value in force the day before it. The package price has precedence over the item prices
+func (u *Usecase) readjustTargets(contract *domain.Contract, anniversary time.Time) ([]*domain.Price, error) {
+	pack := &domain.Package{ID: contract.PackageID}
+	if ok, err := pack.Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return nil, u.error(pkg.ErrPrefInternal, pkg.ErrPackageNotFound, 0, 0)
+	}
+	history, err := domain.LoadPrices(u.Repo, pack.ID)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	before := anniversary.Add(time.Nanosecond * -1)
+	day := anniversary.Format(readjustDateFormat)
+	target := func(id string, itemID *string, fallback *float64) *domain.Price {
+		return &domain.Price{ID: id, Date: time.Now(), PackageID: pack.ID, ItemID: itemID, ContractID: &contract.ID,
+			Start: anniversary, Value: u.priceInForce(history, contract.ID, itemID, before, fallback)}
+	}
+	if pack.Price != nil && *pack.Price > 0 {
+		return []*domain.Price{target(fmt.Sprintf(priceIDFormat, contract.ID, day), nil, pack.Price)}, nil
+	}
+	items, err := pack.GetItems(u.Repo)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Price{}
+	for _, item := range items {
+		if item.Price == nil {
+			continue
+		}
+		ret = append(ret, target(fmt.Sprintf(priceItemIDFormat, contract.ID, day, item.ID), &item.ID, item.Price))
+	}
+	return ret, nil
+}
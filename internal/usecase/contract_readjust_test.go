@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+func TestContractReadjustChainsYearlyPrices(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, local) }
+	price, due := 300.0, int64(10)
+	contract := &domain.Contract{ID: "ana_pilates", ClientID: "ana", PackageID: "pilates",
+		BillingType: pkg.BillingTypePosPaid, DueDay: &due, Date: day(2023, 3, 10), Start: day(2023, 3, 10)}
+	pack := &domain.Package{ID: "pilates", Price: &price}
+	u, _ := newMemoryUsecase(nil, &domain.Client{ID: "ana", Name: "Ana"}, pack, contract)
+	steps := []struct {
+		date   string
+		result string
+		value  string
+	}{
+		{"15/03/2024", pkg.ReadjustDone, "330.00"},
+		{"20/03/2024", pkg.ReadjustAlready, "330.00"},
+		{"15/03/2025", pkg.ReadjustDone, "363.00"},
+	}
+	for _, s := range steps {
+		if err := u.ContractReadjust(&dto.ContractReadjust{ID: "ana_pilates", Date: s.date, Percent: "10"}); err != nil {
+			t.Fatalf("ContractReadjust(%s) error = %v", s.date, err)
+		}
+		out := u.Out[0].(*dto.ContractReadjustOut)
+		if len(u.Out) != 1 || out.Result != s.result || out.New != s.value {
+			t.Errorf("ContractReadjust(%s) = %+v, want %s %s", s.date, out, s.result, s.value)
+		}
+	}
+	prices := map[time.Time]float64{day(2024, 3, 9): 300, day(2024, 3, 10): 330, day(2025, 3, 9): 330,
+		day(2025, 4, 1): 363}
+	for at, want := range prices {
+		got, err := u.packagePrice(contract, pack, at)
+		if err != nil || got == nil || *got != want {
+			t.Errorf("packagePrice(%s) = %v, %v, want %.2f", at.Format(pkg.DateFormat), got, err, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"github.com/lavinas/ephemeris/internal/domain"
 	"github.com/lavinas/ephemeris/internal/port"
 	"github.com/lavinas/ephemeris/pkg"
 )
@@ -17,6 +18,9 @@ func (c *Usecase) Add(dtoIn interface{}) error {
 	result := []interface{}{}
 	count := 1
 	for _, domain := range domains {
+		if err := c.assignSequence(tx, domain); err != nil {
+			return c.error(pkg.ErrPrefInternal, err.Error(), count, len(domains))
+		}
 		if err := domain.Format(c.Repo); err != nil {
 			return c.error(pkg.ErrPrefBadRequest, err.Error(), count, len(domains))
 		}
@@ -29,11 +33,25 @@ func (c *Usecase) Add(dtoIn interface{}) error {
 	if err := c.Repo.Commit(tx); err != nil {
 		return c.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
 	}
+	if err := c.renumberAdded(domains); err != nil {
+		return err
+	}
 	out := in.GetOut()
 	c.Out = out.GetDTO(result)
 	return nil
 }
 
+// renumberAdded renumbers the sessions sequence of the clients when added sessions are out of order
+func (c *Usecase) renumberAdded(domains []port.Domain) error {
+	sessions := []*domain.Session{}
+	for _, d := range domains {
+		if s, ok := d.(*domain.Session); ok {
+			sessions = append(sessions, s)
+		}
+	}
+	return c.renumberOutOfOrder(sessions)
+}
+
 // Get is a method that gets a dto from the repository
 func (c *Usecase) Get(dtoIn interface{}) error {
 	in := dtoIn.(port.DTOIn)
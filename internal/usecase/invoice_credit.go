@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	creditNoteIDFormat = "%s_cn%02d"
+	refundIDFormat     = "refund_%s"
+)
+
+// InvoiceCredit issues a credit note of an invoice, of one of its items or of a billed agenda
+// the value still open on the invoice reduces its balance and the value exceeding it, already paid,
+// is refunded as a payment out or carried as a credit to the next invoice of the client
+func (u *Usecase) InvoiceCredit(dtoIn interface{}) error {
+	dtoCredit := dtoIn.(*dto.InvoiceCredit)
+	if err := dtoCredit.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	note := dtoCredit.GetDomain()[0].(*domain.CreditNote)
+	unlock := u.lock("payment")
+	defer unlock()
+	invoice := &domain.Invoice{ID: strings.ToLower(note.InvoiceID)}
+	if ok, err := invoice.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrInvoiceNotFound, 0, 0)
+	}
+	if invoice.Status == pkg.InvoiceStatusCanceled {
+		return u.error(pkg.ErrPrefBadRequest, fmt.Sprintf(pkg.ErrCreditNoteCanceled, invoice.ID), 0, 0)
+	}
+	notes, err := domain.LoadCreditNotes(u.Repo, invoice.ID)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	note.ID = fmt.Sprintf(creditNoteIDFormat, invoice.ID, len(notes)+1)
+	if dtoCredit.IsFull() {
+		if note.Value, err = note.Available(u.Repo); err != nil {
+			return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+		}
+	}
+	if err := note.Format(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	paid, err := domain.PaidValues(u.Repo)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	reduced := 0.0
+	for _, n := range notes {
+		reduced += n.Reduction()
+	}
+	refund := u.settleCredit(invoice, note, reduced, paid[invoice.ID], dtoCredit.IsRefund())
+	if refund != nil {
+		paid[invoice.ID] += refund.Value
+	}
+	invoice.PaymentStatus = u.creditStatus(invoice, invoice.Value-reduced-note.Reduction(), paid[invoice.ID])
+	// the pix sent and rendered after the credit should charge just what is left to pay on the invoice
+	open := math.Max(math.Round((invoice.Value-reduced-note.Reduction()-paid[invoice.ID])*100)/100, 0)
+	if invoice.Pix, err = u.invoicePix(invoice, open); err != nil {
+		return err
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Add(tx, note); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if refund != nil {
+		if err := u.Repo.Add(tx, refund); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Save(tx, invoice); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	refunded := 0.0
+	if refund != nil {
+		refunded = -refund.Value
+	}
+	u.Out = dtoCredit.GetOut().GetDTO([]*dto.InvoiceCreditOut{{CreditNoteID: note.ID, InvoiceID: invoice.ID,
+		ClientID: invoice.ClientID, Value: fmt.Sprintf("%.2f", note.Value),
+		Reduced: fmt.Sprintf("%.2f", note.Reduction()-refunded), Carried: fmt.Sprintf("%.2f", note.Credit),
+		Refunded: fmt.Sprintf("%.2f", refunded), Status: note.Status, PaymentStatus: invoice.PaymentStatus}})
+	return nil
+}
+
+// settleCredit sets how the credit note is settled by the balance still open on the invoice
+// the value over the balance was already paid and is returned as the refund payment out or carried as credit
+func (u *Usecase) settleCredit(invoice *domain.Invoice, note *domain.CreditNote, reduced, paid float64, refund bool) *domain.Payment {
+	open := math.Max(math.Round((invoice.Value-reduced-paid)*100)/100, 0)
+	excess := math.Round((note.Value-open)*100) / 100
+	switch {
+	case excess <= 0:
+		note.Status = pkg.CreditNoteStatusApplied
+		note.AppliedID = &invoice.ID
+	case refund:
+		note.Status = pkg.CreditNoteStatusRefunded
+		return &domain.Payment{ID: u.truncate(fmt.Sprintf(refundIDFormat, note.ID), paymentMaxID), Date: note.Date,
+			Value: -excess, InvoiceID: &invoice.ID, ClientID: &invoice.ClientID,
+			Reference: u.truncate(note.Reason, paymentMaxRef), Status: pkg.PaymentStatusRefund}
+	default:
+		note.Status = pkg.CreditNoteStatusOpen
+		note.Credit = excess
+	}
+	return nil
+}
+
+// creditStatus returns the payment status of an invoice by its balance net of the credit notes and the value paid
+// an invoice not paid keeps its status while it still has balance
+func (u *Usecase) creditStatus(invoice *domain.Invoice, net, paid float64) string {
+	if paid < paymentCents && net >= paymentCents {
+		return invoice.PaymentStatus
+	}
+	return u.paidStatus(net, invoice.Charges(), paid)
+}
+
+// applyCredits applies the open credits of the clients as items of their new invoices while they fit on the invoice value
+// it returns the credit notes applied
+func (u *Usecase) applyCredits(invoices []*domain.Invoice, items map[string][]*domain.InvoiceItem) ([]*domain.CreditNote, error) {
+	ret := []*domain.CreditNote{}
+	for _, i := range invoices {
+		credits, err := domain.OpenCredits(u.Repo, i.ClientID)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		applied := false
+		for _, c := range credits {
+			if c.Credit > i.Value+paymentCents {
+				continue
+			}
+			items[i.ID] = append(items[i.ID], &domain.InvoiceItem{
+				ID:          fmt.Sprintf(invoiceItemIDFormat, i.ID, len(items[i.ID])+1),
+				InvoiceID:   i.ID,
+				Value:       -c.Credit,
+				Description: fmt.Sprintf(pkg.CreditNoteDescription, c.ID),
+			})
+			i.Value = math.Round((i.Value-c.Credit)*100) / 100
+			c.Status = pkg.CreditNoteStatusApplied
+			c.AppliedID = &i.ID
+			ret = append(ret, c)
+			applied = true
+		}
+		if applied && i.Value < paymentCents {
+			i.PaymentStatus = pkg.InvoicePaymentStatusPaid
+		}
+	}
+	return ret, nil
+}
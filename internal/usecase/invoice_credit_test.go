@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+func TestInvoiceCreditRegeneratesPixForOpenValue(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, local)
+	str := func(s string) *string { return &s }
+	gross := "gross payload"
+	u, repo := newMemoryUsecase(map[string]string{pkg.ConfigPixKey: "pix@clinic.com", pkg.ConfigPixName: "Clinic",
+		pkg.ConfigPixCity: "Sao Paulo"},
+		&domain.Client{ID: "ana", Name: "Ana"},
+		&domain.Invoice{ID: "2024_03_ana", Date: date, ClientID: "ana", Value: 100, Status: pkg.InvoiceStatusActive,
+			PaymentStatus: pkg.InvoicePaymentStatusUnder, Pix: &gross},
+		&domain.InvoiceItem{ID: "2024_03_ana_001", InvoiceID: "2024_03_ana", Value: 100, Description: "pilates"},
+		&domain.Payment{ID: "pay_ana", Date: date, Value: 20, InvoiceID: str("2024_03_ana"), ClientID: str("ana"),
+			Status: pkg.PaymentStatusMatched},
+	)
+	steps := []struct {
+		value string
+		pix   string
+	}{
+		{"30", "540550.00"},
+		{"50", ""},
+	}
+	for _, s := range steps {
+		if err := u.InvoiceCredit(&dto.InvoiceCredit{ID: "2024_03_ana", Value: s.value, Reason: "missed sessions",
+			Date: "10/03/2024"}); err != nil {
+			t.Fatalf("InvoiceCredit(%s) error = %v", s.value, err)
+		}
+		invoice := repo.all(&domain.Invoice{})[0].(*domain.Invoice)
+		switch {
+		case s.pix == "" && invoice.Pix != nil:
+			t.Errorf("InvoiceCredit(%s) pix = %s, want none as nothing is left to pay", s.value, *invoice.Pix)
+		case s.pix != "" && (invoice.Pix == nil || !strings.Contains(*invoice.Pix, s.pix)):
+			t.Errorf("InvoiceCredit(%s) pix = %v, want amount %s", s.value, invoice.Pix, s.pix)
+		}
+	}
+}
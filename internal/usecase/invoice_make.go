@@ -0,0 +1,506 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	invoiceIDFormat      = "%s_%s"
+	invoiceItemIDFormat  = "%s_%03d"
+	invoiceMonthFormat   = "2006_01"
+	invoicePayerIDFormat = "%s_%s"
+	invoiceExtraIDFormat = "%s_extra%d"
+)
+
+var (
+	// billableStatus are the agenda status billed for each contract billing type
+	billableStatus = map[string][]string{
+		pkg.BillingTypePrePaid: {pkg.AgendaStatusOpenned, pkg.AgendaStatusLocked, pkg.AgendaStatusDone,
+			pkg.AgendaStatusMissed},
+		pkg.BillingTypePosPaid: {pkg.AgendaStatusOpenned, pkg.AgendaStatusLocked, pkg.AgendaStatusDone,
+			pkg.AgendaStatusMissed},
+		pkg.BillingTypePosSession: {pkg.AgendaStatusDone, pkg.AgendaStatusMissed},
+		pkg.BillingTypePerSession: {pkg.AgendaStatusDone, pkg.AgendaStatusMissed},
+	}
+)
+
+// InvoiceMake makes the invoices of the month for the clients with active contracts
+// monthly billed contracts have the package price prorated by the paused days and
+// the unbilled agendas of the month are the invoice items with the discount of the linked session applied
+// pre-paid contracts are billed in advance with the package price and the agendas of the next month
+// packages and agendas already billed on the month are skipped, so running it again just bills what is new
+// on an extra invoice of the client
+func (u *Usecase) InvoiceMake(dtoIn interface{}) error {
+	dtoInvoice := dtoIn.(*dto.InvoiceMake)
+	if err := dtoInvoice.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	start, _, _ := dtoInvoice.GetMonth()
+	contracts, err := u.invoiceContracts(dtoInvoice)
+	if err != nil {
+		return err
+	}
+	clients := []string{}
+	for clientID := range contracts {
+		clients = append(clients, clientID)
+	}
+	slices.Sort(clients)
+	result := []interface{}{}
+	for _, clientID := range clients {
+		unlock := u.lock(clientID)
+		outs, err := u.invoiceClient(clientID, contracts[clientID], start)
+		unlock()
+		if err != nil {
+			return err
+		}
+		for _, out := range outs {
+			result = append(result, out)
+		}
+	}
+	if len(result) == 0 {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoAgendasToBill, 0, 0)
+	}
+	u.Out = dtoInvoice.GetOut().GetDTO(result)
+	return nil
+}
+
+// invoiceContracts returns the contracts active on the month grouped by client
+func (u *Usecase) invoiceContracts(dtoIn *dto.InvoiceMake) (map[string]map[string]*domain.Contract, error) {
+	contract, extras, err := dtoIn.GetInstructions(dtoIn.GetDomain()[0])
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, contract, -1, false, extras...)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrContractNotFound, 0, 0)
+	}
+	ret := map[string]map[string]*domain.Contract{}
+	for _, c := range *base.(*[]domain.Contract) {
+		if ret[c.ClientID] == nil {
+			ret[c.ClientID] = map[string]*domain.Contract{}
+		}
+		ret[c.ClientID][c.ID] = &c
+	}
+	return ret, nil
+}
+
+// invoiceClient makes the invoice of the client with the package prices and the billable agendas of the month
+// items of contracts with payers are split on one invoice per payer. It returns nil if there is nothing to bill
+func (u *Usecase) invoiceClient(clientID string, contracts map[string]*domain.Contract, start time.Time) ([]*dto.InvoiceMakeOut, error) {
+	pauses := map[string][]*domain.ContractPause{}
+	for id, c := range contracts {
+		from, to := u.billedPeriod(c.BillingType, start)
+		p, err := c.GetPauses(u.Repo, from, to)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		pauses[id] = p
+	}
+	agendas, err := u.billableAgendas(clientID, contracts, pauses, start)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := u.agendaSessions(clientID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := u.invoiceID(clientID, start)
+	if err != nil {
+		return nil, err
+	}
+	invoice := &domain.Invoice{
+		ID:            id,
+		Date:          time.Now(),
+		ClientID:      clientID,
+		Status:        pkg.DefaultInvoiceStatus,
+		SendStatus:    pkg.DefaultInvoiceSendStatus,
+		PaymentStatus: pkg.DefaultInvoicePaymentStatus,
+		Due:           u.invoiceDue(contracts, start, time.Now()),
+	}
+	items, owners, err := u.packageItems(invoice.ID, contracts, pauses, start)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range agendas {
+		billing, owner := pkg.BillingTypePerSession, ""
+		if a.ContractID != nil && contracts[*a.ContractID] != nil {
+			billing, owner = contracts[*a.ContractID].BillingType, *a.ContractID
+		}
+		period, _ := u.billedPeriod(billing, start)
+		item := u.invoiceItem(invoice.ID, len(items)+1, a, sessions[a.ID], period)
+		if item.Value == 0 && (billing == pkg.BillingTypePrePaid || billing == pkg.BillingTypePosPaid) {
+			continue
+		}
+		items = append(items, item)
+		owners = append(owners, owner)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	invoices, invoiceItems, err := u.payerInvoices(invoice, items, owners)
+	if err != nil {
+		return nil, err
+	}
+	credits, err := u.applyCredits(invoices, invoiceItems)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range invoices {
+		if err := i.Format(u.Repo); err != nil {
+			return nil, u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+		}
+	}
+	if err := u.saveInvoices(invoices, invoiceItems, agendas, credits, start); err != nil {
+		return nil, err
+	}
+	ret := []*dto.InvoiceMakeOut{}
+	for _, i := range invoices {
+		ret = append(ret, &dto.InvoiceMakeOut{ID: i.ID, ClientID: i.ClientID, Month: start.Format(pkg.MonthFormat),
+			Items: strconv.Itoa(len(invoiceItems[i.ID])), Value: fmt.Sprintf("%.2f", i.Value)})
+	}
+	return ret, nil
+}
+
+// invoiceID returns the id of the invoice of the client on the month
+// or the id of an extra one when the month was already billed for the client
+func (u *Usecase) invoiceID(clientID string, month time.Time) (string, error) {
+	base := fmt.Sprintf(invoiceIDFormat, month.Format(invoiceMonthFormat), clientID)
+	id := base
+	for n := 2; ; n++ {
+		invoice := &domain.Invoice{ID: id}
+		if ok, err := invoice.Load(u.Repo); err != nil {
+			return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		} else if !ok {
+			return id, nil
+		}
+		id = fmt.Sprintf(invoiceExtraIDFormat, base, n)
+	}
+}
+
+// payerInvoices splits the items of contracts with payers between the client invoice and one invoice per payer
+// the owners are the contract ids of the items. Invoices left without items are discarded
+func (u *Usecase) payerInvoices(invoice *domain.Invoice, items []*domain.InvoiceItem, owners []string) ([]*domain.Invoice, map[string][]*domain.InvoiceItem, error) {
+	invoices := []*domain.Invoice{invoice}
+	byClient := map[string]*domain.Invoice{invoice.ClientID: invoice}
+	invoiceItems := map[string][]*domain.InvoiceItem{}
+	add := func(i *domain.Invoice, item *domain.InvoiceItem) {
+		item.InvoiceID = i.ID
+		item.ID = fmt.Sprintf(invoiceItemIDFormat, i.ID, len(invoiceItems[i.ID])+1)
+		invoiceItems[i.ID] = append(invoiceItems[i.ID], item)
+		i.Value = math.Round((i.Value+item.Value)*100) / 100
+	}
+	payers := map[string][]*domain.ContractPayer{}
+	for idx, item := range items {
+		owner := owners[idx]
+		if _, ok := payers[owner]; !ok && owner != "" {
+			p, err := domain.LoadContractPayers(u.Repo, owner)
+			if err != nil {
+				return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+			payers[owner] = p
+		}
+		if len(payers[owner]) == 0 {
+			add(invoice, item)
+			continue
+		}
+		shares, rest := domain.SplitValue(item.Value, payers[owner])
+		for j, p := range payers[owner] {
+			if shares[j] == 0 {
+				continue
+			}
+			i := byClient[p.ClientID]
+			if i == nil {
+				payerInvoice := *invoice
+				payerInvoice.ID = fmt.Sprintf(invoicePayerIDFormat, invoice.ID, p.ClientID)
+				payerInvoice.ClientID = p.ClientID
+				payerInvoice.Value = 0
+				i = &payerInvoice
+				byClient[p.ClientID] = i
+				invoices = append(invoices, i)
+			}
+			add(i, &domain.InvoiceItem{AgendaID: item.AgendaID, ContractID: item.ContractID, PackageID: item.PackageID,
+				Month: item.Month, Value: shares[j], Description: fmt.Sprintf(pkg.ShareDescription, item.Description,
+					p.ShareText())})
+		}
+		if rest != 0 || item.Value == 0 {
+			item.Value = rest
+			add(invoice, item)
+		}
+	}
+	ret := []*domain.Invoice{}
+	for _, i := range invoices {
+		if len(invoiceItems[i.ID]) > 0 {
+			ret = append(ret, i)
+		}
+	}
+	return ret, invoiceItems, nil
+}
+
+// packageItems returns the invoice items of the package prices of monthly billed contracts with their contract ids
+// the price is prorated by the days of the month billed the contract is active and not paused
+// packages already billed for the contract on the month billed are skipped
+func (u *Usecase) packageItems(invoiceID string, contracts map[string]*domain.Contract,
+	pauses map[string][]*domain.ContractPause, month time.Time) ([]*domain.InvoiceItem, []string, error) {
+	ids := []string{}
+	for id, c := range contracts {
+		if c.BillingType == pkg.BillingTypePrePaid || c.BillingType == pkg.BillingTypePosPaid {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	items := []*domain.InvoiceItem{}
+	owners := []string{}
+	for _, id := range ids {
+		contract := contracts[id]
+		start, end := u.billedPeriod(contract.BillingType, month)
+		pack := &domain.Package{ID: contract.PackageID}
+		if ok, err := pack.Load(u.Repo); err != nil {
+			return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		} else if !ok {
+			return nil, nil, u.error(pkg.ErrPrefInternal, pkg.ErrPackageNotFound, 0, 0)
+		}
+		price, err := u.packagePrice(contract, pack, start)
+		if err != nil {
+			return nil, nil, err
+		}
+		if price == nil || *price <= 0 {
+			continue
+		}
+		billed, _, err := u.billedItems(&domain.InvoiceItem{ContractID: &contract.ID, PackageID: &pack.ID},
+			u.billedMonth(start))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(billed) > 0 {
+			continue
+		}
+		days, active := u.activeDays(contract, pauses[id], start, end)
+		if active == 0 {
+			continue
+		}
+		description := fmt.Sprintf("%s %s", pack.ID, start.Format(pkg.MonthFormat))
+		if active < days {
+			description += fmt.Sprintf(" (%d/%d)", active, days)
+		}
+		owners = append(owners, id)
+		items = append(items, &domain.InvoiceItem{
+			ID:          fmt.Sprintf(invoiceItemIDFormat, invoiceID, len(items)+1),
+			InvoiceID:   invoiceID,
+			ContractID:  &contract.ID,
+			PackageID:   &pack.ID,
+			Month:       &start,
+			Value:       math.Round(*price*float64(active)/float64(days)*100) / 100,
+			Description: description,
+		})
+	}
+	return items, owners, nil
+}
+
+// proratedPrice returns the package price of the contract prorated by the active and not paused days of the month
+func (u *Usecase) proratedPrice(contract *domain.Contract, start, end time.Time) (float64, error) {
+	pack := &domain.Package{ID: contract.PackageID}
+	if ok, err := pack.Load(u.Repo); err != nil {
+		return 0, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return 0, u.error(pkg.ErrPrefInternal, pkg.ErrPackageNotFound, 0, 0)
+	}
+	price, err := u.packagePrice(contract, pack, start)
+	if err != nil {
+		return 0, err
+	}
+	if price == nil {
+		return 0, nil
+	}
+	pauses, err := contract.GetPauses(u.Repo, start, end)
+	if err != nil {
+		return 0, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	days, active := u.activeDays(contract, pauses, start, end)
+	return math.Round(*price*float64(active)/float64(days)*100) / 100, nil
+}
+
+// packagePrice returns the package price in force for the contract on a moment
+func (u *Usecase) packagePrice(contract *domain.Contract, pack *domain.Package, at time.Time) (*float64, error) {
+	if pack.Price == nil || *pack.Price <= 0 {
+		return pack.Price, nil
+	}
+	history, err := domain.LoadPrices(u.Repo, pack.ID)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return u.priceInForce(history, contract.ID, nil, at, pack.Price), nil
+}
+
+// activeDays returns the days of the month and the days the contract is active and not paused on it
+func (u *Usecase) activeDays(contract *domain.Contract, pauses []*domain.ContractPause, start, end time.Time) (int, int) {
+	first := time.Date(contract.Start.Year(), contract.Start.Month(), contract.Start.Day(), 0, 0, 0, 0, start.Location())
+	days, active := 0, 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days++
+		if d.Before(first) || (contract.End != nil && d.After(*contract.End)) {
+			continue
+		}
+		if !u.isPaused(d, pauses) {
+			active++
+		}
+	}
+	return days, active
+}
+
+// billedPeriod returns the first and the last moment of the period billed on a month for a billing type
+// pre-paid contracts are billed in advance for the next month and the other ones for the month itself
+func (u *Usecase) billedPeriod(billing string, month time.Time) (time.Time, time.Time) {
+	if billing == pkg.BillingTypePrePaid {
+		month = month.AddDate(0, 1, 0)
+	}
+	return month, month.AddDate(0, 1, 0).Add(time.Nanosecond * -1)
+}
+
+// billableAgendas returns the unbilled and not paused agendas of the period billed on the month with billable status
+// for its contract billing type. Agendas without contract are billed as per session
+func (u *Usecase) billableAgendas(clientID string, contracts map[string]*domain.Contract,
+	pauses map[string][]*domain.ContractPause, month time.Time) ([]*domain.Agenda, error) {
+	_, end := u.billedPeriod(pkg.BillingTypePrePaid, month)
+	agendas, err := (&domain.Agenda{ClientID: clientID}).LoadRange(u.Repo, month, end, nil)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Agenda{}
+	for _, a := range agendas {
+		if a.BillingMonth != nil {
+			continue
+		}
+		billing := pkg.BillingTypePerSession
+		if a.ContractID != nil && contracts[*a.ContractID] != nil {
+			billing = contracts[*a.ContractID].BillingType
+			if u.isPaused(a.Start, pauses[*a.ContractID]) {
+				continue
+			}
+		}
+		if from, to := u.billedPeriod(billing, month); a.Start.Before(from) || a.Start.After(to) {
+			continue
+		}
+		if slices.Contains(billableStatus[billing], a.Status) {
+			ret = append(ret, a)
+		}
+	}
+	slices.SortFunc(ret, func(a, b *domain.Agenda) int {
+		return a.Start.Compare(b.Start)
+	})
+	return ret, nil
+}
+
+// agendaSessions returns the sessions of the client tied to agendas mapped by agenda id
+func (u *Usecase) agendaSessions(clientID string) (map[string]*domain.Session, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Session{ClientID: clientID}, -1, false, "agenda_id <> ''")
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := map[string]*domain.Session{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, s := range *base.(*[]domain.Session) {
+		ret[s.AgendaID] = &s
+	}
+	return ret, nil
+}
+
+// invoiceItem returns the invoice item of an agenda billed on a month with the session discount applied
+func (u *Usecase) invoiceItem(invoiceID string, seq int, agenda *domain.Agenda, session *domain.Session, month time.Time) *domain.InvoiceItem {
+	value := 0.0
+	if agenda.Price != nil {
+		value = *agenda.Price
+	}
+	if session != nil {
+		value = session.ApplyDiscount(value)
+	}
+	return &domain.InvoiceItem{
+		ID:          fmt.Sprintf(invoiceItemIDFormat, invoiceID, seq),
+		InvoiceID:   invoiceID,
+		AgendaID:    &agenda.ID,
+		ContractID:  agenda.ContractID,
+		Month:       &month,
+		Value:       value,
+		Description: fmt.Sprintf("%s %s", agenda.ServiceID, agenda.Start.Format(pkg.DateTimeFormat)),
+	}
+}
+
+// invoiceDue returns the due date of the invoice on the earliest due day of the contracts in the month billed
+// or on the next month when that day has already passed on the invoice date
+func (u *Usecase) invoiceDue(contracts map[string]*domain.Contract, month, date time.Time) *time.Time {
+	day, _ := strconv.ParseInt(pkg.DefaultDueDay, 10, 64)
+	found := false
+	for _, c := range contracts {
+		if c.DueDay != nil && (!found || *c.DueDay < day) {
+			day = *c.DueDay
+			found = true
+		}
+	}
+	due := u.dueOn(month, day)
+	if today := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, month.Location()); due.Before(today) {
+		due = u.dueOn(month.AddDate(0, 1, 0), day)
+	}
+	return &due
+}
+
+// dueOn returns the day of the month limited to the last day of it
+func (u *Usecase) dueOn(month time.Time, day int64) time.Time {
+	last := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, month.Location()).Day()
+	return time.Date(month.Year(), month.Month(), min(int(day), last), 0, 0, 0, 0, month.Location())
+}
+
+// saveInvoices saves the invoices and their items, sets the billing month of the agendas
+// and the credit notes applied in one transaction
+func (u *Usecase) saveInvoices(invoices []*domain.Invoice, items map[string][]*domain.InvoiceItem, agendas []*domain.Agenda,
+	credits []*domain.CreditNote, month time.Time) error {
+	for _, invoice := range invoices {
+		pix, err := u.invoicePix(invoice, invoice.Value)
+		if err != nil {
+			return err
+		}
+		invoice.Pix = pix
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for _, invoice := range invoices {
+		if err := u.Repo.Add(tx, invoice); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		for _, item := range items[invoice.ID] {
+			if err := u.Repo.Add(tx, item); err != nil {
+				return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+		}
+	}
+	for _, a := range agendas {
+		a.BillingMonth = &month
+		if err := u.Repo.Save(tx, a); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	for _, c := range credits {
+		if err := u.Repo.Save(tx, c); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return nil
+}
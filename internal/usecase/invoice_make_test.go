@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// invoiceMakeFixture returns the objects of a client with a pos-paid package paused on ten days of april
+// and an attended session billed per session
+func invoiceMakeFixture() []interface{} {
+	local, _ := time.LoadLocation(pkg.Location)
+	day := func(m time.Month, d, h int) time.Time { return time.Date(2024, m, d, h, 0, 0, 0, local) }
+	str := func(s string) *string { return &s }
+	price, session, due := 300.0, 80.0, int64(10)
+	return []interface{}{
+		&domain.Client{ID: "ana", Name: "Ana"},
+		&domain.Package{ID: "pilates", Price: &price},
+		&domain.Contract{ID: "ana_pilates", ClientID: "ana", PackageID: "pilates", BillingType: pkg.BillingTypePosPaid,
+			DueDay: &due, Date: day(1, 1, 0), Start: day(1, 1, 0)},
+		&domain.ContractPause{ID: "ana_trip", ContractID: "ana_pilates", Start: day(4, 11, 0), End: day(4, 20, 0)},
+		&domain.Agenda{ID: "ana_0402", ClientID: "ana", ServiceID: "pilates", ContractID: str("ana_pilates"),
+			Start: day(4, 2, 9), End: day(4, 2, 10), Kind: pkg.AgendaKindRegular, Status: pkg.AgendaStatusDone},
+		&domain.Agenda{ID: "ana_0405", ClientID: "ana", ServiceID: "physio", Start: day(4, 5, 9), End: day(4, 5, 10),
+			Price: &session, Kind: pkg.AgendaKindRegular, Status: pkg.AgendaStatusDone},
+	}
+}
+
+func TestInvoiceMakeProratesPausedPackage(t *testing.T) {
+	u, repo := newMemoryUsecase(nil, invoiceMakeFixture()...)
+	if err := u.InvoiceMake(&dto.InvoiceMake{Month: "04/2024"}); err != nil {
+		t.Fatalf("InvoiceMake() error = %v", err)
+	}
+	out := u.Out[0].(*dto.InvoiceMakeOut)
+	if len(u.Out) != 1 || out.ID != "2024_04_ana" || out.Items != "2" || out.Value != "280.00" {
+		t.Errorf("InvoiceMake() = %+v, want 2024_04_ana with 2 items of 280.00", out)
+	}
+	want := map[string]float64{"pilates 04/2024 (20/30)": 200, "physio 05/04/2024 09:00": 80}
+	for _, o := range repo.all(&domain.InvoiceItem{}) {
+		item := o.(*domain.InvoiceItem)
+		if value, ok := want[item.Description]; !ok || value != item.Value {
+			t.Errorf("InvoiceMake() item %s = %.2f", item.Description, item.Value)
+		}
+	}
+}
+
+func TestInvoiceMakeAgainBillsOnlyWhatIsNew(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	u, repo := newMemoryUsecase(nil, invoiceMakeFixture()...)
+	if err := u.InvoiceMake(&dto.InvoiceMake{Month: "04/2024"}); err != nil {
+		t.Fatalf("InvoiceMake() error = %v", err)
+	}
+	if err := u.InvoiceMake(&dto.InvoiceMake{Month: "04/2024"}); err == nil {
+		t.Errorf("InvoiceMake() again without new agendas error = nil, want %s", pkg.ErrNoAgendasToBill)
+	}
+	price := 50.0
+	repo.Add(nil, &domain.Agenda{ID: "ana_0425", ClientID: "ana", ServiceID: "physio",
+		Start: time.Date(2024, 4, 25, 9, 0, 0, 0, local), End: time.Date(2024, 4, 25, 10, 0, 0, 0, local),
+		Price: &price, Kind: pkg.AgendaKindRegular, Status: pkg.AgendaStatusDone})
+	if err := u.InvoiceMake(&dto.InvoiceMake{Month: "04/2024"}); err != nil {
+		t.Fatalf("InvoiceMake() again error = %v", err)
+	}
+	out := u.Out[0].(*dto.InvoiceMakeOut)
+	if len(u.Out) != 1 || out.ID != "2024_04_ana_extra2" || out.Items != "1" || out.Value != "50.00" {
+		t.Errorf("InvoiceMake() again = %+v, want 2024_04_ana_extra2 with 1 item of 50.00", out)
+	}
+	if invoices := repo.all(&domain.Invoice{}); len(invoices) != 2 {
+		t.Errorf("InvoiceMake() invoices = %d, want 2", len(invoices))
+	}
+}
@@ -0,0 +1,366 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	nfseFileFormat     = "%s.xml"
+	nfseBatchFormat    = "lote_%s.xml"
+	nfseRpsIDFormat    = "rps%d"
+	nfseBatchIDFormat  = "lote%s"
+	nfseDateFormat     = "2006-01-02"
+	nfseDiscrimination = 2000
+	nfseRazaoSocial    = 150
+	nfseEmail          = 80
+	nfseTelefone       = 20
+	nfseRpsTipo        = 1
+	nfseRpsStatus      = 1
+	nfseNo             = 2
+	nfseExigivel       = 1
+)
+
+// nfseIssuer represents the business that issues the NFS-e read from the config
+type nfseIssuer struct {
+	cnpj        string
+	im          string
+	cityCode    string
+	serviceCode string
+	rate        float64
+	simples     int
+	series      string
+	dir         string
+}
+
+// InvoiceNfse generates the ABRASF NFS-e xml of the active invoices checked against the bundled layout subset
+// a single invoice generates a GerarNfseEnvio document and a month generates a EnviarLoteRpsEnvio batch
+// invoices whose document does not pass the check are skipped and reported without taking a RPS number.
+// The check is not the official ABRASF schema validation, that is left to the municipal web service
+func (u *Usecase) InvoiceNfse(dtoIn interface{}) error {
+	dtoNfse := dtoIn.(*dto.InvoiceNfse)
+	if err := dtoNfse.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	issuer, err := u.nfseIssuer()
+	if err != nil {
+		return err
+	}
+	invoice, extras, err := dtoNfse.GetInstructions(dtoNfse.GetDomain()[0])
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, invoice, -1, false, extras...)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoInvoicesToIssue, 0, 0)
+	}
+	invoices := *base.(*[]domain.Invoice)
+	slices.SortFunc(invoices, func(a, b domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	unlock := u.lock("rps")
+	defer unlock()
+	next, err := u.nextRps()
+	if err != nil {
+		return err
+	}
+	ret := []*dto.InvoiceNfseOut{}
+	issued := []*domain.Invoice{}
+	generated := []*dto.InvoiceNfseOut{}
+	docs := []pkg.NfseDeclaracaoPrestacao{}
+	for i := range invoices {
+		out := &dto.InvoiceNfseOut{InvoiceID: invoices[i].ID, ClientID: invoices[i].ClientID,
+			Value: fmt.Sprintf("%.2f", invoices[i].Value), Result: pkg.NfseSkippedNoValue}
+		ret = append(ret, out)
+		if invoices[i].Value <= 0 {
+			continue
+		}
+		assigned := invoices[i].RpsNumber == nil
+		if assigned {
+			number := next
+			invoices[i].RpsNumber = &number
+			next++
+		}
+		doc, iss, err := u.nfseDeclaration(&invoices[i], issuer)
+		if err != nil {
+			return err
+		}
+		if _, err := pkg.MarshalNfse(&pkg.NfseGerarEnvio{Rps: *doc}); err != nil {
+			out.Result = fmt.Sprintf(pkg.NfseSkippedInvalid, err.Error())
+			if assigned {
+				invoices[i].RpsNumber = nil
+				next--
+			}
+			continue
+		}
+		out.Rps = strconv.FormatInt(*invoices[i].RpsNumber, 10)
+		out.Iss = fmt.Sprintf("%.2f", iss)
+		out.Result = pkg.NfseGenerated
+		issued = append(issued, &invoices[i])
+		generated = append(generated, out)
+		docs = append(docs, *doc)
+	}
+	if len(docs) == 0 {
+		u.Out = dtoNfse.GetOut().GetDTO(ret)
+		return nil
+	}
+	files, err := u.writeNfse(issuer, dtoNfse.GetMonth(), issued, docs)
+	if err != nil {
+		return err
+	}
+	for i, out := range generated {
+		out.File = files[min(i, len(files)-1)]
+	}
+	if err := u.saveRps(issued); err != nil {
+		return err
+	}
+	u.Out = dtoNfse.GetOut().GetDTO(ret)
+	return nil
+}
+
+// nfseIssuer reads the issuer profile of the NFS-e from the config
+func (u *Usecase) nfseIssuer() (*nfseIssuer, error) {
+	get := func(key string) string {
+		if u.Config == nil {
+			return ""
+		}
+		return strings.TrimSpace(u.Config.Get(key))
+	}
+	issuer := &nfseIssuer{
+		cnpj:        strings.Join(regexp.MustCompile("[0-9]+").FindAllString(get(pkg.ConfigNfseIssuerCNPJ), -1), ""),
+		im:          get(pkg.ConfigNfseIssuerIM),
+		cityCode:    get(pkg.ConfigNfseCityCode),
+		serviceCode: get(pkg.ConfigNfseServiceCode),
+		simples:     u.configInt(pkg.ConfigNfseSimples, pkg.DefaultNfseSimples),
+		series:      get(pkg.ConfigNfseRpsSeries),
+		dir:         get(pkg.ConfigNfseOutputDir),
+	}
+	required := [][2]string{{pkg.ConfigNfseIssuerCNPJ, issuer.cnpj}, {pkg.ConfigNfseCityCode, issuer.cityCode},
+		{pkg.ConfigNfseServiceCode, issuer.serviceCode}, {pkg.ConfigNfseIssRate, get(pkg.ConfigNfseIssRate)}}
+	for _, r := range required {
+		if r[1] == "" {
+			return nil, u.error(pkg.ErrPrefInternal, fmt.Sprintf(pkg.ErrNfseNotConfigured, r[0]), 0, 0)
+		}
+	}
+	rate, err := strconv.ParseFloat(get(pkg.ConfigNfseIssRate), 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return nil, u.error(pkg.ErrPrefInternal, fmt.Sprintf(pkg.ErrInvalidIssRate, get(pkg.ConfigNfseIssRate)), 0, 0)
+	}
+	issuer.rate = rate
+	if issuer.series == "" {
+		issuer.series = pkg.DefaultNfseRpsSeries
+	}
+	if issuer.dir == "" {
+		issuer.dir = pkg.DefaultNfseOutputDir
+	}
+	return issuer, nil
+}
+
+// nextRps returns the next RPS number after the greatest one already assigned to an invoice
+func (u *Usecase) nextRps() (int64, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Invoice{}, -1, false, "rps_number is not null")
+	if err != nil {
+		return 0, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	next := int64(1)
+	if base == nil {
+		return next, nil
+	}
+	for _, i := range *base.(*[]domain.Invoice) {
+		if i.RpsNumber != nil && *i.RpsNumber >= next {
+			next = *i.RpsNumber + 1
+		}
+	}
+	return next, nil
+}
+
+// nfseDeclaration mounts the service declaration of an invoice with its items and the client fiscal data
+// it returns the declaration and the iss value
+func (u *Usecase) nfseDeclaration(invoice *domain.Invoice, issuer *nfseIssuer) (*pkg.NfseDeclaracaoPrestacao, float64, error) {
+	client := &domain.Client{ID: invoice.ClientID}
+	if ok, err := client.Load(u.Repo); err != nil {
+		return nil, 0, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return nil, 0, u.error(pkg.ErrPrefInternal, pkg.ErrClientNotFound, 0, 0)
+	}
+	discrimination, err := u.nfseDiscrimination(invoice)
+	if err != nil {
+		return nil, 0, err
+	}
+	iss := math.Round(invoice.Value*issuer.rate) / 100
+	competence := invoice.Date
+	if len(invoice.ID) >= len(invoiceMonthFormat) {
+		if month, err := time.Parse(invoiceMonthFormat, invoice.ID[:len(invoiceMonthFormat)]); err == nil {
+			competence = month
+		}
+	}
+	ret := &pkg.NfseDeclaracaoPrestacao{Inf: pkg.NfseInfDeclaracao{
+		ID: fmt.Sprintf(nfseRpsIDFormat, *invoice.RpsNumber),
+		Rps: &pkg.NfseRps{Numero: *invoice.RpsNumber, Serie: issuer.series, Tipo: nfseRpsTipo,
+			DataEmissao: time.Now().Format(nfseDateFormat), Status: nfseRpsStatus},
+		Competencia: competence.Format(nfseDateFormat),
+		Servico: pkg.NfseServico{
+			ValorServicos:       fmt.Sprintf("%.2f", invoice.Value),
+			ValorIss:            fmt.Sprintf("%.2f", iss),
+			Aliquota:            fmt.Sprintf("%.2f", issuer.rate),
+			IssRetido:           nfseNo,
+			ItemListaServico:    issuer.serviceCode,
+			Discriminacao:       discrimination,
+			CodigoMunicipio:     issuer.cityCode,
+			ExigibilidadeISS:    nfseExigivel,
+			MunicipioIncidencia: issuer.cityCode,
+		},
+		Prestador:              pkg.NfseIdentificacao{CpfCnpj: pkg.NfseCpfCnpj{Cnpj: issuer.cnpj}, InscricaoMunicipal: issuer.im},
+		Tomador:                u.nfseTaker(client),
+		OptanteSimplesNacional: issuer.simples,
+		IncentivoFiscal:        nfseNo,
+	}}
+	return ret, iss, nil
+}
+
+// nfseDiscrimination describes the service with the items of the invoice
+func (u *Usecase) nfseDiscrimination(invoice *domain.Invoice) (string, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.InvoiceItem{InvoiceID: invoice.ID}, -1, false)
+	if err != nil {
+		return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return invoice.ID, nil
+	}
+	items := *base.(*[]domain.InvoiceItem)
+	slices.SortFunc(items, func(a, b domain.InvoiceItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	lines := []string{}
+	for _, i := range items {
+		lines = append(lines, fmt.Sprintf(pkg.NfseDiscrimination, i.Description, i.Value))
+	}
+	return u.truncate(strings.Join(lines, "; "), nfseDiscrimination), nil
+}
+
+// nfseTaker mounts the taker of the service with the document, the address and the contacts of the client
+func (u *Usecase) nfseTaker(client *domain.Client) *pkg.NfseTomador {
+	digits := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.Join(regexp.MustCompile("[0-9]+").FindAllString(*s, -1), "")
+	}
+	text := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	ret := &pkg.NfseTomador{RazaoSocial: u.truncate(client.Name, nfseRazaoSocial)}
+	switch doc := digits(client.Document); len(doc) {
+	case 11:
+		ret.IdentificacaoTomador = &pkg.NfseIdentificacao{CpfCnpj: pkg.NfseCpfCnpj{Cpf: doc}}
+	case 14:
+		ret.IdentificacaoTomador = &pkg.NfseIdentificacao{CpfCnpj: pkg.NfseCpfCnpj{Cnpj: doc},
+			InscricaoMunicipal: text(client.MunicipalReg)}
+	}
+	if client.Street != nil && *client.Street != "" {
+		ret.Endereco = &pkg.NfseEndereco{Endereco: *client.Street, Numero: text(client.Number),
+			Complemento: text(client.Complement), Bairro: text(client.District), CodigoMunicipio: text(client.CityCode),
+			Uf: text(client.State), Cep: digits(client.ZipCode)}
+	}
+	email := ""
+	if utf8.RuneCountInString(client.Email) <= nfseEmail {
+		email = client.Email
+	}
+	phone := digits(&client.Phone)
+	if len(phone) > nfseTelefone {
+		phone = ""
+	}
+	if email != "" || phone != "" {
+		ret.Contato = &pkg.NfseContato{Telefone: phone, Email: email}
+	}
+	return ret
+}
+
+// writeNfse marshals, validates and writes the NFS-e files returning their paths
+// a batch writes one file for all the invoices and a single invoice writes one file each
+func (u *Usecase) writeNfse(issuer *nfseIssuer, month time.Time, invoices []*domain.Invoice,
+	docs []pkg.NfseDeclaracaoPrestacao) ([]string, error) {
+	if err := os.MkdirAll(issuer.dir, 0755); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if !month.IsZero() {
+		batch := &pkg.NfseLoteEnvio{LoteRps: pkg.NfseLote{
+			ID: fmt.Sprintf(nfseBatchIDFormat, month.Format("200601")), Versao: pkg.NfseVersion,
+			CpfCnpj: pkg.NfseCpfCnpj{Cnpj: issuer.cnpj}, InscricaoMunicipal: issuer.im,
+			QuantidadeRps: len(docs), ListaRps: docs}}
+		batch.LoteRps.NumeroLote, _ = strconv.ParseInt(month.Format("200601"), 10, 64)
+		file := filepath.Join(issuer.dir, fmt.Sprintf(nfseBatchFormat, month.Format(invoiceMonthFormat)))
+		if err := u.writeNfseFile(file, batch); err != nil {
+			return nil, err
+		}
+		return []string{file}, nil
+	}
+	ret := []string{}
+	for i, doc := range docs {
+		file := filepath.Join(issuer.dir, fmt.Sprintf(nfseFileFormat, invoices[i].ID))
+		if err := u.writeNfseFile(file, &pkg.NfseGerarEnvio{Rps: doc}); err != nil {
+			return nil, err
+		}
+		ret = append(ret, file)
+	}
+	return ret, nil
+}
+
+// writeNfseFile marshals and validates a NFS-e document and writes it on a file
+func (u *Usecase) writeNfseFile(file string, doc any) error {
+	body, err := pkg.MarshalNfse(doc)
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	if err := os.WriteFile(file, body, 0644); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return nil
+}
+
+// saveRps saves the RPS numbers assigned to the invoices
+func (u *Usecase) saveRps(invoices []*domain.Invoice) error {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for _, i := range invoices {
+		if err := u.Repo.Save(tx, i); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return nil
+}
+
+// truncate cuts a text to a maximum number of characters
+func (u *Usecase) truncate(text string, size int) string {
+	if utf8.RuneCountInString(text) <= size {
+		return text
+	}
+	return string([]rune(text)[:size])
+}
@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	dunningIDFormat = "%s_d%02d"
+	dunningMaxSent  = 255
+	interestDays    = 30
+)
+
+// overdueInvoice keeps an overdue invoice with its open value, its days late and the dunning step due for it
+type overdueInvoice struct {
+	invoice *domain.Invoice
+	out     *dto.InvoiceOverdueOut
+	open    float64
+	days    int64
+	step    int64
+}
+
+// InvoiceOverdue marks the not paid invoices after their due date as late with the contractual fine and
+// the pro-rata monthly interest of the open value and sends the dunning step due of each invoice to the
+// client contacts. Paid invoices leave the process, so the escalation stops when the payment is registered
+func (u *Usecase) InvoiceOverdue(dtoIn interface{}) error {
+	dtoOverdue := dtoIn.(*dto.InvoiceOverdue)
+	if err := dtoOverdue.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	date, _ := dtoOverdue.GetDate()
+	invoice, extras, err := dtoOverdue.GetInstructions(dtoOverdue.GetDomain()[0])
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, invoice, -1, false, extras...)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoOverdueInvoices, 0, 0)
+	}
+	invoices := *base.(*[]domain.Invoice)
+	slices.SortFunc(invoices, func(a, b domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	overdue, err := u.chargeOverdue(invoices, date)
+	if err != nil {
+		return err
+	}
+	ret := []*dto.InvoiceOverdueOut{}
+	byClient := map[string][]*overdueInvoice{}
+	clients := []string{}
+	for _, o := range overdue {
+		ret = append(ret, o.out)
+		if o.step == 0 {
+			continue
+		}
+		if byClient[o.invoice.ClientID] == nil {
+			clients = append(clients, o.invoice.ClientID)
+		}
+		byClient[o.invoice.ClientID] = append(byClient[o.invoice.ClientID], o)
+	}
+	slices.Sort(clients)
+	for _, clientID := range clients {
+		if err := u.dunClient(clientID, byClient[clientID], date); err != nil {
+			return err
+		}
+	}
+	u.Out = dtoOverdue.GetOut().GetDTO(ret)
+	return nil
+}
+
+// chargeOverdue marks the invoices as late with their fine and interest on the date
+// and finds the dunning step due for each one
+func (u *Usecase) chargeOverdue(invoices []domain.Invoice, date time.Time) ([]*overdueInvoice, error) {
+	paid, err := domain.PaidValues(u.Repo)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	credited, err := domain.CreditedValues(u.Repo)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	finePercent := u.configFloat(pkg.ConfigLateFinePercent, pkg.DefaultLateFinePercent)
+	interestPercent := u.configFloat(pkg.ConfigLateInterestPercent, pkg.DefaultLateInterestPercent)
+	steps := u.dunningSteps()
+	ret := []*overdueInvoice{}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for i := range invoices {
+		invoice := &invoices[i]
+		due := time.Date(invoice.Due.Year(), invoice.Due.Month(), invoice.Due.Day(), 0, 0, 0, 0, date.Location())
+		days := int64(math.Round(date.Sub(due).Hours() / 24))
+		open := math.Max(invoice.Value-credited[invoice.ID]-paid[invoice.ID], 0)
+		fine := math.Round(open*finePercent) / 100
+		interest := math.Round(open*interestPercent*float64(days)/interestDays) / 100
+		invoice.PaymentStatus = pkg.InvoicePaymentStatusLate
+		invoice.Fine = &fine
+		invoice.Interest = &interest
+		if err := u.Repo.Save(tx, invoice); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		last, err := domain.LastDunningStep(u.Repo, invoice.ID)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		o := &overdueInvoice{invoice: invoice, open: open, days: days, out: &dto.InvoiceOverdueOut{
+			InvoiceID: invoice.ID, ClientID: invoice.ClientID, Due: due.Format(pkg.DateFormat),
+			Days: strconv.FormatInt(days, 10), Value: fmt.Sprintf("%.2f", open), Fine: fmt.Sprintf("%.2f", fine),
+			Interest: fmt.Sprintf("%.2f", interest)}}
+		for _, s := range steps {
+			if s <= days && s > last {
+				o.step = s
+			}
+		}
+		ret = append(ret, o)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return ret, nil
+}
+
+// dunClient sends one reminder with the overdue invoices of a client to its notice contacts and records the step of each one
+// just the last step due is sent when the process has missed the former ones
+func (u *Usecase) dunClient(clientID string, overdue []*overdueInvoice, date time.Time) error {
+	if u.Notifier == nil {
+		return u.error(pkg.ErrPrefInternal, pkg.ErrNotifierNotConfigured, 0, 0)
+	}
+	client := &domain.Client{ID: clientID}
+	if ok, err := client.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefInternal, pkg.ErrClientNotFound, 0, 0)
+	}
+	recipients, err := domain.Recipients(u.Repo, client, pkg.RouteNotice)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	sent := []string{}
+	for _, r := range recipients {
+		addresses := r.Addresses()
+		channels := []string{}
+		for channel := range addresses {
+			channels = append(channels, channel)
+		}
+		slices.Sort(channels)
+		for _, channel := range channels {
+			lines := []string{}
+			for _, o := range overdue {
+				lines = append(lines, fmt.Sprintf(pkg.DunningMessage, r.Name, o.invoice.ID, client.Name, o.open,
+					o.days, o.out.Due, *o.invoice.Fine, *o.invoice.Interest))
+				if o.invoice.Pix != nil {
+					lines[len(lines)-1] += fmt.Sprintf(pkg.InvoicePixMessage, *o.invoice.Pix)
+				}
+			}
+			subject := fmt.Sprintf(pkg.DunningSubject, client.Name)
+			if err := u.Notifier.Send(channel, addresses[channel], subject, strings.Join(lines, ". ")); err == nil {
+				sent = append(sent, channel+":"+addresses[channel])
+			}
+		}
+	}
+	status := pkg.DunningStatusFailed
+	if len(sent) > 0 {
+		status = pkg.DunningStatusSent
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for _, o := range overdue {
+		dunning := &domain.Dunning{ID: fmt.Sprintf(dunningIDFormat, o.invoice.ID, o.step), Date: date,
+			InvoiceID: o.invoice.ID, ClientID: clientID, Step: o.step, Days: o.days, Fine: *o.invoice.Fine,
+			Interest: *o.invoice.Interest, Sent: u.truncate(strings.Join(sent, ", "), dunningMaxSent), Status: status}
+		if err := u.Repo.Save(tx, dunning); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		o.out.Dunning = fmt.Sprintf(pkg.DunningResult, o.step, status)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return nil
+}
+
+// dunningSteps returns the days after the due date of the dunning sequence from the config
+func (u *Usecase) dunningSteps() []int64 {
+	value := pkg.DefaultDunningSteps
+	if u.Config != nil && strings.TrimSpace(u.Config.Get(pkg.ConfigDunningSteps)) != "" {
+		value = u.Config.Get(pkg.ConfigDunningSteps)
+	}
+	ret := []int64{}
+	for _, p := range strings.Split(value, ",") {
+		if s, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64); err == nil && s > 0 {
+			ret = append(ret, s)
+		}
+	}
+	slices.Sort(ret)
+	return ret
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	pixQRFileFormat = "%s.png"
+)
+
+// InvoicePix regenerates the PIX payload of the active invoices for their open value and renders their QR code images
+func (u *Usecase) InvoicePix(dtoIn interface{}) error {
+	dtoPix := dtoIn.(*dto.InvoicePix)
+	if err := dtoPix.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	if u.pixConfig(pkg.ConfigPixKey) == "" && u.pixConfig(pkg.ConfigPixURL) == "" {
+		return u.error(pkg.ErrPrefInternal, fmt.Sprintf(pkg.ErrPixNotConfigured, pkg.ConfigPixKey), 0, 0)
+	}
+	invoice, extras, err := dtoPix.GetInstructions(dtoPix.GetDomain()[0])
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, invoice, -1, false, extras...)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoInvoicesToPix, 0, 0)
+	}
+	invoices := *base.(*[]domain.Invoice)
+	slices.SortFunc(invoices, func(a, b domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	dir := u.pixConfig(pkg.ConfigPixQRDir)
+	if dir == "" {
+		dir = pkg.DefaultPixQRDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	paid, err := domain.PaidValues(u.Repo)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	credited, err := domain.CreditedValues(u.Repo)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*dto.InvoicePixOut{}
+	for i := range invoices {
+		open := math.Max(math.Round((invoices[i].Value-credited[invoices[i].ID]-paid[invoices[i].ID])*100)/100, 0)
+		out, err := u.renderPix(&invoices[i], open, dir)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, out)
+	}
+	u.Out = dtoPix.GetOut().GetDTO(ret)
+	return nil
+}
+
+// renderPix saves the PIX payload of one invoice for its open value and writes its QR code image
+func (u *Usecase) renderPix(invoice *domain.Invoice, open float64, dir string) (*dto.InvoicePixOut, error) {
+	out := &dto.InvoicePixOut{InvoiceID: invoice.ID, ClientID: invoice.ClientID, Value: fmt.Sprintf("%.2f", open)}
+	pix, err := u.invoicePix(invoice, open)
+	if err != nil {
+		return nil, err
+	}
+	invoice.Pix = pix
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Save(tx, invoice); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if pix == nil {
+		return out, nil
+	}
+	png, err := pkg.PixQRCode(*pix, u.configInt(pkg.ConfigPixQRSize, pkg.DefaultPixQRSize))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	out.File = filepath.Join(dir, fmt.Sprintf(pixQRFileFormat, invoice.ID))
+	if err := os.WriteFile(out.File, png, 0644); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	out.Pix = *pix
+	return out, nil
+}
+
+// invoicePix returns the PIX BR Code payload of an invoice for the amount to be paid with the merchant profile
+// of the config. It returns nil if pix is not configured or if the invoice has nothing to be paid by the client
+func (u *Usecase) invoicePix(invoice *domain.Invoice, amount float64) (*string, error) {
+	key := u.pixConfig(pkg.ConfigPixKey)
+	url := u.pixConfig(pkg.ConfigPixURL)
+	if (key == "" && url == "") || amount <= 0 || invoice.PaymentStatus == pkg.InvoicePaymentStatusRefund {
+		return nil, nil
+	}
+	pix := &pkg.Pix{Key: key, URL: url, Name: u.pixConfig(pkg.ConfigPixName), City: u.pixConfig(pkg.ConfigPixCity),
+		TxID: invoice.ID, Amount: amount}
+	payload, err := pix.Payload()
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return &payload, nil
+}
+
+// pixConfig returns a pix config value
+func (u *Usecase) pixConfig(key string) string {
+	if u.Config == nil {
+		return ""
+	}
+	return strings.TrimSpace(u.Config.Get(key))
+}
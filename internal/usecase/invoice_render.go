@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	invoiceRenderFileFormat = "%s.%s"
+)
+
+// InvoiceRender renders an invoice to a pdf or html file with the user templates or the bundled ones
+func (u *Usecase) InvoiceRender(dtoIn interface{}) error {
+	dtoRender := dtoIn.(*dto.InvoiceRender)
+	if err := dtoRender.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	invoice := dtoRender.GetDomain()[0].(*domain.Invoice)
+	if ok, err := invoice.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrInvoiceNotFound, 0, 0)
+	}
+	file, err := u.renderInvoice(invoice, dtoRender.GetFormat())
+	if err != nil {
+		return err
+	}
+	out := &dto.InvoiceRenderOut{InvoiceID: invoice.ID, ClientID: invoice.ClientID, Format: dtoRender.GetFormat(), File: file}
+	u.Out = dtoRender.GetOut().GetDTO([]*dto.InvoiceRenderOut{out})
+	return nil
+}
+
+// renderInvoice writes the file of an invoice rendered on a format returning its path
+func (u *Usecase) renderInvoice(invoice *domain.Invoice, format string) (string, error) {
+	view, err := u.invoiceView(invoice)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := u.invoiceTemplate(format)
+	if err != nil {
+		return "", err
+	}
+	data, err := pkg.RenderInvoice(format, tmpl, view)
+	if err != nil {
+		return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	dir := u.renderConfig(pkg.ConfigInvoiceOutputDir)
+	if dir == "" {
+		dir = pkg.DefaultInvoiceOutputDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	file := filepath.Join(dir, fmt.Sprintf(invoiceRenderFileFormat, invoice.ID, format))
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return file, nil
+}
+
+// invoiceView returns the data of an invoice available to the templates
+// with the business of the config, the client, the items and the pix payment
+// the total is the open value of the invoice as on the account statement of the client and the pix charges it
+func (u *Usecase) invoiceView(invoice *domain.Invoice) (*pkg.InvoiceView, error) {
+	client := &domain.Client{ID: invoice.ClientID}
+	if ok, err := client.Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return nil, u.error(pkg.ErrPrefInternal, pkg.ErrClientNotFound, 0, 0)
+	}
+	l, err := u.loadLedger(invoice.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	paid := u.ledgerPaid(l, time.Time{})
+	open := math.Round(u.invoiceOpen(l, invoice, paid, time.Time{})*100) / 100
+	view := &pkg.InvoiceView{ID: invoice.ID, Date: invoice.Date.Format(pkg.DateFormat),
+		Business: u.invoiceBusiness(), Client: u.invoiceParty(client), Total: u.amount(open)}
+	if invoice.Due != nil {
+		view.Due = invoice.Due.Format(pkg.DateFormat)
+	}
+	if charges := u.chargesDue(invoice, paid[invoice.ID], l); charges > 0 {
+		view.Charges = fmt.Sprintf("%.2f", charges)
+	}
+	credits := 0.0
+	for _, n := range l.notes {
+		if n.InvoiceID == invoice.ID {
+			credits += n.Reduction()
+		}
+	}
+	if credits > 0 {
+		view.Credits = u.amount(-credits)
+	}
+	if paid[invoice.ID] != 0 {
+		view.Paid = u.amount(-paid[invoice.ID])
+	}
+	items, err := u.invoiceViewItems(invoice)
+	if err != nil {
+		return nil, err
+	}
+	view.Items = items
+	if logo := u.renderConfig(pkg.ConfigBusinessLogo); logo != "" {
+		if view.Logo, err = os.ReadFile(logo); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		if err := pkg.ValidImage(view.Logo); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	view.Instructions = u.renderConfig(pkg.ConfigInvoiceInstructions)
+	pix, err := u.invoicePix(invoice, open)
+	if err != nil {
+		return nil, err
+	}
+	if pix != nil {
+		view.Pix = *pix
+		if view.QRCode, err = pkg.PixQRCode(*pix, u.configInt(pkg.ConfigPixQRSize, pkg.DefaultPixQRSize)); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if view.Instructions == "" && pix != nil {
+		view.Instructions = pkg.InvoiceInstructionsPix
+	}
+	if view.Instructions == "" {
+		view.Instructions = fmt.Sprintf(pkg.InvoiceInstructions, invoice.ID)
+	}
+	return view, nil
+}
+
+// invoiceViewItems returns the items of an invoice ordered by id
+func (u *Usecase) invoiceViewItems(invoice *domain.Invoice) ([]pkg.InvoiceViewItem, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.InvoiceItem{InvoiceID: invoice.ID}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []pkg.InvoiceViewItem{}
+	if base == nil {
+		return ret, nil
+	}
+	items := *base.(*[]domain.InvoiceItem)
+	slices.SortFunc(items, func(a, b domain.InvoiceItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	for _, i := range items {
+		ret = append(ret, pkg.InvoiceViewItem{Description: i.Description, Value: fmt.Sprintf("%.2f", i.Value)})
+	}
+	return ret, nil
+}
+
+// invoiceBusiness returns the business issuing the invoices from the config
+// falling back to the pix merchant name and to the nfse issuer document
+func (u *Usecase) invoiceBusiness() pkg.InvoiceParty {
+	ret := pkg.InvoiceParty{Name: u.renderConfig(pkg.ConfigBusinessName), Document: u.renderConfig(pkg.ConfigBusinessDocument),
+		Address: u.renderConfig(pkg.ConfigBusinessAddress), Phone: pkg.FormatPhone(u.renderConfig(pkg.ConfigBusinessPhone)),
+		Email: u.renderConfig(pkg.ConfigBusinessEmail)}
+	if ret.Name == "" {
+		ret.Name = u.pixConfig(pkg.ConfigPixName)
+	}
+	if ret.Document == "" {
+		ret.Document = u.renderConfig(pkg.ConfigNfseIssuerCNPJ)
+	}
+	return ret
+}
+
+// invoiceParty returns the client billed with its address in one line
+func (u *Usecase) invoiceParty(client *domain.Client) pkg.InvoiceParty {
+	ret := pkg.InvoiceParty{Name: client.Name, Phone: pkg.FormatPhone(client.Phone), Email: client.Email}
+	if client.Document != nil {
+		ret.Document = *client.Document
+	}
+	parts := []string{}
+	for _, p := range []*string{client.Street, client.Number, client.Complement, client.District, client.City,
+		client.State, client.ZipCode} {
+		if p != nil && strings.TrimSpace(*p) != "" {
+			parts = append(parts, strings.TrimSpace(*p))
+		}
+	}
+	ret.Address = strings.Join(parts, ", ")
+	return ret
+}
+
+// invoiceTemplate returns the template of a format from the template dir of the config
+// or the bundled one if it is not configured or the file does not exist
+func (u *Usecase) invoiceTemplate(format string) (string, error) {
+	if dir := u.renderConfig(pkg.ConfigInvoiceTemplateDir); dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf(pkg.InvoiceTemplateFormat, format)))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	tmpl, err := pkg.DefaultInvoiceTemplate(format)
+	if err != nil {
+		return "", u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	return tmpl, nil
+}
+
+// renderConfig returns a config value of the invoice rendering
+func (u *Usecase) renderConfig(key string) string {
+	if u.Config == nil {
+		return ""
+	}
+	return strings.TrimSpace(u.Config.Get(key))
+}
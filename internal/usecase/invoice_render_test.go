@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+func TestInvoiceViewChargesOpenValueByPix(t *testing.T) {
+	local, _ := time.LoadLocation(pkg.Location)
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, local)
+	str := func(s string) *string { return &s }
+	gross := "gross payload"
+	invoice := &domain.Invoice{ID: "2024_03_ana", Date: date, ClientID: "ana", Value: 100,
+		Status: pkg.InvoiceStatusActive, PaymentStatus: pkg.InvoicePaymentStatusUnder, Pix: &gross}
+	u, repo := newMemoryUsecase(map[string]string{pkg.ConfigPixKey: "pix@clinic.com", pkg.ConfigPixName: "Clinic",
+		pkg.ConfigPixCity: "Sao Paulo"},
+		&domain.Client{ID: "ana", Name: "Ana"}, invoice,
+		&domain.InvoiceItem{ID: "2024_03_ana_001", InvoiceID: "2024_03_ana", Value: 100, Description: "pilates"},
+		&domain.Payment{ID: "pay_ana", Date: date, Value: 40, InvoiceID: str("2024_03_ana"), ClientID: str("ana"),
+			Status: pkg.PaymentStatusMatched},
+	)
+	view, err := u.invoiceView(invoice)
+	if err != nil {
+		t.Fatalf("invoiceView() error = %v", err)
+	}
+	if view.Total != "60.00" || !strings.Contains(view.Pix, "540560.00") || len(view.QRCode) == 0 {
+		t.Errorf("invoiceView() = total %s, pix %q, want the pix of 60.00", view.Total, view.Pix)
+	}
+	repo.Save(nil, &domain.Payment{ID: "pay_ana_2", Date: date, Value: 60, InvoiceID: str("2024_03_ana"),
+		ClientID: str("ana"), Status: pkg.PaymentStatusMatched})
+	if view, err = u.invoiceView(invoice); err != nil {
+		t.Fatalf("invoiceView() paid error = %v", err)
+	}
+	if view.Pix != "" || view.QRCode != nil {
+		t.Errorf("invoiceView() paid = pix %q, want no pix", view.Pix)
+	}
+}
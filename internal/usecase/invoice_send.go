@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// InvoiceSend sends the active and not sent invoices to the contacts of the clients routed by role
+// the payer contacts receive them first, then the liable and the guardian ones, and the client itself if none
+func (u *Usecase) InvoiceSend(dtoIn interface{}) error {
+	dtoSend := dtoIn.(*dto.InvoiceSend)
+	if err := dtoSend.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	if u.Notifier == nil {
+		return u.error(pkg.ErrPrefInternal, pkg.ErrNotifierNotConfigured, 0, 0)
+	}
+	invoice, extras, err := dtoSend.GetInstructions(dtoSend.GetDomain()[0])
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, invoice, -1, false, extras...)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoInvoicesToSend, 0, 0)
+	}
+	invoices := *base.(*[]domain.Invoice)
+	slices.SortFunc(invoices, func(a, b domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	ret := []*dto.InvoiceSendOut{}
+	for i := range invoices {
+		out, err := u.sendInvoice(&invoices[i])
+		if err != nil {
+			return err
+		}
+		ret = append(ret, out...)
+	}
+	u.Out = dtoSend.GetOut().GetDTO(ret)
+	return nil
+}
+
+// sendInvoice sends one invoice to its recipients and marks it as sent if one of them received it
+// the invoice is rendered and attached on the format of the attach config if informed
+func (u *Usecase) sendInvoice(invoice *domain.Invoice) ([]*dto.InvoiceSendOut, error) {
+	client := &domain.Client{ID: invoice.ClientID}
+	if ok, err := client.Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return nil, u.error(pkg.ErrPrefInternal, pkg.ErrClientNotFound, 0, 0)
+	}
+	recipients, err := domain.Recipients(u.Repo, client, pkg.RouteInvoice)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	attachments := []string{}
+	if format := strings.ToLower(strings.TrimSpace(u.renderConfig(pkg.ConfigInvoiceAttach))); format != "" {
+		file, err := u.renderInvoice(invoice, format)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, file)
+	}
+	ret := []*dto.InvoiceSendOut{}
+	sent := false
+	for _, r := range recipients {
+		addresses := r.Addresses()
+		channels := []string{}
+		for channel := range addresses {
+			channels = append(channels, channel)
+		}
+		slices.Sort(channels)
+		for _, channel := range channels {
+			subject := fmt.Sprintf(pkg.InvoiceSubject, invoice.ID)
+			body := fmt.Sprintf(pkg.InvoiceMessage, r.Name, invoice.ID, client.Name, invoice.Value)
+			if invoice.Pix != nil {
+				body += fmt.Sprintf(pkg.InvoicePixMessage, *invoice.Pix)
+			}
+			out := &dto.InvoiceSendOut{InvoiceID: invoice.ID, ClientID: client.ID, Name: r.Name, Role: r.Role,
+				Channel: channel, Address: addresses[channel], Result: pkg.InvoiceSendStatusSent}
+			if err := u.Notifier.Send(channel, addresses[channel], subject, body, attachments...); err != nil {
+				out.Result = err.Error()
+			} else {
+				sent = true
+			}
+			ret = append(ret, out)
+		}
+	}
+	if !sent {
+		return ret, nil
+	}
+	invoice.SendStatus = pkg.InvoiceSendStatusSent
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Save(tx, invoice); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return ret, nil
+}
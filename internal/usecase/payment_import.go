@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+const (
+	paymentIDFormat = "%s_%s"
+	paymentMaxID    = 150
+	paymentMaxRef   = 150
+	paymentMaxName  = 100
+	paymentCents    = 0.005
+)
+
+// paymentMatcher keeps the open invoices, their paid values and their clients while a bank file is imported
+type paymentMatcher struct {
+	invoices []*domain.Invoice
+	paid     map[string]float64
+	credited map[string]float64
+	clients  map[string]*domain.Client
+	window   int
+	weights  []float64
+}
+
+// PaymentImport imports the credits of an OFX statement or a CNAB return file as payments
+// matching them to the open invoices by txid or reference, then by amount, payer document and date proximity
+func (u *Usecase) PaymentImport(dtoIn interface{}) error {
+	dtoImport := dtoIn.(*dto.PaymentImport)
+	if err := dtoImport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	format, credits, err := dtoImport.GetCredits()
+	if err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	if len(credits) == 0 {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoCreditsOnFile, 0, 0)
+	}
+	unlock := u.lock("payment")
+	defer unlock()
+	matcher, err := u.paymentMatcher()
+	if err != nil {
+		return err
+	}
+	ret := []*dto.PaymentImportOut{}
+	for _, c := range credits {
+		out, err := u.importCredit(format, c, matcher)
+		if err != nil {
+			return err
+		}
+		ret = append(ret, out)
+	}
+	u.Out = dtoImport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// paymentMatcher loads the active invoices still open to payments with their paid and credited values
+func (u *Usecase) paymentMatcher() (*paymentMatcher, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	open := []string{pkg.InvoicePaymentStatusOpen, pkg.InvoicePaymentStatusLate, pkg.InvoicePaymentStatusUnder}
+	base, _, err := u.Repo.Find(tx, &domain.Invoice{Status: pkg.InvoiceStatusActive}, -1, false, "value > 0",
+		fmt.Sprintf("payment_status in ('%s')", strings.Join(open, "', '")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	matcher := &paymentMatcher{invoices: []*domain.Invoice{}, clients: map[string]*domain.Client{},
+		window: u.configInt(pkg.ConfigPaymentMatchWindow, pkg.DefaultPaymentMatchWindow)}
+	if base != nil {
+		for _, i := range *base.(*[]domain.Invoice) {
+			matcher.invoices = append(matcher.invoices, &i)
+		}
+	}
+	slices.SortFunc(matcher.invoices, func(a, b *domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	if matcher.paid, err = domain.PaidValues(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if matcher.credited, err = domain.CreditedValues(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if u.Config != nil {
+		matcher.weights = u.parseWeights(u.Config.Get(pkg.ConfigPaymentMatchWeights))
+	}
+	if matcher.weights == nil {
+		matcher.weights = u.parseWeights(pkg.DefaultPaymentMatchWeights)
+	}
+	return matcher, nil
+}
+
+// importCredit records one credit as a payment and updates the payment status of the invoice matched
+func (u *Usecase) importCredit(format string, c *pkg.BankCredit, m *paymentMatcher) (*dto.PaymentImportOut, error) {
+	id := strings.ToLower(regexp.MustCompile(`[^A-Za-z0-9_-]`).ReplaceAllString(fmt.Sprintf(paymentIDFormat, format, c.ID), ""))
+	payment := &domain.Payment{ID: u.truncate(id, paymentMaxID), Date: c.Date, Value: c.Value,
+		Reference: u.truncate(c.Reference, paymentMaxRef), Name: u.truncate(c.Name, paymentMaxName),
+		Status: pkg.PaymentStatusUnmatched}
+	out := &dto.PaymentImportOut{Line: strconv.Itoa(c.Line), PaymentID: payment.ID, Date: c.Date.Format(pkg.DateFormat),
+		Value: fmt.Sprintf("%.2f", c.Value)}
+	if ok, err := (&domain.Payment{ID: payment.ID}).Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if ok {
+		out.Result = pkg.PaymentDuplicated
+		return out, nil
+	}
+	if c.Document != "" {
+		payment.Document = &c.Document
+	}
+	invoice, candidates, err := u.matchCredit(c, m)
+	if err != nil {
+		return nil, err
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	switch {
+	case invoice != nil:
+		payment.Status = pkg.PaymentStatusMatched
+		payment.InvoiceID = &invoice.ID
+		payment.ClientID = &invoice.ClientID
+		m.paid[invoice.ID] += c.Value
+		invoice.PaymentStatus = u.paidStatus(invoice.Value-m.credited[invoice.ID], invoice.Charges(), m.paid[invoice.ID])
+		if err := u.Repo.Save(tx, invoice); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		out.InvoiceID = invoice.ID
+		out.ClientID = invoice.ClientID
+	case len(candidates) > 0:
+		payment.Status = pkg.PaymentStatusAmbiguous
+		ids := []string{}
+		for _, i := range candidates {
+			ids = append(ids, i.ID)
+		}
+		out.Candidates = strings.Join(ids, ", ")
+	}
+	if err := u.Repo.Add(tx, payment); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	out.Result = payment.Status
+	return out, nil
+}
+
+// matchCredit returns the invoice matched by a credit or the candidates when it is ambiguous
+// the txid or the id of the invoice on the reference wins, otherwise the invoices with the open value of the credit
+// with or without the late charges
+// are filtered by the payer document and the date window and scored by date, payer name and reference distances
+func (u *Usecase) matchCredit(c *pkg.BankCredit, m *paymentMatcher) (*domain.Invoice, []*domain.Invoice, error) {
+	reference := strings.ToLower(regexp.MustCompile(`[^A-Za-z0-9]`).ReplaceAllString(c.Reference, ""))
+	byReference := []*domain.Invoice{}
+	size := 0
+	for _, i := range m.invoices {
+		txid := strings.ToLower(pkg.PixTxID(i.ID))
+		if txid == "" || !strings.Contains(reference, txid) || len(txid) < size {
+			continue
+		}
+		if len(txid) > size {
+			byReference, size = []*domain.Invoice{}, len(txid)
+		}
+		byReference = append(byReference, i)
+	}
+	if len(byReference) == 1 {
+		return byReference[0], nil, nil
+	}
+	if len(byReference) > 1 {
+		return nil, byReference, nil
+	}
+	byValue := []*domain.Invoice{}
+	byDocument := []*domain.Invoice{}
+	for _, i := range m.invoices {
+		open := i.Value - m.credited[i.ID] - m.paid[i.ID]
+		if (math.Abs(open-c.Value) >= paymentCents && math.Abs(open+i.Charges()-c.Value) >= paymentCents) ||
+			u.days(c, i) > int64(m.window) {
+			continue
+		}
+		byValue = append(byValue, i)
+		client, err := u.paymentClient(i.ClientID, m)
+		if err != nil {
+			return nil, nil, err
+		}
+		if c.Document != "" && client.Document != nil &&
+			regexp.MustCompile(`\D`).ReplaceAllString(*client.Document, "") == c.Document {
+			byDocument = append(byDocument, i)
+		}
+	}
+	if len(byDocument) > 0 {
+		byValue = byDocument
+	}
+	switch len(byValue) {
+	case 0:
+		return nil, nil, nil
+	case 1:
+		return byValue[0], nil, nil
+	}
+	return u.scoreCredit(c, byValue, reference, m)
+}
+
+// scoreCredit scores the candidates of a credit by weighted distance returning the best one or the tied ones
+func (u *Usecase) scoreCredit(c *pkg.BankCredit, candidates []*domain.Invoice, reference string, m *paymentMatcher) (*domain.Invoice, []*domain.Invoice, error) {
+	cmd := pkg.NewCommands()
+	scores := map[string]float64{}
+	for _, i := range candidates {
+		client, err := u.paymentClient(i.ClientID, m)
+		if err != nil {
+			return nil, nil, err
+		}
+		x := []interface{}{u.days(c, i), strings.ToLower(c.Name), reference}
+		y := []interface{}{int64(0), strings.ToLower(client.Name), strings.ToLower(pkg.PixTxID(i.ID))}
+		if c.Name == "" {
+			y[1] = ""
+		}
+		score, err := cmd.WeightedDistance(x, y, m.weights)
+		if err != nil {
+			return nil, nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		scores[i.ID] = score
+	}
+	slices.SortStableFunc(candidates, func(a, b *domain.Invoice) int {
+		return cmp.Compare(scores[a.ID], scores[b.ID])
+	})
+	if scores[candidates[0].ID] < scores[candidates[1].ID] {
+		return candidates[0], nil, nil
+	}
+	tied := []*domain.Invoice{}
+	for _, i := range candidates {
+		if scores[i.ID] == scores[candidates[0].ID] {
+			tied = append(tied, i)
+		}
+	}
+	return nil, tied, nil
+}
+
+// paymentClient returns the client of an invoice loading it once while importing
+func (u *Usecase) paymentClient(id string, m *paymentMatcher) (*domain.Client, error) {
+	if client, ok := m.clients[id]; ok {
+		return client, nil
+	}
+	client := &domain.Client{ID: id}
+	if _, err := client.Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	m.clients[id] = client
+	return client, nil
+}
+
+// days returns the number of days between the credit and the invoice dates
+func (u *Usecase) days(c *pkg.BankCredit, i *domain.Invoice) int64 {
+	return int64(math.Round(math.Abs(c.Date.Sub(i.Date).Hours()) / 24))
+}
+
+// paidStatus returns the payment status of an invoice by its value, its late charges and the value paid
+// the invoice is paid from its value up to its value with the charges
+func (u *Usecase) paidStatus(value, charges, paid float64) string {
+	switch {
+	case paid > value-paymentCents && paid < value+charges+paymentCents:
+		return pkg.InvoicePaymentStatusPaid
+	case paid > value:
+		return pkg.InvoicePaymentStatusOver
+	}
+	return pkg.InvoicePaymentStatusUnder
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"slices"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ReportAging reports the receivables of the clients on a date by the days overdue
+// with the open value of the invoices net of the payments and credit notes as on their statements
+func (u *Usecase) ReportAging(dtoIn interface{}) error {
+	dtoReport := dtoIn.(*dto.ReportAging)
+	if err := dtoReport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	at := dtoReport.GetAt()
+	clients, err := u.receivableClients()
+	if err != nil {
+		return err
+	}
+	ret := []*dto.ReportAgingOut{}
+	total := make([]float64, 4)
+	for _, id := range clients {
+		buckets, err := u.clientAging(id, at)
+		if err != nil {
+			return err
+		}
+		if buckets[0]+buckets[1]+buckets[2]+buckets[3] < paymentCents {
+			continue
+		}
+		client := &domain.Client{ID: id}
+		if _, err := client.Load(u.Repo); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		ret = append(ret, u.agingOut(id, client.Name, buckets))
+		for idx := range total {
+			total[idx] += buckets[idx]
+		}
+	}
+	ret = append(ret, u.agingOut(pkg.ReportTotal, "", total))
+	u.Output = dtoReport.GetFormat()
+	u.Out = dtoReport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// receivableClients returns the ids of the clients with active invoices to be paid ordered
+func (u *Usecase) receivableClients() ([]string, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Invoice{Status: pkg.InvoiceStatusActive}, -1, false, "value > 0")
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []string{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, i := range *base.(*[]domain.Invoice) {
+		if !slices.Contains(ret, i.ClientID) {
+			ret = append(ret, i.ClientID)
+		}
+	}
+	slices.Sort(ret)
+	return ret, nil
+}
+
+// clientAging returns the open value of the invoices of a client on a date
+// up to 30 days overdue or not due yet, from 31 to 60, from 61 to 90 and over 90 days overdue
+func (u *Usecase) clientAging(clientID string, at time.Time) ([]float64, error) {
+	l, err := u.loadLedger(clientID)
+	if err != nil {
+		return nil, err
+	}
+	buckets := u.agingBuckets(l, at)
+	return []float64{buckets[0] + buckets[1], buckets[2], buckets[3], buckets[4]}, nil
+}
+
+// agingOut returns the line of the aging report of a client
+func (u *Usecase) agingOut(id, name string, buckets []float64) *dto.ReportAgingOut {
+	return &dto.ReportAgingOut{ClientID: id, Name: name, Days30: u.amount(buckets[0]), Days60: u.amount(buckets[1]),
+		Days90: u.amount(buckets[2]), Over90: u.amount(buckets[3]),
+		Total: u.amount(buckets[0] + buckets[1] + buckets[2] + buckets[3])}
+}
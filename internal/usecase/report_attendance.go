@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// attendanceLine accumulates the appointments of one group of the attendance report by their status
+type attendanceLine struct {
+	done     int
+	missed   int
+	notice   int
+	noNotice int
+}
+
+// ReportAttendance reports the attendance of the agendas and of the sessions without agenda on a period
+// by client, service or professional. Saved appointments were canceled with notice and keep a make-up credit,
+// canceled ones were canceled without notice and missed ones are no-shows. The trend is the change of the
+// attendance rate over the previous period with the same months and the groups with the no-show rate
+// over the configured threshold are flagged
+func (u *Usecase) ReportAttendance(dtoIn interface{}) error {
+	dtoReport := dtoIn.(*dto.ReportAttendance)
+	if err := dtoReport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, to, _ := dtoReport.GetRange()
+	keys := u.reportKeys(dtoReport.GetBy())
+	current, err := u.attendanceLines(keys, from, to)
+	if err != nil {
+		return err
+	}
+	from, to = dtoReport.GetPrevious()
+	previous, err := u.attendanceLines(keys, from, to)
+	if err != nil {
+		return err
+	}
+	threshold := u.configFloat(pkg.ConfigNoShowThreshold, pkg.DefaultNoShowThreshold)
+	ret := []*dto.ReportAttendanceOut{}
+	total, totalPrevious := &attendanceLine{}, &attendanceLine{}
+	for _, l := range previous {
+		totalPrevious.add(l)
+	}
+	for _, key := range u.attendanceKeys(current) {
+		l := current[key]
+		total.add(l)
+		name := ""
+		if keys.by == pkg.ReportByClient {
+			client := &domain.Client{ID: key}
+			if _, err := client.Load(u.Repo); err != nil {
+				return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+			}
+			name = client.Name
+		}
+		ret = append(ret, u.attendanceOut(keys.by, key, name, l, previous[key], threshold))
+	}
+	ret = append(ret, u.attendanceOut(keys.by, pkg.ReportTotal, "", total, totalPrevious, threshold))
+	u.Output = dtoReport.GetFormat()
+	u.Out = dtoReport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// attendanceLines returns the appointments of a period accumulated by group
+// the sessions linked to an agenda are already counted by their agenda
+func (u *Usecase) attendanceLines(keys *reportKeys, from, to time.Time) (map[string]*attendanceLine, error) {
+	statuses := fmt.Sprintf("status in ('%s', '%s', '%s', '%s')", pkg.AgendaStatusDone, pkg.AgendaStatusMissed,
+		pkg.AgendaStatusSaved, pkg.AgendaStatusCanceled)
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	agendas, _, err := u.Repo.Find(tx, &domain.Agenda{}, -1, false, statuses,
+		fmt.Sprintf("start >= '%s'", from.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("start <= '%s'", to.Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	sessions, _, err := u.Repo.Find(tx, &domain.Session{}, -1, false, statuses, "(agenda_id is null or agenda_id = '')",
+		fmt.Sprintf("at >= '%s'", from.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("at <= '%s'", to.Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := map[string]*attendanceLine{}
+	line := func(key string) *attendanceLine {
+		if ret[key] == nil {
+			ret[key] = &attendanceLine{}
+		}
+		return ret[key]
+	}
+	if agendas != nil {
+		for _, a := range *agendas.(*[]domain.Agenda) {
+			key, err := u.reportAgendaKey(keys, &a)
+			if err != nil {
+				return nil, err
+			}
+			line(key).count(a.Status)
+		}
+	}
+	if sessions != nil {
+		for _, s := range *sessions.(*[]domain.Session) {
+			key := pkg.ReportUnassigned
+			switch keys.by {
+			case pkg.ReportByClient:
+				key = s.ClientID
+			case pkg.ReportByService:
+				key = s.ServiceID
+			}
+			line(key).count(s.Status)
+		}
+	}
+	return ret, nil
+}
+
+// attendanceKeys returns the groups of the report ordered with the unassigned one at the end
+func (u *Usecase) attendanceKeys(lines map[string]*attendanceLine) []string {
+	keys := map[string]*reportLine{}
+	for key := range lines {
+		keys[key] = nil
+	}
+	return u.reportSortedKeys(keys)
+}
+
+// attendanceOut returns the line of the attendance report of a group
+func (u *Usecase) attendanceOut(by, key, name string, l, previous *attendanceLine,
+	threshold float64) *dto.ReportAttendanceOut {
+	out := &dto.ReportAttendanceOut{By: by, Key: key, Name: name, Scheduled: strconv.Itoa(l.scheduled()),
+		Done: strconv.Itoa(l.done), Missed: strconv.Itoa(l.missed), Notice: strconv.Itoa(l.notice),
+		NoNotice: strconv.Itoa(l.noNotice)}
+	if l.scheduled() == 0 {
+		return out
+	}
+	attendance := float64(l.done) / float64(l.scheduled()) * 100
+	noShow := float64(l.missed) / float64(l.scheduled()) * 100
+	out.Attendance = fmt.Sprintf("%.2f%%", math.Round(attendance*100)/100)
+	out.NoShow = fmt.Sprintf("%.2f%%", math.Round(noShow*100)/100)
+	if previous != nil && previous.scheduled() > 0 {
+		trend := math.Round((attendance-float64(previous.done)/float64(previous.scheduled())*100)*100) / 100
+		if trend == 0 {
+			trend = 0
+		}
+		out.Trend = fmt.Sprintf("%+.2f", trend)
+	}
+	if noShow > threshold {
+		out.Flag = pkg.ReportFlagNoShow
+	}
+	return out
+}
+
+// count counts an appointment on the line by its status
+func (l *attendanceLine) count(status string) {
+	switch status {
+	case pkg.AgendaStatusDone:
+		l.done++
+	case pkg.AgendaStatusMissed:
+		l.missed++
+	case pkg.AgendaStatusSaved:
+		l.notice++
+	case pkg.AgendaStatusCanceled:
+		l.noNotice++
+	}
+}
+
+// add adds the appointments of other line
+func (l *attendanceLine) add(other *attendanceLine) {
+	l.done += other.done
+	l.missed += other.missed
+	l.notice += other.notice
+	l.noNotice += other.noNotice
+}
+
+// scheduled returns the appointments of the line
+func (l *attendanceLine) scheduled() int {
+	return l.done + l.missed + l.notice + l.noNotice
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// ReportForecast reports the revenue forecast of the prices of the future openned agendas by month
+// and by service, package or professional. Agendas without price are counted but billed by their package
+func (u *Usecase) ReportForecast(dtoIn interface{}) error {
+	dtoReport := dtoIn.(*dto.ReportForecast)
+	if err := dtoReport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, to, _ := dtoReport.GetRange()
+	agendas, err := u.forecastAgendas(nil, from, to)
+	if err != nil {
+		return err
+	}
+	keys := u.reportKeys(dtoReport.GetBy())
+	lines := map[string]map[string]*reportLine{}
+	for _, a := range agendas {
+		key, err := u.reportAgendaKey(keys, a)
+		if err != nil {
+			return err
+		}
+		month := a.Start.Format(pkg.MonthFormat)
+		if lines[month] == nil {
+			lines[month] = map[string]*reportLine{}
+		}
+		if lines[month][key] == nil {
+			lines[month][key] = &reportLine{}
+		}
+		lines[month][key].count++
+		if a.Price != nil {
+			lines[month][key].priced++
+			lines[month][key].billed += *a.Price
+		}
+	}
+	ret := []*dto.ReportForecastOut{}
+	for _, month := range u.reportMonths(from, to) {
+		total := &reportLine{}
+		for _, l := range lines[month] {
+			total.count += l.count
+			total.priced += l.priced
+			total.billed += l.billed
+		}
+		for _, key := range u.reportSortedKeys(lines[month]) {
+			l := lines[month][key]
+			ret = append(ret, &dto.ReportForecastOut{Month: month, By: keys.by, Key: key, Agendas: strconv.Itoa(l.count),
+				Priced: strconv.Itoa(l.priced), Forecast: u.amount(l.billed), Share: u.share(l.billed, total.billed)})
+		}
+		ret = append(ret, &dto.ReportForecastOut{Month: month, By: keys.by, Key: pkg.ReportTotal,
+			Agendas: strconv.Itoa(total.count), Priced: strconv.Itoa(total.priced), Forecast: u.amount(total.billed),
+			Share: u.share(total.billed, total.billed)})
+	}
+	u.Output = dtoReport.GetFormat()
+	u.Out = dtoReport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// forecastAgendas returns the openned agendas starting from now on a period, of a contract if informed
+func (u *Usecase) forecastAgendas(contractID *string, from, to time.Time) ([]*domain.Agenda, error) {
+	local, _ := time.LoadLocation(pkg.Location)
+	if now := time.Now().In(local); from.Before(now) {
+		from = now
+	}
+	if from.After(to) {
+		return []*domain.Agenda{}, nil
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Agenda{ContractID: contractID, Status: pkg.AgendaStatusOpenned}, -1, false,
+		fmt.Sprintf("start >= '%s'", from.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("start <= '%s'", to.Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Agenda{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, a := range *base.(*[]domain.Agenda) {
+		ret = append(ret, &a)
+	}
+	return ret, nil
+}
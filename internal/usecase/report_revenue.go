@@ -0,0 +1,343 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// reportKeys keeps the agendas and contracts read to group the lines of the reports
+type reportKeys struct {
+	by        string
+	agendas   map[string]*domain.Agenda
+	contracts map[string]*domain.Contract
+}
+
+// reportLine accumulates the values of one group of a month of a report
+type reportLine struct {
+	count  int
+	priced int
+	billed float64
+	credit float64
+}
+
+// ReportRevenue reports the monthly revenue of the invoice items by service, package or professional
+// the credit notes reduce the revenue of the month they were issued by their full value, so the items
+// that apply their carried credits to other invoices are not counted again
+func (u *Usecase) ReportRevenue(dtoIn interface{}) error {
+	dtoReport := dtoIn.(*dto.ReportRevenue)
+	if err := dtoReport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	from, to, _ := dtoReport.GetRange()
+	keys := u.reportKeys(dtoReport.GetBy())
+	lines := map[string]map[string]*reportLine{}
+	line := func(date time.Time, key string) *reportLine {
+		month := date.Format(pkg.MonthFormat)
+		if lines[month] == nil {
+			lines[month] = map[string]*reportLine{}
+		}
+		if lines[month][key] == nil {
+			lines[month][key] = &reportLine{}
+		}
+		return lines[month][key]
+	}
+	notes, err := u.reportNotes()
+	if err != nil {
+		return err
+	}
+	applied := map[string]bool{}
+	for _, n := range notes {
+		if n.AppliedID != nil && *n.AppliedID != n.InvoiceID {
+			applied[*n.AppliedID+"|"+fmt.Sprintf(pkg.CreditNoteDescription, n.ID)] = true
+		}
+	}
+	invoices, err := u.reportInvoices(from, to)
+	if err != nil {
+		return err
+	}
+	for _, i := range invoices {
+		items, err := u.reportItems(i.ID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if applied[i.ID+"|"+item.Description] {
+				continue
+			}
+			key, err := u.reportItemKey(keys, item)
+			if err != nil {
+				return err
+			}
+			l := line(i.Date, key)
+			l.count++
+			l.billed += item.Value
+		}
+	}
+	for _, n := range notes {
+		if n.Date.Before(from) || n.Date.After(to) || n.Value == 0 {
+			continue
+		}
+		shares, err := u.creditShares(keys, n)
+		if err != nil {
+			return err
+		}
+		for key, value := range shares {
+			line(n.Date, key).credit += value
+		}
+	}
+	ret := []*dto.ReportRevenueOut{}
+	for _, month := range u.reportMonths(from, to) {
+		total := &reportLine{}
+		for _, l := range lines[month] {
+			total.count += l.count
+			total.billed += l.billed
+			total.credit += l.credit
+		}
+		for _, key := range u.reportSortedKeys(lines[month]) {
+			l := lines[month][key]
+			ret = append(ret, &dto.ReportRevenueOut{Month: month, By: keys.by, Key: key, Items: strconv.Itoa(l.count),
+				Billed: u.amount(l.billed), Credited: u.amount(l.credit), Revenue: u.amount(l.billed - l.credit),
+				Share: u.share(l.billed-l.credit, total.billed-total.credit)})
+		}
+		ret = append(ret, &dto.ReportRevenueOut{Month: month, By: keys.by, Key: pkg.ReportTotal,
+			Items: strconv.Itoa(total.count), Billed: u.amount(total.billed), Credited: u.amount(total.credit),
+			Revenue: u.amount(total.billed - total.credit), Share: u.share(total.billed-total.credit, total.billed-total.credit)})
+	}
+	u.Output = dtoReport.GetFormat()
+	u.Out = dtoReport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// reportInvoices returns the invoices issued on a period ordered by id
+func (u *Usecase) reportInvoices(from, to time.Time) ([]*domain.Invoice, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Invoice{}, -1, false,
+		fmt.Sprintf("date >= '%s'", from.Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("date <= '%s'", to.Format("2006-01-02 15:04:05")))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Invoice{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, i := range *base.(*[]domain.Invoice) {
+		ret = append(ret, &i)
+	}
+	slices.SortFunc(ret, func(a, b *domain.Invoice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ret, nil
+}
+
+// reportItems returns the items of an invoice ordered by id
+func (u *Usecase) reportItems(invoiceID string) ([]*domain.InvoiceItem, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.InvoiceItem{InvoiceID: invoiceID}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.InvoiceItem{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, i := range *base.(*[]domain.InvoiceItem) {
+		ret = append(ret, &i)
+	}
+	slices.SortFunc(ret, func(a, b *domain.InvoiceItem) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ret, nil
+}
+
+// reportNotes returns all the credit notes
+func (u *Usecase) reportNotes() ([]*domain.CreditNote, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.CreditNote{}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.CreditNote{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, n := range *base.(*[]domain.CreditNote) {
+		ret = append(ret, &n)
+	}
+	return ret, nil
+}
+
+// creditShares returns the value of a credit note that reduces the revenue by group
+// a note of an item reduces its group and a note of the invoice reduces the groups of its items pro rata
+// the full value of the note is shared, including the part carried to other invoices, so the item that applies
+// the carried credit is not entered again. The rounding remainder goes to the last item
+func (u *Usecase) creditShares(keys *reportKeys, n *domain.CreditNote) (map[string]float64, error) {
+	items, err := u.reportItems(n.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]float64{}
+	total := 0.0
+	last := -1
+	for idx, item := range items {
+		if n.ItemID != nil && *n.ItemID == item.ID {
+			key, err := u.reportItemKey(keys, item)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]float64{key: n.Value}, nil
+		}
+		if item.Value > 0 {
+			total += item.Value
+			last = idx
+		}
+	}
+	if n.ItemID != nil || total <= 0 {
+		return map[string]float64{pkg.ReportUnassigned: n.Value}, nil
+	}
+	shared := 0.0
+	for idx, item := range items {
+		if item.Value <= 0 {
+			continue
+		}
+		key, err := u.reportItemKey(keys, item)
+		if err != nil {
+			return nil, err
+		}
+		share := math.Round(n.Value*item.Value/total*100) / 100
+		if idx == last {
+			share = math.Round((n.Value-shared)*100) / 100
+		}
+		shared += share
+		ret[key] += share
+	}
+	return ret, nil
+}
+
+// reportKeys returns the keeper of the entities read to group the reports
+func (u *Usecase) reportKeys(by string) *reportKeys {
+	return &reportKeys{by: by, agendas: map[string]*domain.Agenda{}, contracts: map[string]*domain.Contract{}}
+}
+
+// reportItemKey returns the group of an invoice item by its agenda
+// the items of package prices have no agenda and are grouped by their package or by the professional of their contract
+func (u *Usecase) reportItemKey(keys *reportKeys, item *domain.InvoiceItem) (string, error) {
+	if item.AgendaID == nil {
+		switch {
+		case keys.by == pkg.ReportByPackage && item.PackageID != nil:
+			return *item.PackageID, nil
+		case keys.by == pkg.ReportByProfessional && item.ContractID != nil:
+			contract, err := u.reportContract(keys, *item.ContractID)
+			if err != nil {
+				return "", err
+			}
+			if contract != nil && contract.Professional != nil {
+				return *contract.Professional, nil
+			}
+		}
+		return pkg.ReportUnassigned, nil
+	}
+	agenda, ok := keys.agendas[*item.AgendaID]
+	if !ok {
+		agenda = &domain.Agenda{ID: *item.AgendaID}
+		if exists, err := agenda.Load(u.Repo); err != nil {
+			return "", u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		} else if !exists {
+			agenda = nil
+		}
+		keys.agendas[*item.AgendaID] = agenda
+	}
+	if agenda == nil {
+		return pkg.ReportUnassigned, nil
+	}
+	return u.reportAgendaKey(keys, agenda)
+}
+
+// reportAgendaKey returns the group of an agenda by its client, its service, the package of its contract
+// or its professional
+func (u *Usecase) reportAgendaKey(keys *reportKeys, agenda *domain.Agenda) (string, error) {
+	switch keys.by {
+	case pkg.ReportByClient:
+		return agenda.ClientID, nil
+	case pkg.ReportByProfessional:
+		if agenda.Professional != nil {
+			return *agenda.Professional, nil
+		}
+	case pkg.ReportByPackage:
+		if agenda.ContractID == nil {
+			break
+		}
+		contract, err := u.reportContract(keys, *agenda.ContractID)
+		if err != nil {
+			return "", err
+		}
+		if contract != nil {
+			return contract.PackageID, nil
+		}
+	default:
+		return agenda.ServiceID, nil
+	}
+	return pkg.ReportUnassigned, nil
+}
+
+// reportContract returns a contract read to group the reports or nil when it does not exist
+func (u *Usecase) reportContract(keys *reportKeys, id string) (*domain.Contract, error) {
+	contract, ok := keys.contracts[id]
+	if ok {
+		return contract, nil
+	}
+	contract = &domain.Contract{ID: id}
+	if exists, err := contract.Load(u.Repo); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !exists {
+		contract = nil
+	}
+	keys.contracts[id] = contract
+	return contract, nil
+}
+
+// reportMonths returns the months of a period on the month format
+func (u *Usecase) reportMonths(from, to time.Time) []string {
+	ret := []string{}
+	for m := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !m.After(to); m = m.AddDate(0, 1, 0) {
+		ret = append(ret, m.Format(pkg.MonthFormat))
+	}
+	return ret
+}
+
+// reportSortedKeys returns the groups of a month ordered with the unassigned one at the end
+func (u *Usecase) reportSortedKeys(lines map[string]*reportLine) []string {
+	ret := []string{}
+	for key := range lines {
+		ret = append(ret, key)
+	}
+	slices.SortFunc(ret, func(a, b string) int {
+		if (a == pkg.ReportUnassigned) != (b == pkg.ReportUnassigned) {
+			if a == pkg.ReportUnassigned {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a, b)
+	})
+	return ret
+}
+
+// share formats the percentage of a value over a total
+func (u *Usecase) share(value, total float64) string {
+	if math.Abs(total) < paymentCents {
+		return ""
+	}
+	return fmt.Sprintf("%.2f%%", math.Round(value/total*10000)/100)
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// sponsorExposure accumulates the values of the contracts of a sponsor
+type sponsorExposure struct {
+	contracts int
+	clients   []string
+	packages  float64
+	forecast  float64
+	open      float64
+	overdue   float64
+}
+
+// ReportSponsor reports the exposure of the sponsors on a date by their contracts in force
+// with the prices of their packages, the forecast of the openned agendas of the next months
+// and the open and overdue receivables of their sponsored clients
+func (u *Usecase) ReportSponsor(dtoIn interface{}) error {
+	dtoReport := dtoIn.(*dto.ReportSponsor)
+	if err := dtoReport.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	at := dtoReport.GetAt()
+	contracts, err := u.sponsoredContracts(at)
+	if err != nil {
+		return err
+	}
+	exposures := map[string]*sponsorExposure{}
+	packages := map[string]*domain.Package{}
+	aging := map[string][]float64{}
+	for _, c := range contracts {
+		e := exposures[*c.SponsorID]
+		if e == nil {
+			e = &sponsorExposure{}
+			exposures[*c.SponsorID] = e
+		}
+		e.contracts++
+		if p, err := u.sponsorPackage(packages, c.PackageID); err != nil {
+			return err
+		} else if p != nil && p.Price != nil {
+			e.packages += *p.Price
+		}
+		agendas, err := u.forecastAgendas(&c.ID, at, at.AddDate(0, pkg.DefaultForecastMonths, 0))
+		if err != nil {
+			return err
+		}
+		for _, a := range agendas {
+			if a.Price != nil {
+				e.forecast += *a.Price
+			}
+		}
+		if slices.Contains(e.clients, c.ClientID) {
+			continue
+		}
+		e.clients = append(e.clients, c.ClientID)
+		if _, ok := aging[c.ClientID]; !ok {
+			l, err := u.loadLedger(c.ClientID)
+			if err != nil {
+				return err
+			}
+			aging[c.ClientID] = u.agingBuckets(l, at)
+		}
+		for idx, value := range aging[c.ClientID] {
+			e.open += value
+			if idx > 0 {
+				e.overdue += value
+			}
+		}
+	}
+	ret := []*dto.ReportSponsorOut{}
+	total := &sponsorExposure{}
+	for _, id := range u.sponsorIDs(exposures) {
+		e := exposures[id]
+		sponsor := &domain.Client{ID: id}
+		if _, err := sponsor.Load(u.Repo); err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		ret = append(ret, u.sponsorOut(id, sponsor.Name, e, len(e.clients)))
+		total.contracts += e.contracts
+		total.packages += e.packages
+		total.forecast += e.forecast
+		total.open += e.open
+		total.overdue += e.overdue
+		for _, c := range e.clients {
+			if !slices.Contains(total.clients, c) {
+				total.clients = append(total.clients, c)
+			}
+		}
+	}
+	ret = append(ret, u.sponsorOut(pkg.ReportTotal, "", total, len(total.clients)))
+	u.Output = dtoReport.GetFormat()
+	u.Out = dtoReport.GetOut().GetDTO(ret)
+	return nil
+}
+
+// sponsoredContracts returns the contracts with sponsor in force on a date ordered by id
+func (u *Usecase) sponsoredContracts(at time.Time) ([]*domain.Contract, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	date := at.Format("2006-01-02 15:04:05")
+	base, _, err := u.Repo.Find(tx, &domain.Contract{}, -1, false, "sponsor_id is not null",
+		fmt.Sprintf("start <= '%s'", date), fmt.Sprintf("end is null or end >= '%s'", date))
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*domain.Contract{}
+	if base == nil {
+		return ret, nil
+	}
+	for _, c := range *base.(*[]domain.Contract) {
+		ret = append(ret, &c)
+	}
+	slices.SortFunc(ret, func(a, b *domain.Contract) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return ret, nil
+}
+
+// sponsorPackage returns a package keeping the ones read or nil if it does not exist
+func (u *Usecase) sponsorPackage(packages map[string]*domain.Package, id string) (*domain.Package, error) {
+	if p, ok := packages[id]; ok {
+		return p, nil
+	}
+	p := &domain.Package{ID: id}
+	exists, err := p.Load(u.Repo)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if !exists {
+		p = nil
+	}
+	packages[id] = p
+	return p, nil
+}
+
+// sponsorIDs returns the ids of the sponsors ordered
+func (u *Usecase) sponsorIDs(exposures map[string]*sponsorExposure) []string {
+	ret := []string{}
+	for id := range exposures {
+		ret = append(ret, id)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// sponsorOut returns the line of the exposure report of a sponsor
+// the exposure is the open receivables added to the forecast of the openned agendas
+func (u *Usecase) sponsorOut(id, name string, e *sponsorExposure, clients int) *dto.ReportSponsorOut {
+	return &dto.ReportSponsorOut{SponsorID: id, Name: name, Contracts: strconv.Itoa(e.contracts),
+		Clients: strconv.Itoa(clients), Packages: u.amount(e.packages), Forecast: u.amount(e.forecast),
+		Open: u.amount(e.open), Overdue: u.amount(e.overdue), Exposure: u.amount(e.open + e.forecast)}
+}
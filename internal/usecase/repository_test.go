@@ -0,0 +1,415 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// memoryRepo is a repository kept in memory that finds the objects by their filled fields
+// and by the simple sql conditions used as extras by the usecases
+type memoryRepo struct {
+	objs []interface{}
+}
+
+// newMemoryUsecase returns a usecase over a memory repository with the objects and the config informed
+func newMemoryUsecase(config map[string]string, objs ...interface{}) (*Usecase, *memoryRepo) {
+	repo := &memoryRepo{}
+	for _, o := range objs {
+		if err := repo.Add(nil, o); err != nil {
+			panic(err)
+		}
+	}
+	return &Usecase{Repo: repo, Log: log.New(io.Discard, "", 0), Config: mapConfig(config)}, repo
+}
+
+func (r *memoryRepo) Migrate(domain []interface{}) error { return nil }
+func (r *memoryRepo) Close()                             {}
+func (r *memoryRepo) Begin() interface{}                 { return r }
+func (r *memoryRepo) Commit(tx interface{}) error        { return nil }
+func (r *memoryRepo) Rollback(tx interface{}) error      { return nil }
+
+func (r *memoryRepo) Add(tx interface{}, obj interface{}) error {
+	if r.index(obj, r.id(obj)) >= 0 {
+		return fmt.Errorf("duplicate entry '%s'", r.id(obj))
+	}
+	r.objs = append(r.objs, r.copy(obj))
+	return nil
+}
+
+func (r *memoryRepo) Get(tx interface{}, obj interface{}, id string, lock bool) (bool, error) {
+	idx := r.index(obj, id)
+	if idx < 0 {
+		return false, nil
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(r.objs[idx]).Elem())
+	return true, nil
+}
+
+func (r *memoryRepo) Find(tx interface{}, obj interface{}, limit int, lock bool, extras ...interface{}) (interface{}, bool, error) {
+	found := r.match(obj, extras)
+	if len(found) == 0 {
+		return nil, false, nil
+	}
+	ret := reflect.New(reflect.SliceOf(reflect.TypeOf(obj).Elem()))
+	for _, o := range found {
+		ret.Elem().Set(reflect.Append(ret.Elem(), reflect.ValueOf(o).Elem()))
+	}
+	if limit > 0 && ret.Elem().Len() > limit {
+		ret.Elem().SetLen(limit)
+		return ret.Interface(), true, nil
+	}
+	return ret.Interface(), false, nil
+}
+
+func (r *memoryRepo) Save(tx interface{}, obj interface{}) error {
+	if idx := r.index(obj, r.id(obj)); idx >= 0 {
+		r.objs[idx] = r.copy(obj)
+		return nil
+	}
+	r.objs = append(r.objs, r.copy(obj))
+	return nil
+}
+
+func (r *memoryRepo) Delete(tx interface{}, obj interface{}, extras ...interface{}) error {
+	found := r.match(obj, extras)
+	r.objs = slices.DeleteFunc(r.objs, func(o interface{}) bool {
+		return slices.Contains(found, o)
+	})
+	return nil
+}
+
+// all returns the objects of the type of an object ordered by id
+func (r *memoryRepo) all(obj interface{}) []interface{} {
+	ret := []interface{}{}
+	for _, o := range r.objs {
+		if reflect.TypeOf(o) == reflect.TypeOf(obj) {
+			ret = append(ret, o)
+		}
+	}
+	slices.SortFunc(ret, func(a, b interface{}) int {
+		return strings.Compare(r.id(a), r.id(b))
+	})
+	return ret
+}
+
+// match returns the objects of the type of the filter with its filled fields and the extra conditions
+func (r *memoryRepo) match(filter interface{}, extras []interface{}) []interface{} {
+	ret := []interface{}{}
+	f := reflect.ValueOf(filter).Elem()
+	for _, o := range r.all(filter) {
+		value := reflect.ValueOf(o).Elem()
+		ok := true
+		for i := 0; i < f.NumField() && ok; i++ {
+			if f.Type().Field(i).Tag.Get("gorm") == "" || pkg.IsEmpty(f.Field(i).Interface()) ||
+				(f.Field(i).Kind() != reflect.Ptr && f.Field(i).IsZero()) {
+				continue
+			}
+			field := value.Field(i)
+			if field.Kind() == reflect.Ptr && field.IsNil() {
+				ok = false
+				continue
+			}
+			ok = reflect.DeepEqual(reflect.Indirect(f.Field(i)).Interface(), reflect.Indirect(field).Interface())
+		}
+		for _, extra := range extras {
+			ok = ok && r.condition(extra).eval(value)
+		}
+		if ok {
+			ret = append(ret, o)
+		}
+	}
+	return ret
+}
+
+// condition returns the parsed extra condition of a find
+func (r *memoryRepo) condition(extra interface{}) *sqlCondition {
+	switch c := extra.(type) {
+	case string:
+		return newSQLCondition(c)
+	case *pkg.Condition:
+		query := c.Query
+		for _, arg := range c.Args {
+			query = strings.Replace(query, "?", "'"+strings.ReplaceAll(fmt.Sprint(arg), "'", "''")+"'", 1)
+		}
+		return newSQLCondition(query)
+	}
+	panic(fmt.Sprintf("unsupported condition %v", extra))
+}
+
+// index returns the position of the object of the type with the id or -1
+func (r *memoryRepo) index(obj interface{}, id string) int {
+	for idx, o := range r.objs {
+		if reflect.TypeOf(o) == reflect.TypeOf(obj) && r.id(o) == id {
+			return idx
+		}
+	}
+	return -1
+}
+
+// id returns the id of an object
+func (r *memoryRepo) id(obj interface{}) string {
+	return reflect.ValueOf(obj).Elem().FieldByName("ID").String()
+}
+
+// copy returns a copy of an object, so changes on the object are only kept when it is saved
+func (r *memoryRepo) copy(obj interface{}) interface{} {
+	ret := reflect.New(reflect.TypeOf(obj).Elem())
+	ret.Elem().Set(reflect.ValueOf(obj).Elem())
+	return ret.Interface()
+}
+
+// mapConfig is a config kept in a map
+type mapConfig map[string]string
+
+func (c mapConfig) Get(key string) string { return c[key] }
+
+// sqlCondition evaluates the simple sql conditions used on the extras of the finds over an object
+// it handles and, or, parentheses, comparisons, in, like and is null over the columns of the object
+type sqlCondition struct {
+	tokens []string
+	pos    int
+	row    reflect.Value
+}
+
+// newSQLCondition returns the condition of a sql text
+func newSQLCondition(query string) *sqlCondition {
+	tokens := []string{}
+	for i := 0; i < len(query); {
+		ch := rune(query[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '\'':
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == '\'' && (j+1 == len(query) || query[j+1] != '\'') {
+					break
+				}
+				if query[j] == '\'' {
+					j++
+				}
+			}
+			tokens = append(tokens, query[i:j+1])
+			i = j + 1
+		case strings.ContainsRune("(),", ch):
+			tokens = append(tokens, string(ch))
+			i++
+		case strings.ContainsRune("<>=!", ch):
+			j := i + 1
+			for j < len(query) && strings.ContainsRune("<>=", rune(query[j])) {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(query) && !unicode.IsSpace(rune(query[j])) && !strings.ContainsRune("(),<>=!'", rune(query[j])) {
+				j++
+			}
+			tokens = append(tokens, query[i:j])
+			i = j
+		}
+	}
+	return &sqlCondition{tokens: tokens}
+}
+
+// eval returns if the row matches the condition
+func (c *sqlCondition) eval(row reflect.Value) bool {
+	c.pos, c.row = 0, row
+	ret := c.or()
+	if c.pos != len(c.tokens) {
+		panic(fmt.Sprintf("unexpected %v", c.tokens[c.pos:]))
+	}
+	return ret
+}
+
+func (c *sqlCondition) or() bool {
+	ret := c.and()
+	for c.keyword("or") {
+		ret = c.and() || ret
+	}
+	return ret
+}
+
+func (c *sqlCondition) and() bool {
+	ret := c.atom()
+	for c.keyword("and") {
+		ret = c.atom() && ret
+	}
+	return ret
+}
+
+func (c *sqlCondition) atom() bool {
+	if c.keyword("(") {
+		ret := c.or()
+		c.expect(")")
+		return ret
+	}
+	value, null := c.field(c.next())
+	switch {
+	case c.keyword("is"):
+		not := c.keyword("not")
+		c.expect("null")
+		return null != not
+	case c.keyword("in"):
+		c.expect("(")
+		found := false
+		for {
+			lit := c.literal()
+			found = found || (!null && c.compare(value, lit) == 0)
+			if !c.keyword(",") {
+				break
+			}
+		}
+		c.expect(")")
+		return found
+	case c.keyword("like"):
+		return !null && c.like(fmt.Sprint(value), c.literal())
+	}
+	op := c.next()
+	cmp := 0
+	if lit := c.literal(); !null {
+		cmp = c.compare(value, lit)
+	}
+	if null {
+		return false
+	}
+	switch op {
+	case "=":
+		return cmp == 0
+	case "<>", "!=":
+		return cmp != 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	panic("unsupported operator " + op)
+}
+
+// field returns the value of a column of the row and if it is null
+func (c *sqlCondition) field(column string) (interface{}, bool) {
+	for i := 0; i < c.row.NumField(); i++ {
+		name := ""
+		isLower := false
+		for _, ch := range c.row.Type().Field(i).Name {
+			if unicode.IsUpper(ch) && isLower {
+				name += "_"
+			}
+			isLower = unicode.IsLower(ch)
+			name += string(unicode.ToLower(ch))
+		}
+		if name != strings.ToLower(column) {
+			continue
+		}
+		value := c.row.Field(i)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return nil, true
+			}
+			value = value.Elem()
+		}
+		return value.Interface(), false
+	}
+	panic("unknown column " + column)
+}
+
+// compare compares a value of a column with a literal
+func (c *sqlCondition) compare(value interface{}, literal string) int {
+	switch v := value.(type) {
+	case time.Time:
+		local, _ := time.LoadLocation(pkg.Location)
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, literal, local); err == nil {
+				return v.Compare(t)
+			}
+		}
+		panic("invalid time " + literal)
+	case string:
+		return strings.Compare(v, literal)
+	case bool:
+		return strings.Compare(strconv.FormatBool(v), strconv.FormatBool(literal == "1" || literal == "true"))
+	}
+	number, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		panic(err)
+	}
+	f := reflect.ValueOf(value).Convert(reflect.TypeOf(number)).Float()
+	switch {
+	case f < number:
+		return -1
+	case f > number:
+		return 1
+	}
+	return 0
+}
+
+// like returns if a value matches a like pattern escaped by backslashes
+func (c *sqlCondition) like(value, pattern string) bool {
+	expr := "(?is)^"
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+			if i < len(pattern) {
+				expr += regexp.QuoteMeta(string(pattern[i]))
+			}
+		case '%':
+			expr += ".*"
+		case '_':
+			expr += "."
+		default:
+			expr += regexp.QuoteMeta(string(pattern[i]))
+		}
+	}
+	return regexp.MustCompile(expr + "$").MatchString(value)
+}
+
+// literal returns the next literal without quotes
+func (c *sqlCondition) literal() string {
+	token := c.next()
+	if strings.HasPrefix(token, "'") {
+		return strings.ReplaceAll(token[1:len(token)-1], "''", "'")
+	}
+	return token
+}
+
+// keyword consumes the next token if it is the keyword
+func (c *sqlCondition) keyword(word string) bool {
+	if c.pos < len(c.tokens) && strings.EqualFold(c.tokens[c.pos], word) {
+		c.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the next token that should be the keyword
+func (c *sqlCondition) expect(word string) {
+	if !c.keyword(word) {
+		panic(errors.New("expected " + word + " on " + strings.Join(c.tokens, " ")))
+	}
+}
+
+// next consumes the next token
+func (c *sqlCondition) next() string {
+	if c.pos >= len(c.tokens) {
+		panic("unexpected end of " + strings.Join(c.tokens, " "))
+	}
+	c.pos++
+	return c.tokens[c.pos-1]
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// agendaScore represents the score of an agenda candidate to be linked with a session
+type agendaScore struct {
+	agenda    *domain.Agenda
+	distances []float64
+	score     float64
+	result    string
+}
+
+// sessionExplain lists the agenda candidates of the sessions with their scores without tying them
+func (u *Usecase) sessionExplain(dtoIn *dto.SessionTie, sessions *[]domain.Session) error {
+	result := []interface{}{}
+	for i := range *sessions {
+		session := &(*sessions)[i]
+		agendas, err := u.searchAgendas(session, false)
+		if err != nil {
+			return err
+		}
+		if agendas == nil {
+			result = append(result, &dto.SessionExplainOut{SessionID: session.ID, Result: pkg.ErrNoAgendasFound})
+			continue
+		}
+		scores, err := u.scoreAgendas(session, agendas)
+		if err != nil {
+			return err
+		}
+		for _, sc := range scores {
+			result = append(result, &dto.SessionExplainOut{
+				SessionID: session.ID,
+				AgendaID:  sc.agenda.ID,
+				Start:     sc.agenda.Start.Format(pkg.DateTimeFormat),
+				Status:    sc.agenda.Status,
+				Client:    fmt.Sprintf("%.0f", sc.distances[0]),
+				Time:      fmt.Sprintf("%.0f", sc.distances[1]),
+				Service:   fmt.Sprintf("%.0f", sc.distances[2]),
+				Score:     fmt.Sprintf("%.2f", sc.score),
+				Result:    sc.result,
+			})
+		}
+	}
+	u.Out = dtoIn.GetExplainOut().GetDTO(result)
+	return nil
+}
+
+// scoreAgendas scores the agenda candidates of a session by the weighted distance of client, time and service
+// the agenda with lowest score and same kind is chosen and the others have the reason of rejection
+func (u *Usecase) scoreAgendas(session *domain.Session, agendas []*domain.Agenda) ([]*agendaScore, error) {
+	weights, _, tolerance := u.tieParams()
+	cmd := pkg.Commands{}
+	sKeys := []interface{}{session.ClientID, session.At, session.ServiceID}
+	ret := []*agendaScore{}
+	var best *agendaScore
+	for _, a := range agendas {
+		aKeys := []interface{}{a.ClientID, a.Start, a.ServiceID}
+		dist, err := cmd.Distances(sKeys, aKeys)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		score, err := cmd.WeightedDistance(sKeys, aKeys, weights)
+		if err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		sc := &agendaScore{agenda: a, distances: dist, score: score}
+		ret = append(ret, sc)
+		switch {
+		case !u.kindMatches(session, a):
+			sc.result = pkg.ExplainKindMismatch
+		case a.Status == pkg.AgendaStatusLocked && a.Start.Format("2006-01-02") != session.At.Format("2006-01-02"):
+			sc.result = pkg.ExplainLockedOtherDay
+		case tolerance > 0 && score > tolerance:
+			sc.result = pkg.ExplainAboveTolerance
+		case best != nil && score >= best.score:
+			sc.result = pkg.ExplainWorseScore
+		default:
+			if best != nil {
+				best.result = pkg.ExplainWorseScore
+			}
+			sc.result = pkg.ExplainChosen
+			best = sc
+		}
+	}
+	return ret, nil
+}
+
+// kindMatches checks if the session kind can be tied to the agenda kind
+// extra sessions are tied just to extra agendas and the other ones never to extra agendas
+func (u *Usecase) kindMatches(session *domain.Session, agenda *domain.Agenda) bool {
+	return (session.GetKind() == pkg.SessionKindExtra) == (agenda.Kind == pkg.AgendaKindExtra)
+}
+
+// tieParams returns the weights of client, time and service, the search window in days
+// and the score tolerance configured for session tie
+func (u *Usecase) tieParams() ([]float64, int, float64) {
+	weights := u.parseWeights(pkg.DefaultSessionTieWeights)
+	if u.Config != nil {
+		if w := u.parseWeights(u.Config.Get(pkg.ConfigSessionTieWeights)); w != nil {
+			weights = w
+		}
+	}
+	window := u.configInt(pkg.ConfigSessionTieWindow, pkg.DefaultSessionTieWindow)
+	if window <= 0 {
+		window = pkg.DefaultSessionTieWindow
+	}
+	tolerance := pkg.DefaultSessionTieTolerance
+	if u.Config != nil {
+		if t, err := strconv.ParseFloat(u.Config.Get(pkg.ConfigSessionTieTolerance), 64); err == nil && t >= 0 {
+			tolerance = t
+		}
+	}
+	return weights, window, tolerance
+}
+
+// parseWeights parses a comma separated list of the three weights returning nil if invalid
+func (u *Usecase) parseWeights(value string) []float64 {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return nil
+	}
+	ret := []float64{}
+	total := 0.0
+	for _, p := range parts {
+		w, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || w < 0 {
+			return nil
+		}
+		ret = append(ret, w)
+		total += w
+	}
+	if total == 0 {
+		return nil
+	}
+	return ret
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/lavinas/ephemeris/internal/domain"
+	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/internal/port"
+	"github.com/lavinas/ephemeris/pkg"
+)
+
+// SessionSequence checks gaps and duplicates or renumbers the sessions sequence of the clients
+func (u *Usecase) SessionSequence(dtoIn interface{}) error {
+	dtoSequence := dtoIn.(*dto.SessionSequence)
+	if err := dtoSequence.Validate(); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	clients, err := u.sequenceClients(dtoSequence.ClientID)
+	if err != nil {
+		return err
+	}
+	result := []interface{}{}
+	for _, clientID := range clients {
+		var outs []*dto.SessionSequenceOut
+		if dtoSequence.IsRenumber() {
+			unlock := u.lock(clientID)
+			outs, err = u.renumberSessions(clientID, true)
+			unlock()
+		} else {
+			outs, err = u.checkSessions(clientID)
+		}
+		if err != nil {
+			return err
+		}
+		for _, out := range outs {
+			result = append(result, out)
+		}
+	}
+	if len(result) == 0 {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrSessionNotFound, 0, 0)
+	}
+	u.Out = dtoSequence.GetOut().GetDTO(result)
+	return nil
+}
+
+// sequenceClients returns the client informed or all clients
+func (u *Usecase) sequenceClients(clientID string) ([]string, error) {
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	base, _, err := u.Repo.Find(tx, &domain.Client{ID: clientID}, -1, false)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrClientNotFound, 0, 0)
+	}
+	clients := []string{}
+	for _, c := range *base.(*[]domain.Client) {
+		clients = append(clients, c.ID)
+	}
+	return clients, nil
+}
+
+// checkSessions reports the gaps and duplicates of the sessions sequence of a client
+func (u *Usecase) checkSessions(clientID string) ([]*dto.SessionSequenceOut, error) {
+	scopes, err := domain.LoadSessionScopes(u.Repo, nil, clientID, u.sequenceByContract())
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*dto.SessionSequenceOut{}
+	for _, scope := range scopes.Keys() {
+		sessions := scopes.Sessions[scope]
+		bySeq := map[int][]string{}
+		first, last := *sessions[0].Sequence, *sessions[0].Sequence
+		for _, s := range sessions {
+			bySeq[*s.Sequence] = append(bySeq[*s.Sequence], s.ID)
+			first, last = min(first, *s.Sequence), max(last, *s.Sequence)
+		}
+		problems := []*dto.SessionSequenceOut{}
+		for seq := first; seq <= last; seq++ {
+			ids := bySeq[seq]
+			if len(ids) == 0 {
+				problems = append(problems, u.sequenceOut(clientID, scope, seq, "", pkg.SequenceGap))
+			}
+			for i := 0; len(ids) > 1 && i < len(ids); i++ {
+				problems = append(problems, u.sequenceOut(clientID, scope, seq, ids[i], pkg.SequenceDuplicate))
+			}
+		}
+		if len(problems) == 0 {
+			problems = append(problems, u.sequenceOut(clientID, scope, last, "", pkg.SequenceOk))
+		}
+		ret = append(ret, problems...)
+	}
+	return ret, nil
+}
+
+// renumberSessions renumbers the sessions sequence of a client following the sessions dates
+// if not forced, just the scopes with sessions out of order or duplicated are renumbered
+// numbering starts on the lowest sequence of the scope, or on 1 if it is not positive
+func (u *Usecase) renumberSessions(clientID string, force bool) ([]*dto.SessionSequenceOut, error) {
+	scopes, err := domain.LoadSessionScopes(u.Repo, nil, clientID, u.sequenceByContract())
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	ret := []*dto.SessionSequenceOut{}
+	changed := []*domain.Session{}
+	for _, scope := range scopes.Keys() {
+		sessions := scopes.Sessions[scope]
+		if !force && !u.sequenceOutOfOrder(sessions) {
+			continue
+		}
+		seq := 0
+		for _, s := range sessions {
+			if *s.Sequence > 0 && (seq == 0 || *s.Sequence < seq) {
+				seq = *s.Sequence
+			}
+		}
+		seq = max(seq, 1)
+		outs := []*dto.SessionSequenceOut{}
+		for _, s := range sessions {
+			if *s.Sequence != seq {
+				outs = append(outs, u.sequenceOut(clientID, scope, seq, s.ID, fmt.Sprintf(pkg.SequenceRenumbered, *s.Sequence)))
+				number := seq
+				s.Sequence = &number
+				changed = append(changed, s)
+			}
+			seq++
+		}
+		if len(outs) == 0 {
+			outs = append(outs, u.sequenceOut(clientID, scope, seq-1, "", pkg.SequenceOk))
+		}
+		ret = append(ret, outs...)
+	}
+	if len(changed) == 0 {
+		return ret, nil
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	for _, s := range changed {
+		if err := u.Repo.Save(tx, s); err != nil {
+			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return ret, nil
+}
+
+// renumberOutOfOrder renumbers the clients of the sessions that became out of order
+func (u *Usecase) renumberOutOfOrder(sessions []*domain.Session) error {
+	clients := []string{}
+	for _, s := range sessions {
+		if !slices.Contains(clients, s.ClientID) {
+			clients = append(clients, s.ClientID)
+		}
+	}
+	for _, clientID := range clients {
+		unlock := u.lock(clientID)
+		_, err := u.renumberSessions(clientID, false)
+		unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sequenceOutOfOrder checks if sessions sorted by date have not increasing sequences
+func (u *Usecase) sequenceOutOfOrder(sessions []*domain.Session) bool {
+	for i := 1; i < len(sessions); i++ {
+		if *sessions[i].Sequence <= *sessions[i-1].Sequence {
+			return true
+		}
+	}
+	return false
+}
+
+// sequenceByContract returns if sessions are numbered per contract instead of per client
+func (u *Usecase) sequenceByContract() bool {
+	return u.Config != nil && u.Config.Get(pkg.ConfigSessionSequenceScope) == pkg.SequenceScopeContract
+}
+
+// assignSequence assigns the next sequence of its client or contract to a session added without one
+// the sequence is read on the add transaction, so the sessions added before on it are counted
+func (u *Usecase) assignSequence(tx interface{}, d port.Domain) error {
+	session, ok := d.(*domain.Session)
+	if !ok || session.Sequence != nil || session.ClientID == "" {
+		return nil
+	}
+	next, err := session.NextSequence(u.Repo, tx, u.sequenceByContract())
+	if err != nil {
+		return err
+	}
+	session.Sequence = &next
+	return nil
+}
+
+// sequenceOut returns a sequence output line
+func (u *Usecase) sequenceOut(clientID, scope string, seq int, sessionID, result string) *dto.SessionSequenceOut {
+	if scope == "" {
+		scope = pkg.SequenceScopeClient
+	}
+	return &dto.SessionSequenceOut{ClientID: clientID, Scope: scope, Sequence: strconv.Itoa(seq),
+		SessionID: sessionID, Result: result}
+}
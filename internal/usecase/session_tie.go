@@ -2,30 +2,38 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
 	"slices"
 	"time"
 
 	"github.com/lavinas/ephemeris/internal/domain"
 	"github.com/lavinas/ephemeris/internal/dto"
+	"github.com/lavinas/ephemeris/internal/port"
 	"github.com/lavinas/ephemeris/pkg"
 )
 
-const (
-	session_tie_jobs = 1
-)
-
 // SessionTie ties a session to an agenda
 func (u *Usecase) SessionTie(dtoIn interface{}) error {
 	dtoSessionTie := dtoIn.(*dto.SessionTie)
 	if err := dtoSessionTie.Validate(); err != nil {
 		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
 	}
+	if err := u.validateSessionTie(dtoSessionTie); err != nil {
+		return err
+	}
 	sessions, err := u.findSessionsTie(dtoSessionTie)
 	if err != nil {
 		return err
 	}
-	command := dtoSessionTie.GetCommand()
-	result := u.sessionTieLoop(command, sessions)
+	limit := u.configInt(pkg.ConfigSessionTieLimit, pkg.DefaultSessionTieLimit)
+	if limit > 0 && len(*sessions) > limit && !dtoSessionTie.IsProceed() {
+		return u.error(pkg.ErrPrefBadRequest, fmt.Sprintf(pkg.ErrSessionTieLimit, len(*sessions), limit), 0, 0)
+	}
+	if dtoSessionTie.IsExplain() {
+		return u.sessionExplain(dtoSessionTie, sessions)
+	}
+	jobs := u.sessionTieJobs(dtoSessionTie)
+	result := u.sessionTieLoop(dtoSessionTie, sessions, jobs)
 	if len(result) == 0 {
 		return u.error(pkg.ErrPrefBadRequest, pkg.ErrNoSessionsProcessed, 0, 0)
 	}
@@ -34,6 +42,46 @@ func (u *Usecase) SessionTie(dtoIn interface{}) error {
 	return nil
 }
 
+// validateSessionTie validates the filters of session tie against existing clients and services
+func (u *Usecase) validateSessionTie(dtoIn *dto.SessionTie) error {
+	if err := u.validateFilter(&domain.Client{}, dtoIn.ClientID, pkg.ErrClientNotFound); err != nil {
+		return err
+	}
+	if err := u.validateFilter(&domain.Service{}, dtoIn.ServiceID, pkg.ErrServiceNotFound); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateFilter validates if a filter value, exact or with the * wildcard, matches at least one register
+// other characters, like the hyphens of the ids, are matched literally
+func (u *Usecase) validateFilter(obj port.Domain, value string, msg string) error {
+	if value == "" {
+		return nil
+	}
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	like := pkg.LikeCondition("id", value)
+	if like == nil {
+		ok, err := u.Repo.Get(tx, obj, value, false)
+		if err != nil {
+			return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+		}
+		if !ok {
+			return u.error(pkg.ErrPrefBadRequest, msg, 0, 0)
+		}
+		return nil
+	}
+	base, _, err := u.Repo.Find(tx, obj, 1, false, like)
+	if err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if base == nil {
+		return u.error(pkg.ErrPrefBadRequest, msg, 0, 0)
+	}
+	return nil
+}
+
 // findSessionsTie finds a session to tie
 func (u *Usecase) findSessionsTie(dtoIn *dto.SessionTie) (*[]domain.Session, error) {
 	d, extras, err := dtoIn.GetInstructions(dtoIn.GetDomain()[0])
@@ -69,52 +117,102 @@ func (u *Usecase) sessionSortFunc(a domain.Session, b domain.Session) int {
 	}
 }
 
-// sessionTieLoop2 process multiple sessions
-func (u *Usecase) sessionTieLoop(command string, sessions *[]domain.Session) []interface{} {
-	jobs := make(chan *domain.Session, len(*sessions))
-	result := make(chan interface{}, len(*sessions))
-	for w := 0; w < session_tie_jobs; w++ {
-		go u.sessionTieJob(command, jobs, result)
+// sessionTieJobs returns the number of parallel jobs informed on command, config or default
+func (u *Usecase) sessionTieJobs(dtoIn *dto.SessionTie) int {
+	jobs := dtoIn.GetJobs()
+	if jobs == 0 {
+		jobs = u.configInt(pkg.ConfigSessionTieJobs, pkg.DefaultSessionTieJobs)
 	}
-	for _, session := range *sessions {
-		jobs <- &session
+	if jobs < 1 || jobs > pkg.MaxSessionTieJobs {
+		jobs = pkg.DefaultSessionTieJobs
 	}
-	close(jobs)
-	ret := []interface{}{}
-	for i := 0; i < len(*sessions); i++ {
-		r := <-result
-		ret = append(ret, r)
+	return jobs
+}
+
+// sessionTieTask represents a session to be processed by a job and its position on the sorted result
+type sessionTieTask struct {
+	idx     int
+	session *domain.Session
+	result  interface{}
+}
+
+// sessionTieLoop process multiple sessions in parallel keeping the original order on result
+func (u *Usecase) sessionTieLoop(dtoIn *dto.SessionTie, sessions *[]domain.Session, jobs int) []interface{} {
+	total := len(*sessions)
+	tasks := make(chan *sessionTieTask, total)
+	done := make(chan *sessionTieTask, total)
+	for w := 0; w < jobs; w++ {
+		go u.sessionTieJob(dtoIn, tasks, done)
+	}
+	for i := range *sessions {
+		tasks <- &sessionTieTask{idx: i, session: &(*sessions)[i]}
+	}
+	close(tasks)
+	ret := make([]interface{}, total)
+	step := total / pkg.SessionTieProgressSteps
+	if step == 0 {
+		step = 1
+	}
+	for i := 1; i <= total; i++ {
+		t := <-done
+		ret[t.idx] = t.result
+		if i%step == 0 || i == total {
+			u.progress(pkg.MsgSessionTieProgress, dtoIn.GetCommand(), i, total)
+		}
 	}
-	close(result)
+	close(done)
 	return ret
 }
 
+// progress shows the progress of a long running command on the progress output, stderr by default,
+// keeping the command output clean, and logs it
+func (u *Usecase) progress(format string, args ...interface{}) {
+	if u.Progress != nil {
+		fmt.Fprintf(u.Progress, format+"\n", args...)
+	}
+	u.Log.Printf(format, args...)
+}
+
 // sessionTieJob is a job to tie a session in parallel
-func (u *Usecase) sessionTieJob(command string, jobs <-chan *domain.Session, result chan<- interface{}) {
-	for s := range jobs {
-		ss, err := u.sessionTieOne(s.ID, command)
+// sessions of the same client are serialized as they compete for the same agendas
+// numbering per contract, sessions are renumbered as tying changes the contract of the session
+func (u *Usecase) sessionTieJob(dtoIn *dto.SessionTie, tasks <-chan *sessionTieTask, done chan<- *sessionTieTask) {
+	for t := range tasks {
+		unlock := u.lock(t.session.ClientID)
+		ss, err := u.sessionTieOne(t.session.ID, dtoIn)
+		if err == nil && u.sequenceByContract() {
+			_, err = u.renumberSessions(t.session.ClientID, false)
+		}
+		unlock()
 		if err != nil {
-			s.Process = pkg.ProcessStatusError
-			s.AgendaID = err.Error()
-			result <- s
-			continue
+			t.session.Process = pkg.ProcessStatusError
+			t.session.AgendaID = err.Error()
+			t.result = t.session
+		} else {
+			t.result = ss
 		}
-		result <- ss
+		done <- t
 	}
 }
 
 // sessionTieOne ties a session to an agenda
-func (u *Usecase) sessionTieOne(id string, command string) (*domain.Session, error) {
+func (u *Usecase) sessionTieOne(id string, dtoIn *dto.SessionTie) (*domain.Session, error) {
 	session, err := u.getLockSession(id)
 	if err != nil {
 		return nil, err
 	}
 	defer u.unlockSession(session)
 	cmdMap := map[string]func(*domain.Session) error{
-		"tie":     u.tieCommand,
-		"untie":   u.untieCommand,
-		"confirm": u.confirmCommand,
-	}
+		"tie":   u.tieCommand,
+		"untie": u.untieCommand,
+		"confirm": func(s *domain.Session) error {
+			return u.confirmCommand(s, dtoIn.IsReschedule(), dtoIn.GetOriginal())
+		},
+		"recontract": func(s *domain.Session) error {
+			return u.recontractCommand(s, dtoIn.ContractID)
+		},
+	}
+	command := dtoIn.GetCommand()
 	if cmdMap[command] == nil {
 		return nil, u.error(pkg.ErrPrefBadRequest, pkg.ErrCommandImplemented, 0, 0)
 	}
@@ -142,7 +240,8 @@ func (u *Usecase) untieCommand(session *domain.Session) error {
 }
 
 // confirmCommand implements command "confirm"
-func (u *Usecase) confirmCommand(session *domain.Session) error {
+// with reschedule, a new rescheduled agenda is created on the session date bonded to the original one
+func (u *Usecase) confirmCommand(session *domain.Session, reschedule bool, original string) error {
 	if session.Process != pkg.ProcessStatusUnconfirmed {
 		return errors.New(pkg.ErrSessionNotUnconfirmed)
 	}
@@ -154,6 +253,9 @@ func (u *Usecase) confirmCommand(session *domain.Session) error {
 		return u.error(pkg.ErrPrefInternal, pkg.ErrEmptyAgenda, 0, 0)
 	}
 	defer u.unlockAgendas([]*domain.Agenda{agenda})
+	if reschedule {
+		return u.rescheduleSessionAgenda(session, agenda, original)
+	}
 	session.Process = pkg.ProcessStatusLinked
 	agenda.Status = session.Status
 	if err := u.saveSessionAgenda(session, agenda); err != nil {
@@ -162,6 +264,62 @@ func (u *Usecase) confirmCommand(session *domain.Session) error {
 	return nil
 }
 
+// rescheduleSessionAgenda creates a rescheduled agenda on the session date and links the session to it
+func (u *Usecase) rescheduleSessionAgenda(session *domain.Session, agenda *domain.Agenda, original string) error {
+	bond := agenda.ID
+	resched := &domain.Agenda{
+		ID:           fmt.Sprintf(idFormat, session.At.Format(idDateFormat), session.ClientID),
+		Date:         time.Now(),
+		ClientID:     session.ClientID,
+		ServiceID:    session.ServiceID,
+		ContractID:   agenda.ContractID,
+		Start:        session.At,
+		End:          session.At.Add(agenda.End.Sub(agenda.Start)),
+		Price:        agenda.Price,
+		Kind:         pkg.AgendaKindRescheduled,
+		Status:       session.Status,
+		Bond:         &bond,
+		Professional: agenda.Professional,
+	}
+	if err := resched.Format(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefBadRequest, err.Error(), 0, 0)
+	}
+	agenda.Status = original
+	session.Process = pkg.ProcessStatusLinked
+	session.AgendaID = resched.ID
+	tx := u.Repo.Begin()
+	defer u.Repo.Rollback(tx)
+	if err := u.Repo.Add(tx, resched); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Save(tx, agenda); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Save(tx, session); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if err := u.Repo.Commit(tx); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	return nil
+}
+
+// recontractCommand implements command "recontract"
+// it moves the session to another contract of the same client and ties it again
+func (u *Usecase) recontractCommand(session *domain.Session, contractID string) error {
+	contract := &domain.Contract{ID: contractID}
+	if ok, err := contract.Load(u.Repo); err != nil {
+		return u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	} else if !ok {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractNotFound, 0, 0)
+	}
+	if contract.ClientID != session.ClientID {
+		return u.error(pkg.ErrPrefBadRequest, pkg.ErrContractClientMismatch, 0, 0)
+	}
+	session.ContractID = &contract.ID
+	return u.tieCommand(session)
+}
+
 // untieSession unties a session from agendas
 func (u *Usecase) untieSession(session *domain.Session) error {
 	agenda, err := u.restartLockAgenda(session.AgendaID)
@@ -219,19 +377,26 @@ func (u *Usecase) tieSession(session *domain.Session) (*domain.Session, error) {
 	return over, nil
 }
 
-// searchLockAgendas searches agendas first for same day and then for a longer period
+// searchLockAgendas searches agendas first for same day and then for a longer period and locks them
 func (u *Usecase) searchLockAgendas(session *domain.Session) ([]*domain.Agenda, error) {
-	ag := domain.Agenda{ClientID: session.ClientID}
+	return u.searchAgendas(session, true)
+}
+
+// searchAgendas searches agendas first for same day and then for the configured window of days
+func (u *Usecase) searchAgendas(session *domain.Session, lock bool) ([]*domain.Agenda, error) {
+	_, window, _ := u.tieParams()
+	ag := domain.Agenda{ClientID: session.ClientID, ContractID: session.ContractID}
 	start := time.Date(session.At.Year(), session.At.Month(), session.At.Day(), 0, 0, 0, 0, time.Local)
 	end := time.Date(session.At.Year(), session.At.Month(), session.At.Day(), 23, 59, 59, 0, time.Local)
-	agendas, err := u.getLockAgenda(&ag, start, end, []string{pkg.AgendaStatusOpenned})
+	agendas, err := u.loadAgendas(&ag, start, end, []string{pkg.AgendaStatusOpenned}, lock)
 	if err != nil {
 		return nil, err
 	}
 	if agendas == nil {
-		start = session.At.Add(-time.Hour * 24 * 60)
-		end = session.At.Add(time.Hour * 24 * 60)
-		agendas, err = u.getLockAgenda(&ag, start, end, []string{pkg.AgendaStatusOpenned, pkg.AgendaStatusLocked})
+		start = session.At.AddDate(0, 0, -window)
+		end = session.At.AddDate(0, 0, window)
+		status := []string{pkg.AgendaStatusOpenned, pkg.AgendaStatusLocked}
+		agendas, err = u.loadAgendas(&ag, start, end, status, lock)
 		if err != nil {
 			return nil, err
 		}
@@ -239,6 +404,21 @@ func (u *Usecase) searchLockAgendas(session *domain.Session) ([]*domain.Agenda,
 	return agendas, nil
 }
 
+// loadAgendas loads agendas of a interval of dates locking them if required
+func (u *Usecase) loadAgendas(agenda *domain.Agenda, start, end time.Time, status []string, lock bool) ([]*domain.Agenda, error) {
+	if lock {
+		return u.getLockAgenda(agenda, start, end, status)
+	}
+	agendas, err := agenda.LoadRange(u.Repo, start, end, status)
+	if err != nil {
+		return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
+	}
+	if len(agendas) == 0 {
+		return nil, nil
+	}
+	return agendas, nil
+}
+
 // saveSessionAgenda saves the session agenda
 func (u *Usecase) saveSessionAgenda(session *domain.Session, agenda *domain.Agenda) error {
 	tx := u.Repo.Begin()
@@ -301,32 +481,21 @@ func (u *Usecase) lockAgendas(agendas []*domain.Agenda) error {
 	return nil
 }
 
-// findAgendas finds agendas linked with session
+// findAgendas finds the agenda with best score to be linked with session
 func (u *Usecase) findAgenda(session *domain.Session, agendas []*domain.Agenda) (*domain.Agenda, error) {
 	if session == nil || agendas == nil {
 		return nil, nil
 	}
-	ag := &domain.Agenda{}
-	cmd := pkg.Commands{}
-	sKeys := []interface{}{session.ClientID, session.At, session.ServiceID}
-	weights := []float64{100, 10, 1}
-	dist := -1.0
-	for _, a := range agendas {
-		aKeys := []interface{}{a.ClientID, a.Start, a.ServiceID}
-		idx, err := cmd.WeightedDistance(sKeys, aKeys, weights)
-		if err != nil {
-			return nil, u.error(pkg.ErrPrefInternal, err.Error(), 0, 0)
-		}
-		if a.Status == pkg.AgendaStatusLocked && a.Start.Format("2006-01-02") != session.At.Format("2006-01-02") {
-			continue
-		}
-		if dist != -1.0 && idx >= dist {
-			continue
+	scores, err := u.scoreAgendas(session, agendas)
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range scores {
+		if sc.result == pkg.ExplainChosen {
+			return sc.agenda, nil
 		}
-		ag = a
-		dist = idx
 	}
-	return ag, nil
+	return nil, nil
 }
 
 // getOverlappingSession gets overlapping session matched with found agenda
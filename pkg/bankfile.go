@@ -0,0 +1,221 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// cnab240Liquidations are the occurrence codes of the segment T that settle a bill
+	cnab240Liquidations = []string{"06", "17"}
+	// cnab400Liquidations are the occurrence codes of the detail record that settle a bill
+	cnab400Liquidations = []string{"06", "07", "08", "17"}
+)
+
+// BankCredit represents a credit of a bank statement or a settled bill of a billing return file
+type BankCredit struct {
+	ID        string
+	Line      int
+	Date      time.Time
+	Value     float64
+	Reference string
+	Document  string
+	Name      string
+}
+
+// ParseBankFile parses an OFX statement or a CNAB 240 or 400 return file detected by its content
+// it returns the format detected and the credits of the file
+func ParseBankFile(data []byte) (string, []*BankCredit, error) {
+	text := string(data)
+	if strings.Contains(strings.ToUpper(text), "<OFX>") {
+		credits, err := ParseOFX(data)
+		return BankFileOFX, credits, err
+	}
+	lines := bankLines(text)
+	if len(lines) > 0 && len(lines[0]) == 240 {
+		credits, err := ParseCNAB240(data)
+		return BankFileCNAB240, credits, err
+	}
+	if len(lines) > 0 && len(lines[0]) == 400 {
+		credits, err := ParseCNAB400(data)
+		return BankFileCNAB400, credits, err
+	}
+	return "", nil, errors.New(ErrBankFileFormat)
+}
+
+// ParseOFX parses the credit transactions of an OFX statement on SGML or XML format
+func ParseOFX(data []byte) ([]*BankCredit, error) {
+	text := string(data)
+	ret := []*BankCredit{}
+	re := regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	for _, idx := range re.FindAllStringSubmatchIndex(text, -1) {
+		block := text[idx[2]:idx[3]]
+		line := strings.Count(text[:idx[0]], "\n") + 1
+		value, err := strconv.ParseFloat(strings.Replace(ofxTag(block, "TRNAMT"), ",", ".", 1), 64)
+		if err != nil {
+			return nil, fmt.Errorf(ErrBankFileLine, line, "TRNAMT")
+		}
+		if value <= 0 {
+			continue
+		}
+		posted := ofxTag(block, "DTPOSTED")
+		if len(posted) < 8 {
+			return nil, fmt.Errorf(ErrBankFileLine, line, "DTPOSTED")
+		}
+		date, err := time.Parse("20060102", posted[:8])
+		if err != nil {
+			return nil, fmt.Errorf(ErrBankFileLine, line, "DTPOSTED")
+		}
+		// the id keeps the credit from being imported twice, so a credit without it is not accepted
+		id := ofxTag(block, "FITID")
+		if id == "" {
+			return nil, fmt.Errorf(ErrBankFileLine, line, "FITID")
+		}
+		memo := strings.TrimSpace(ofxTag(block, "NAME") + " " + ofxTag(block, "MEMO"))
+		ret = append(ret, &BankCredit{
+			ID:        id,
+			Line:      line,
+			Date:      date,
+			Value:     value,
+			Reference: strings.TrimSpace(ofxTag(block, "REFNUM") + " " + ofxTag(block, "CHECKNUM") + " " + memo),
+			Document:  bankDocument(memo),
+			Name:      ofxTag(block, "NAME"),
+		})
+	}
+	return ret, nil
+}
+
+// ParseCNAB240 parses the settled bills of a CNAB 240 billing return file from its segments T and U
+func ParseCNAB240(data []byte) ([]*BankCredit, error) {
+	ret := []*BankCredit{}
+	var current *BankCredit
+	for i, line := range bankLines(string(data)) {
+		if len(line) != 240 {
+			return nil, fmt.Errorf(ErrBankFileLine, i+1, "length")
+		}
+		if line[7] != '3' {
+			continue
+		}
+		switch line[13] {
+		case 'T':
+			current = nil
+			if !slices.Contains(cnab240Liquidations, line[15:17]) {
+				continue
+			}
+			document := strings.TrimLeft(strings.TrimSpace(line[133:148]), "0")
+			switch line[132] {
+			case '1':
+				document = fmt.Sprintf("%011s", document)
+			case '2':
+				document = fmt.Sprintf("%014s", document)
+			default:
+				document = ""
+			}
+			if strings.TrimSpace(line[37:57]) == "" {
+				return nil, fmt.Errorf(ErrBankFileLine, i+1, "id")
+			}
+			current = &BankCredit{
+				ID:        strings.TrimSpace(line[37:57]),
+				Line:      i + 1,
+				Reference: strings.TrimSpace(line[58:73]),
+				Document:  document,
+				Name:      strings.TrimSpace(line[148:188]),
+			}
+		case 'U':
+			if current == nil {
+				continue
+			}
+			value, err := cnabValue(line[77:92])
+			if err != nil {
+				return nil, fmt.Errorf(ErrBankFileLine, i+1, "value")
+			}
+			date, err := time.Parse("02012006", line[137:145])
+			if err != nil {
+				return nil, fmt.Errorf(ErrBankFileLine, i+1, "date")
+			}
+			current.Value = value
+			current.Date = date
+			ret = append(ret, current)
+			current = nil
+		}
+	}
+	return ret, nil
+}
+
+// ParseCNAB400 parses the settled bills of a CNAB 400 billing return file from its detail records
+func ParseCNAB400(data []byte) ([]*BankCredit, error) {
+	ret := []*BankCredit{}
+	for i, line := range bankLines(string(data)) {
+		if len(line) != 400 {
+			return nil, fmt.Errorf(ErrBankFileLine, i+1, "length")
+		}
+		if line[0] != '1' || !slices.Contains(cnab400Liquidations, line[108:110]) {
+			continue
+		}
+		value, err := cnabValue(line[253:266])
+		if err != nil {
+			return nil, fmt.Errorf(ErrBankFileLine, i+1, "value")
+		}
+		date, err := time.Parse("020106", line[110:116])
+		if err != nil {
+			return nil, fmt.Errorf(ErrBankFileLine, i+1, "date")
+		}
+		if strings.TrimSpace(line[62:70]) == "" {
+			return nil, fmt.Errorf(ErrBankFileLine, i+1, "id")
+		}
+		ret = append(ret, &BankCredit{
+			ID:        strings.TrimSpace(line[62:70]) + strings.TrimSpace(line[110:116]),
+			Line:      i + 1,
+			Date:      date,
+			Value:     value,
+			Reference: strings.TrimSpace(strings.TrimSpace(line[37:62]) + " " + strings.TrimSpace(line[116:126])),
+		})
+	}
+	return ret, nil
+}
+
+// ofxTag returns the value of a tag of an OFX block closed or not
+func ofxTag(block, tag string) string {
+	m := regexp.MustCompile(`(?i)<` + tag + `>([^<\r\n]*)`).FindStringSubmatch(block)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[1])
+}
+
+// bankLines splits a bank file in lines without the line breaks and the empty ones
+func bankLines(text string) []string {
+	ret := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		if line != "" {
+			ret = append(ret, line)
+		}
+	}
+	return ret
+}
+
+// cnabValue parses a CNAB numeric field with two implicit decimals
+func cnabValue(field string) (float64, error) {
+	cents, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return float64(cents) / 100, nil
+}
+
+// bankDocument finds a cpf or cnpj on a statement text returning its digits
+func bankDocument(text string) string {
+	re := regexp.MustCompile(`\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}|\d{3}\.?\d{3}\.?\d{3}-?\d{2}`)
+	for _, m := range re.FindAllString(text, -1) {
+		digits := regexp.MustCompile(`\D`).ReplaceAllString(m, "")
+		if len(digits) == 11 || len(digits) == 14 {
+			return digits
+		}
+	}
+	return ""
+}
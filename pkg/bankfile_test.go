@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// bankLine returns a fixed width line with the fields informed by their 1-based start position
+func bankLine(size int, fields map[int]string) string {
+	line := []byte(strings.Repeat(" ", size))
+	for pos, value := range fields {
+		copy(line[pos-1:], value)
+	}
+	return string(line)
+}
+
+func TestParseOFX(t *testing.T) {
+	ofx := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240115100000[-3:BRT]
+<TRNAMT>150,50
+<FITID>A1
+<MEMO>PIX RECEBIDO 529.982.247-25 202401john
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240116
+<TRNAMT>-20.00
+<FITID>A2
+<MEMO>TARIFA
+</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+	format, credits, err := ParseBankFile([]byte(ofx))
+	if err != nil || format != BankFileOFX || len(credits) != 1 {
+		t.Fatalf("ParseBankFile() = %q, %d credits, %v, want one ofx credit", format, len(credits), err)
+	}
+	c := credits[0]
+	if c.ID != "A1" || c.Value != 150.5 || !c.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) ||
+		c.Document != "52998224725" || !strings.Contains(c.Reference, "202401john") {
+		t.Errorf("ParseOFX() = %+v", c)
+	}
+	if _, err := ParseOFX([]byte(strings.Replace(ofx, "<FITID>A1\n", "", 1))); err == nil {
+		t.Errorf("ParseOFX() credit without FITID error = nil, want error")
+	}
+}
+
+func TestParseCNAB240(t *testing.T) {
+	lines := []string{
+		bankLine(240, map[int]string{1: "00100000"}),
+		bankLine(240, map[int]string{8: "3", 14: "T", 16: "06", 38: "000000000000123", 59: "202401john",
+			133: "1", 134: "000052998224725", 149: "JOHN DOE"}),
+		bankLine(240, map[int]string{8: "3", 14: "U", 78: "000000000015050", 138: "15012024"}),
+		bankLine(240, map[int]string{8: "3", 14: "T", 16: "02", 38: "000000000000124"}),
+		bankLine(240, map[int]string{8: "3", 14: "U", 78: "000000000010000", 138: "16012024"}),
+	}
+	format, credits, err := ParseBankFile([]byte(strings.Join(lines, "\r\n")))
+	if err != nil || format != BankFileCNAB240 || len(credits) != 1 {
+		t.Fatalf("ParseBankFile() = %q, %d credits, %v, want one cnab240 credit", format, len(credits), err)
+	}
+	c := credits[0]
+	if c.ID != "000000000000123" || c.Value != 150.5 || c.Reference != "202401john" || c.Document != "52998224725" ||
+		c.Name != "JOHN DOE" || c.Line != 2 || !c.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseCNAB240() = %+v", c)
+	}
+	if _, err := ParseCNAB240([]byte(lines[0] + "\n" + lines[1][:200])); err == nil {
+		t.Errorf("ParseCNAB240() with short line error = nil, want error")
+	}
+	noID := bankLine(240, map[int]string{8: "3", 14: "T", 16: "06", 59: "202401john"})
+	if _, err := ParseCNAB240([]byte(lines[0] + "\n" + noID + "\n" + lines[2])); err == nil {
+		t.Errorf("ParseCNAB240() settled bill without id error = nil, want error")
+	}
+}
+
+func TestParseCNAB400(t *testing.T) {
+	lines := []string{
+		bankLine(400, map[int]string{1: "02RETORNO"}),
+		bankLine(400, map[int]string{1: "1", 38: "202401john", 63: "00000123", 109: "06", 111: "150124",
+			117: "DOC123", 254: "0000000015050"}),
+		bankLine(400, map[int]string{1: "1", 38: "202401mary", 109: "02", 111: "150124", 254: "0000000010000"}),
+		bankLine(400, map[int]string{1: "9"}),
+	}
+	format, credits, err := ParseBankFile([]byte(strings.Join(lines, "\n")))
+	if err != nil || format != BankFileCNAB400 || len(credits) != 1 {
+		t.Fatalf("ParseBankFile() = %q, %d credits, %v, want one cnab400 credit", format, len(credits), err)
+	}
+	c := credits[0]
+	if c.ID != "00000123150124" || c.Value != 150.5 || c.Reference != "202401john DOC123" ||
+		!c.Date.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseCNAB400() = %+v", c)
+	}
+	noID := bankLine(400, map[int]string{1: "1", 38: "202401john", 109: "06", 111: "150124", 254: "0000000015050"})
+	if _, err := ParseCNAB400([]byte(lines[0] + "\n" + noID)); err == nil {
+		t.Errorf("ParseCNAB400() settled bill without id error = nil, want error")
+	}
+	if _, _, err := ParseBankFile([]byte("not a bank file")); err == nil {
+		t.Errorf("ParseBankFile() unknown format error = nil, want error")
+	}
+}
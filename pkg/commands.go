@@ -1,6 +1,9 @@
 package pkg
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -29,6 +32,12 @@ type Param struct {
 	transpose string
 }
 
+// Condition is a parameterized condition of a query with its placeholders and their arguments
+type Condition struct {
+	Query string
+	Args  []interface{}
+}
+
 // Texts is a struct that groups all texts functionalities
 type Commands struct {
 }
@@ -39,13 +48,15 @@ func NewCommands() *Commands {
 }
 
 // MarshalSlice is a function that converts a slice of structs to a string
+// as a table or as csv or json when these args are informed
 func (c *Commands) Marshal(v interface{}, args ...string) string {
 	rvl := c.getInputSlice(v)
 	if len(rvl) == 0 {
 		return ErrNoResults
 	}
 	nokeys := slices.Contains(args, "nokeys")
-	counter := slices.Contains(args, "counter")
+	asCSV, asJSON := slices.Contains(args, OutputCSV), slices.Contains(args, OutputJSON)
+	counter := slices.Contains(args, "counter") && !asCSV && !asJSON
 	ret := c.getValuesSlice(rvl, nokeys, counter)
 	if len(ret) == 0 {
 		return ErrNoResults
@@ -53,6 +64,12 @@ func (c *Commands) Marshal(v interface{}, args ...string) string {
 	if slices.Contains(args, "trim") {
 		ret = c.trimTable(ret)
 	}
+	if asCSV {
+		return c.mountCSV(ret)
+	}
+	if asJSON {
+		return c.mountJSON(ret)
+	}
 	if slices.Contains(args, "more") {
 		cols := len(ret[0])
 		more := []string{}
@@ -129,7 +146,7 @@ func (c *Commands) Transpose(v interface{}) ([]interface{}, error) {
 			param.name = param.value
 		}
 		params = append(params, param)
-		if trs != "" {
+		if trs != nil {
 			trans = append(trans, trs)
 		}
 	}
@@ -154,14 +171,33 @@ func (c *Commands) Sort(v interface{}, cmd string) {
 // w is the weight for each field
 // returns the distance between the two sliceswhen 0 means that the two slices are equal
 func (c *Commands) WeightedDistance(x, y []interface{}, w []float64) (float64, error) {
-	if len(x) != len(y) || len(x) != len(w) {
+	if len(x) != len(w) {
 		return 0, fmt.Errorf("slices must have the same length")
 	}
+	d, err := c.Distances(x, y)
+	if err != nil {
+		return 0, err
+	}
 	z := 0.0
 	total := 0.0
+	for i := range d {
+		z += d[i] * w[i]
+		total += w[i]
+	}
+	return z / total, nil
+}
+
+// Distances calculates the absolute distance between each field of two slices of interfaces
+// interface{} can be any type of data, but implemented only for strings, time, int64 and float64
+// strings are compared by levenshtein distance and times by seconds
+func (c *Commands) Distances(x, y []interface{}) ([]float64, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("slices must have the same length")
+	}
+	ret := make([]float64, len(x))
 	for i := range x {
 		if reflect.TypeOf(x[i]) != reflect.TypeOf(y[i]) {
-			return 0, fmt.Errorf("slices must have the same types")
+			return nil, fmt.Errorf("slices must have the same types")
 		}
 		z1 := 0.0
 		switch reflect.TypeOf(x[i]).String() {
@@ -174,13 +210,11 @@ func (c *Commands) WeightedDistance(x, y []interface{}, w []float64) (float64, e
 		case "time.Time":
 			z1 = float64(x[i].(time.Time).Sub(y[i].(time.Time)).Seconds())
 		default:
-			fmt.Println(reflect.TypeOf(x[i]).String())
-			return 0, fmt.Errorf("type not implemented. Implemented: string, int64, float64, time.Time")
+			return nil, fmt.Errorf("type not implemented. Implemented: string, int64, float64, time.Time")
 		}
-		z += math.Abs(z1 * w[i])
-		total += w[i]
+		ret[i] = math.Abs(z1)
 	}
-	return z / total, nil
+	return ret, nil
 }
 
 // sortParams is a function that returns the field and the direction of a command
@@ -235,13 +269,13 @@ func (c *Commands) fieldByTag(v interface{}, field string) string {
 }
 
 // transpose is a function that returns the transpose of a string
-func (c *Commands) transpose(data string, param *Param) (string, string, error) {
+func (c *Commands) transpose(data string, param *Param) (string, interface{}, error) {
 	if data == "" || param.transpose == "" {
-		return data, "", nil
+		return data, nil, nil
 	}
 	trs := strings.Split(param.transpose, ",")
 	if len(trs) != 2 {
-		return "", "", errors.New(ErrorTransposeStruct)
+		return "", nil, errors.New(ErrorTransposeStruct)
 	}
 	field := trs[0]
 	ftype := trs[1]
@@ -249,24 +283,41 @@ func (c *Commands) transpose(data string, param *Param) (string, string, error)
 	case "string":
 		return c.transposeString(data, field)
 	case "numeric":
-		return c.transposeNumeric(data, field)
+		return c.condition(c.transposeNumeric(data, field))
 	case "time":
-		return c.transposeTime(data, field)
+		return c.condition(c.transposeTime(data, field))
 	default:
-		return "", "", errors.New(ErrorTransposeType)
+		return "", nil, errors.New(ErrorTransposeType)
 	}
 }
 
+// condition is a function that returns the transposed condition or nil when there is none
+func (c *Commands) condition(data string, trs string, err error) (string, interface{}, error) {
+	if trs == "" {
+		return data, nil, err
+	}
+	return data, trs, err
+}
+
 // transposeString is a function that returns the transpose of a string
-func (c *Commands) transposeString(data string, field string) (string, string, error) {
+func (c *Commands) transposeString(data string, field string) (string, interface{}, error) {
 	if data == "cmd" {
 		data = "c*"
 	}
-	if !strings.Contains(data, "+") && !strings.Contains(data, "-") && !strings.Contains(data, "*") {
-		return data, "", nil
+	if cond := LikeCondition(field, data); cond != nil {
+		return "", cond, nil
+	}
+	return data, nil, nil
+}
+
+// LikeCondition returns the parameterized like condition of a field filtered by a value with the * wildcard
+// the other characters, like the hyphens of the ids, are matched literally. It returns nil without wildcard
+func LikeCondition(field, value string) *Condition {
+	if !strings.Contains(value, Wildcard) {
+		return nil
 	}
-	data = strings.ReplaceAll(data, "*", "%")
-	return "", fmt.Sprintf("%s like '%s'", field, data), nil
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+	return &Condition{Query: field + " like ?", Args: []interface{}{strings.ReplaceAll(escaped, Wildcard, "%")}}
 }
 
 // transposeFloat is a function that returns the transpose of a float
@@ -717,3 +768,54 @@ func (c *Commands) mountTable(table [][]string) string {
 	}
 	return ret
 }
+
+// mountCSV is a function that mounts a csv with the header on the first line
+func (c *Commands) mountCSV(table [][]string) string {
+	b := &strings.Builder{}
+	w := csv.NewWriter(b)
+	w.WriteAll(table)
+	return b.String()
+}
+
+// jsonLine is a line of the json output with the values keyed by the header in its order
+type jsonLine struct {
+	keys   []string
+	values []string
+}
+
+// MarshalJSON marshals the line as a json object keeping the order of the header
+func (l jsonLine) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range l.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(l.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// mountJSON is a function that mounts a json array with one object per line keyed by the header in its order
+func (c *Commands) mountJSON(table [][]string) string {
+	lines := []jsonLine{}
+	for _, line := range table[1:] {
+		lines = append(lines, jsonLine{keys: table[0], values: line})
+	}
+	ret, err := json.MarshalIndent(lines, "", "  ")
+	if err != nil {
+		return err.Error()
+	}
+	return string(ret) + "\n"
+}
@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDistances(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	x := []interface{}{"joao", at, "psico"}
+	y := []interface{}{"joan", at.Add(time.Hour), "psico"}
+	got, err := NewCommands().Distances(x, y)
+	if err != nil {
+		t.Fatalf("Distances() error = %v", err)
+	}
+	want := []float64{2, 3600, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Distances()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	score, err := NewCommands().WeightedDistance(x, y, []float64{100, 10, 1})
+	if err != nil {
+		t.Fatalf("WeightedDistance() error = %v", err)
+	}
+	if want := (200.0 + 36000.0) / 111.0; score != want {
+		t.Errorf("WeightedDistance() = %v, want %v", score, want)
+	}
+}
+
+func TestMarshalOutputs(t *testing.T) {
+	type row struct {
+		Name  string `command:"name:name"`
+		Value string `command:"name:value"`
+		Empty string `command:"name:empty"`
+	}
+	rows := []*row{{Name: "ana, maria", Value: "10.00"}, {Name: `say "hi"`, Value: "-2.50"}}
+	csv := NewCommands().Marshal(rows, "trim", "counter", OutputCSV)
+	if want := "name,value\n\"ana, maria\",10.00\n\"say \"\"hi\"\"\",-2.50\n"; csv != want {
+		t.Errorf("Marshal() csv = %q, want %q", csv, want)
+	}
+	json := NewCommands().Marshal(rows, "trim", "counter", OutputJSON)
+	want := "[\n  {\n    \"name\": \"ana, maria\",\n    \"value\": \"10.00\"\n  },\n" +
+		"  {\n    \"name\": \"say \\\"hi\\\"\",\n    \"value\": \"-2.50\"\n  }\n]\n"
+	if json != want {
+		t.Errorf("Marshal() json = %q, want %q", json, want)
+	}
+	csv = NewCommands().Marshal(rows, "counter", OutputCSV)
+	if want := "name,value,empty\n\"ana, maria\",10.00,\n\"say \"\"hi\"\"\",-2.50,\n"; csv != want {
+		t.Errorf("Marshal() csv without trim = %q, want %q", csv, want)
+	}
+	json = NewCommands().Marshal(rows[:1], "counter", OutputJSON)
+	if want := "[\n  {\n    \"name\": \"ana, maria\",\n    \"value\": \"10.00\",\n    \"empty\": \"\"\n  }\n]\n"; json != want {
+		t.Errorf("Marshal() json without trim = %q, want %q", json, want)
+	}
+}
+
+func TestLikeCondition(t *testing.T) {
+	if cond := LikeCondition("id", "ana-maria"); cond != nil {
+		t.Errorf("LikeCondition() = %v, want nil", cond)
+	}
+	cond := LikeCondition("id", "o'neil_10%*")
+	if cond == nil || cond.Query != "id like ?" || len(cond.Args) != 1 || cond.Args[0] != `o'neil\_10\%%` {
+		t.Errorf("LikeCondition() = %v", cond)
+	}
+	type filter struct {
+		ID string `command:"name:id;trans:id,string"`
+	}
+	f := &filter{ID: "ana*"}
+	trans, err := NewCommands().Transpose(f)
+	if err != nil || len(trans) != 1 || f.ID != "" {
+		t.Fatalf("Transpose() = %v, %v", trans, err)
+	}
+	if c, ok := trans[0].(*Condition); !ok || c.Args[0] != "ana%" {
+		t.Errorf("Transpose() = %v", trans[0])
+	}
+}
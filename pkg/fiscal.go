@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	// StateCodes maps the brazilian states to their IBGE codes
+	StateCodes = map[string]string{
+		"RO": "11", "AC": "12", "AM": "13", "RR": "14", "PA": "15", "AP": "16", "TO": "17",
+		"MA": "21", "PI": "22", "CE": "23", "RN": "24", "PB": "25", "PE": "26", "AL": "27", "SE": "28", "BA": "29",
+		"MG": "31", "ES": "32", "RJ": "33", "SP": "35",
+		"PR": "41", "SC": "42", "RS": "43",
+		"MS": "50", "MT": "51", "GO": "52", "DF": "53",
+	}
+	// cityCodeExceptions are the IBGE city codes created with an invalid check digit
+	cityCodeExceptions = []string{"2201919", "2201988", "2202251", "2611533", "3117836", "3152131", "4305871",
+		"5203939", "5203962"}
+)
+
+// FormatCEP returns the brazilian zip code on 00000-000 format
+func FormatCEP(cep string) (string, error) {
+	digits := strings.Join(regexp.MustCompile("[0-9]+").FindAllString(cep, -1), "")
+	if len(digits) != 8 || digits == "00000000" {
+		return "", errors.New(ErrInvalidZipCode)
+	}
+	return digits[:5] + "-" + digits[5:], nil
+}
+
+// ValidCityCode checks if the IBGE city code has seven digits, belongs to the state and has a valid check digit
+func ValidCityCode(code, state string) bool {
+	if !regexp.MustCompile(`^[0-9]{7}$`).MatchString(code) {
+		return false
+	}
+	if prefix, ok := StateCodes[strings.ToUpper(state)]; !ok || code[:2] != prefix {
+		return false
+	}
+	for _, e := range cityCodeExceptions {
+		if code == e {
+			return true
+		}
+	}
+	sum := 0
+	for i := 0; i < 6; i++ {
+		d := int(code[i]-'0') * (i%2 + 1)
+		sum += d/10 + d%10
+	}
+	return (10-sum%10)%10 == int(code[6]-'0')
+}
@@ -0,0 +1,48 @@
+package pkg
+
+import "testing"
+
+func TestFormatCEP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"01310100", "01310-100"},
+		{"01310-100", "01310-100"},
+		{"01.310-100", "01310-100"},
+	}
+	for _, tt := range tests {
+		got, err := FormatCEP(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("FormatCEP(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "1310100", "013101000", "00000-000"} {
+		if got, err := FormatCEP(in); err == nil {
+			t.Errorf("FormatCEP(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestValidCityCode(t *testing.T) {
+	tests := []struct {
+		code  string
+		state string
+		want  bool
+	}{
+		{"3550308", "SP", true},
+		{"3550308", "sp", true},
+		{"3304557", "RJ", true},
+		{"5300108", "DF", true},
+		{"2201919", "PI", true},
+		{"3550309", "SP", false},
+		{"3550308", "RJ", false},
+		{"355030", "SP", false},
+		{"3550308", "XX", false},
+	}
+	for _, tt := range tests {
+		if got := ValidCityCode(tt.code, tt.state); got != tt.want {
+			t.Errorf("ValidCityCode(%q, %q) = %v, want %v", tt.code, tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	journalDateFormat = "02012006"
+	journalAccount    = 20
+	journalValue      = 15
+	journalDocument   = 30
+	journalHistory    = 100
+)
+
+var (
+	// journalHeader is the header of the csv layout of the journal entries
+	journalHeader = []string{"date", "debit", "credit", "value", "document", "history"}
+)
+
+// JournalEntry represents a line of the accounting journal debiting an account and crediting another one
+type JournalEntry struct {
+	Date     time.Time
+	Debit    string
+	Credit   string
+	Value    float64
+	Document string
+	History  string
+}
+
+// ParseChart parses a chart of accounts mapping on the key=account,key=account format
+func ParseChart(spec string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" ||
+			len(strings.TrimSpace(kv[1])) > journalAccount {
+			return nil, fmt.Errorf(ErrInvalidChart, pair)
+		}
+		ret[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+	}
+	return ret, nil
+}
+
+// JournalCSV returns the journal entries on the csv layout with header
+func JournalCSV(entries []*JournalEntry) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	if err := w.Write(journalHeader); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		line := []string{e.Date.Format(DateFormat), e.Debit, e.Credit, fmt.Sprintf("%.2f", e.Value), e.Document,
+			e.History}
+		if err := w.Write(line); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// JournalFixed returns the journal entries on the fixed width layout, one entry by line with
+// the date as ddmmyyyy, the debit and credit accounts with 20 positions, the value in cents with 15 digits,
+// the document with 30 positions and the history with 100 positions without accents
+func JournalFixed(entries []*JournalEntry) []byte {
+	buf := &bytes.Buffer{}
+	for _, e := range entries {
+		cents := int64(math.Round(e.Value * 100))
+		fmt.Fprintf(buf, "%s%s%s%0*d%s%s\r\n", e.Date.Format(journalDateFormat), journalText(e.Debit, journalAccount),
+			journalText(e.Credit, journalAccount), journalValue, cents, journalText(e.Document, journalDocument),
+			journalText(e.History, journalHistory))
+	}
+	return buf.Bytes()
+}
+
+// journalText returns a text without accents and control characters padded or cut to a size
+func journalText(s string, size int) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, _ = transform.String(t, strings.TrimSpace(s))
+	s = regexp.MustCompile(`[^\x20-\x7E]`).ReplaceAllString(s, "")
+	if len(s) > size {
+		s = s[:size]
+	}
+	return fmt.Sprintf("%-*s", size, s)
+}
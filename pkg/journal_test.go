@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseChart(t *testing.T) {
+	chart, err := ParseChart(" receivable=1.1.2.01, Revenue.Psico = 3.1.1.02 ,")
+	if err != nil {
+		t.Fatalf("ParseChart() error = %v", err)
+	}
+	if chart["receivable"] != "1.1.2.01" || chart["revenue.psico"] != "3.1.1.02" || len(chart) != 2 {
+		t.Errorf("ParseChart() = %v", chart)
+	}
+	for _, spec := range []string{"receivable", "=1.1", "bank=", "bank=123456789012345678901"} {
+		if _, err := ParseChart(spec); err == nil {
+			t.Errorf("ParseChart(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+func TestJournalLayouts(t *testing.T) {
+	local, _ := time.LoadLocation(Location)
+	entries := []*JournalEntry{
+		{Date: time.Date(2024, 1, 15, 0, 0, 0, 0, local), Debit: "1.1.2.01", Credit: "3.1.1.01", Value: 150.5,
+			Document: "2024_01_john", History: "Sessão de psicologia, janeiro"},
+	}
+	body, err := JournalCSV(entries)
+	if err != nil {
+		t.Fatalf("JournalCSV() error = %v", err)
+	}
+	want := "date,debit,credit,value,document,history\n" +
+		"15/01/2024,1.1.2.01,3.1.1.01,150.50,2024_01_john,\"Sessão de psicologia, janeiro\"\n"
+	if string(body) != want {
+		t.Errorf("JournalCSV() = %q, want %q", body, want)
+	}
+	line := strings.TrimSuffix(string(JournalFixed(entries)), "\r\n")
+	if len(line) != 8+20+20+15+30+100 {
+		t.Fatalf("JournalFixed() line size = %d", len(line))
+	}
+	if line[:8] != "15012024" || strings.TrimSpace(line[8:28]) != "1.1.2.01" ||
+		strings.TrimSpace(line[28:48]) != "3.1.1.01" || line[48:63] != "000000000015050" ||
+		strings.TrimSpace(line[63:93]) != "2024_01_john" ||
+		strings.TrimSpace(line[93:]) != "Sessao de psicologia, janeiro" {
+		t.Errorf("JournalFixed() = %q", line)
+	}
+}
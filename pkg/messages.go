@@ -20,6 +20,10 @@ const (
 	RoleClient                   = "client"
 	RoleLiable                   = "liable"
 	RolePayer                    = "payer"
+	RoleGuardian                 = "guardian"
+	RoleEmergency                = "emergency"
+	RouteInvoice                 = "invoice"
+	RouteNotice                  = "notice"
 	ContactEmail                 = "e-mail"
 	ContactWhatsapp              = "whatsapp"
 	ContactAll                   = "all"
@@ -30,8 +34,12 @@ const (
 	RecurrenceCycleMonth         = "month"
 	RecurrenceCycleYear          = "year"
 	DefaultRecurrenceCycle       = RecurrenceCycleOnce
+	TaxRegimeMEI                 = "mei"
+	TaxRegimeSimples             = "simples"
+	TaxRegimePresumido           = "presumido"
+	TaxRegimeReal                = "real"
+	TaxRegimeExempt              = "isento"
 	DefaultDueDay                = "10"
-	DefaultSessionSequence       = "0"
 	BillingTypePrePaid           = "pre-paid"
 	BillingTypePosPaid           = "pos-paid"
 	BillingTypePosSession        = "pos-session"
@@ -51,6 +59,40 @@ const (
 	AgendaStatusCanceled         = "canceled"
 	AgendaStatusLocked           = "locked"
 	DefaultAgendaStatus          = AgendaStatusOpenned
+	BankFileOFX                  = "ofx"
+	BankFileCNAB240              = "cnab240"
+	BankFileCNAB400              = "cnab400"
+	PaymentStatusMatched         = "matched"
+	PaymentStatusAmbiguous       = "ambiguous"
+	PaymentStatusUnmatched       = "unmatched"
+	PaymentStatusRefund          = "refund"
+	PaymentDuplicated            = "duplicated"
+	CreditNoteStatusOpen         = "open"
+	CreditNoteStatusApplied      = "applied"
+	CreditNoteStatusRefunded     = "refunded"
+	LedgerInvoice                = "invoice"
+	LedgerCharges                = "charges"
+	LedgerCredit                 = "credit"
+	LedgerPayment                = "payment"
+	LedgerRefund                 = "refund"
+	LedgerMakeup                 = "makeup"
+	LedgerBalance                = "balance"
+	LedgerAging                  = "aging"
+	ReportByService              = "service"
+	ReportByClient               = "client"
+	ReportByPackage              = "package"
+	ReportByProfessional         = "professional"
+	ReportTotal                  = "total"
+	ReportUnassigned             = "unassigned"
+	ReportFlagNoShow             = "no-show"
+	AccountReceivable            = "receivable"
+	AccountRevenue               = "revenue"
+	AccountIssExpense            = "iss_expense"
+	AccountIssPayable            = "iss_payable"
+	AccountBank                  = "bank"
+	AccountCash                  = "cash"
+	DunningStatusSent            = "sent"
+	DunningStatusFailed          = "failed"
 	InvoiceStatusActive          = "active"
 	InvoiceStatusCanceled        = "canceled"
 	DefaultInvoiceStatus         = InvoiceStatusActive
@@ -89,6 +131,7 @@ const (
 	Location                     = "America/Sao_Paulo"
 	DateFormat                   = "02/01/2006"
 	MonthFormat                  = "01/2006"
+	Wildcard                     = "*"
 	DateTimeFormat               = "02/01/2006 15:04"
 	DateHourFormat               = "02/01/2006 15"
 	DefaultDateFormat            = "2006-01-02"
@@ -113,7 +156,6 @@ const (
 	ErrInvalidEmail              = "invalid email"
 	ErrLongEmail                 = "email should have at most 100"
 	ErrEmptyPhone                = "empty phone"
-	ErrLongPhone                 = "phone should have at most 20"
 	ErrInvalidPhone              = "invalid phone"
 	ErrEmptyContact              = "empty contact"
 	ErrLongContact               = "contact should have at most 20"
@@ -209,7 +251,7 @@ const (
 	ErrCsvAndGet                 = "csv and get are not allowed together"
 	ErrCsvAndParams              = "csv and params are not allowed together"
 	ErrEmptyDiscount             = "empty discount"
-	ErrInvalidDiscount           = "discount should be between 0 and 1"
+	ErrInvalidDiscount           = "invalid discount. Should be a positive value or a percentage between 0% and 100%"
 	ErrEmptyProcess              = "empty process"
 	ErrInvalidProcess            = "invalid process. Should be %s"
 	ErrEmptyMessage              = "empty message"
@@ -238,4 +280,225 @@ const (
 	ErrAgendaMultiple            = "multiples agendas found"
 	ErrAgendaClientMismatch      = "session and agenda client mismatch"
 	ErrIdOrAgendaNotFound        = "id or agenda not found"
+	ErrInvalidJobs               = "jobs should be numeric and between 1 and %d"
+	ErrWildcardOnly              = "%s filter should not match all registers"
+	ErrInvalidFrom               = "invalid from date. Should have %s format"
+	ErrInvalidTo                 = "invalid to date. Should have %s format"
+	ErrFromAfterTo               = "from date should be before to date"
+	ErrInvalidYes                = "%s should be yes if informed"
+	ErrSessionTieLimit           = "%d sessions would be processed, more than %d. Use proceed yes to confirm"
+	ErrOnlyConfirmOptions        = "reschedule and original are allowed only on confirm command"
+	ErrOriginalWithoutReschedule = "original is allowed only with reschedule yes"
+	ErrInvalidOriginal           = "invalid original agenda status. Should be %s"
+	ErrOnlyRecontractOptions     = "contract is allowed only on recontract command"
+	ErrRecontractWithoutContract = "contract should be informed on recontract command"
+	ErrOnlyTieExplain            = "explain is allowed only on tie command"
+	ErrCheckinParams             = "id, day or week should be informed"
+	ErrCheckinIDAndRange         = "id should not be informed with client, day or week"
+	ErrCheckinDayAndWeek         = "day and week should not be informed together"
+	ErrAgendaNotOpenned          = "agenda is not openned"
+	ErrAgendaAlreadyTied         = "agenda already has a session tied"
+	ErrRenumberWithoutClient     = "client should be informed on renumber command"
+	ErrNoAgendasToBill           = "no agendas to bill on the month"
+	ErrLongReason                = "reason should have at most 100 characters"
+	ErrEndBeforeStart            = "end date should not be before contract start"
+	ErrChangeBeforeStart         = "change date should be after contract start"
+	ErrContractLocked            = "contract is locked"
+	ErrContractEnded             = "contract already ended on %s"
+	ErrOriginNotFound            = "origin contract not found"
+	ErrSamePackage               = "contract already has the informed package"
+	ErrChangeAfterEnd            = "change date should not be after contract end"
+	ErrChangeAfterDone           = "contract has agendas done or tied to sessions after the change date"
+	ErrPackageItemNotFound       = "package item not found"
+	ErrContractPackageMismatch   = "contract and package mismatch"
+	ErrPercentOrIndex            = "percent or index should be informed, but not both"
+	ErrInvalidPercent            = "invalid percent. Should be numeric"
+	ErrIndexMonthMissing         = "index has no value for month %s"
+	ErrEmptyShare                = "empty share"
+	ErrInvalidShare              = "invalid share. Should be a positive value or a percentage between 0% and 100%"
+	ErrSharesOver100             = "percentage shares of the contract payers should not be over 100%"
+	ErrPayerIsClient             = "payer should be different from the contract client"
+	ErrInvalidContactRole        = "invalid role. Should be %s"
+	ErrInvoiceSendParams         = "id or month should be informed"
+	ErrNoInvoicesToSend          = "no invoices to send"
+	ErrNotifierNotConfigured     = "notifier not configured"
+	ErrInvalidZipCode            = "invalid zip code. Should have 8 digits"
+	ErrInvalidState              = "invalid state. Should be a brazilian state code"
+	ErrInvalidCityCode           = "invalid city code. Should be the IBGE code of a city of the state"
+	ErrIncompleteAddress         = "zip code, street, city and state should be informed on address"
+	ErrLongAddress               = "%s should have at most %d characters"
+	ErrInvalidMunicipalReg       = "municipal registration should have up to 15 digits"
+	ErrInvalidTaxRegime          = "invalid tax regime. Should be %s"
+	ErrTaxRegimeWithoutCNPJ      = "tax regime %s requires a cnpj document"
+	ErrXsdInvalid                = "invalid xsd schema"
+	ErrXsdUnexpected             = "unexpected element %s"
+	ErrXsdMissing                = "missing element %s"
+	ErrXsdNamespace              = "invalid namespace %s. Should be %s"
+	ErrXsdInvalidValue           = "invalid value of %s: %s"
+	ErrXsdFacet                  = "invalid value of %s: %s does not match %s %s"
+	ErrNfseNotConfigured         = "nfse issuer not configured. %s should be informed"
+	ErrInvalidIssRate            = "invalid iss rate %s. Should be a percent between 0 and 100"
+	ErrNoInvoicesToIssue         = "no invoices to issue"
+	ErrPixMerchant               = "pix merchant name and city should be informed"
+	ErrPixKey                    = "pix key or location url should be informed with up to 77 characters"
+	ErrPixAmount                 = "pix amount should not be negative"
+	ErrInvalidPix                = "invalid pix payload. Check its crc"
+	ErrPixNotConfigured          = "pix not configured. %s should be informed"
+	ErrNoInvoicesToPix           = "no invoices to generate pix"
+	ErrBankFileFormat            = "unknown bank file format. Should be ofx, cnab 240 or cnab 400"
+	ErrBankFileLine              = "invalid line %d of bank file: %s"
+	ErrLongReference             = "reference should have at most 150 characters"
+	ErrNoCreditsOnFile           = "no credits on bank file"
+	ErrInvalidDueDate            = "invalid due date format. Use %s"
+	ErrInvalidCharge             = "fine and interest should not be negative"
+	ErrNoOverdueInvoices         = "no overdue invoices"
+	ErrEmptyReason               = "empty reason"
+	ErrCreditNoteValue           = "credit note value should be greater than zero"
+	ErrCreditNoteOverValue       = "credit note value %.2f is over the value %.2f not credited yet"
+	ErrCreditNoteItem            = "invoice item %s is not of the invoice %s"
+	ErrCreditNoteAgenda          = "agenda %s is not billed on the invoice %s"
+	ErrCreditNoteRefundInvoice   = "credit notes are not allowed on refund invoices"
+	ErrCreditNoteCanceled        = "invoice %s is already canceled"
+	ErrInvalidCreditNoteStatus   = "invalid credit note status. Should be %s"
+	ErrCancelWithoutCreditNote   = "canceled invoice requires credit notes of its full value %.2f"
+	ErrInvalidOutput             = "invalid format. Should be %s"
+	ErrInvalidImage              = "invalid image. Should be png or jpeg"
+	ErrInvalidTemplate           = "invalid invoice template: %s"
+	ErrLongProfessional          = "professional should have at most 50 characters"
+	ErrInvalidProfessional       = "professional should have just one word. Use _ to separate words"
+	ErrInvalidReportBy           = "invalid by. Should be %s"
+	ErrInvalidFromMonth          = "invalid from month. Should have %s format"
+	ErrInvalidToMonth            = "invalid to month. Should have %s format"
+	ErrInvalidAtDate             = "invalid at date. Should have %s format"
+	ErrInvalidChart              = "invalid chart of accounts mapping %s. Should be key=account with accounts up to 20 characters"
+	ErrMissingAccount            = "account %s not mapped on the chart of accounts"
+	ErrLongSuccessorID           = "successor contract id %s should have at most 50 characters"
+	ConfigMysqlDNS               = "MYSQL_DNS"
+	ConfigLogOutput              = "LOG_OUTPUT"
+	ConfigSessionTieJobs         = "SESSION_TIE_JOBS"
+	ConfigSessionTieLimit        = "SESSION_TIE_LIMIT"
+	ConfigSessionTieWeights      = "SESSION_TIE_WEIGHTS"
+	ConfigSessionTieWindow       = "SESSION_TIE_WINDOW"
+	ConfigSessionTieTolerance    = "SESSION_TIE_TOLERANCE"
+	ConfigSessionSequenceScope   = "SESSION_SEQUENCE_SCOPE"
+	ConfigNotifyOutbox           = "NOTIFY_OUTBOX"
+	ConfigNfseIssuerCNPJ         = "NFSE_ISSUER_CNPJ"
+	ConfigNfseIssuerIM           = "NFSE_ISSUER_IM"
+	ConfigNfseCityCode           = "NFSE_CITY_CODE"
+	ConfigNfseServiceCode        = "NFSE_SERVICE_CODE"
+	ConfigNfseIssRate            = "NFSE_ISS_RATE"
+	ConfigNfseSimples            = "NFSE_SIMPLES"
+	ConfigNfseRpsSeries          = "NFSE_RPS_SERIES"
+	ConfigNfseOutputDir          = "NFSE_OUTPUT_DIR"
+	ConfigPixKey                 = "PIX_KEY"
+	ConfigPixURL                 = "PIX_URL"
+	ConfigPixName                = "PIX_NAME"
+	ConfigPixCity                = "PIX_CITY"
+	ConfigPixQRDir               = "PIX_QR_DIR"
+	ConfigPixQRSize              = "PIX_QR_SIZE"
+	ConfigPaymentMatchWindow     = "PAYMENT_MATCH_WINDOW"
+	ConfigPaymentMatchWeights    = "PAYMENT_MATCH_WEIGHTS"
+	ConfigLateFinePercent        = "LATE_FINE_PERCENT"
+	ConfigLateInterestPercent    = "LATE_INTEREST_PERCENT"
+	ConfigDunningSteps           = "DUNNING_STEPS"
+	ConfigNoShowThreshold        = "NOSHOW_THRESHOLD"
+	ConfigAccountingChart        = "ACCOUNTING_CHART"
+	ConfigAccountingOutputDir    = "ACCOUNTING_OUTPUT_DIR"
+	OutputTable                  = "table"
+	OutputCSV                    = "csv"
+	OutputJSON                   = "json"
+	ConfigBusinessName           = "BUSINESS_NAME"
+	ConfigBusinessDocument       = "BUSINESS_DOCUMENT"
+	ConfigBusinessAddress        = "BUSINESS_ADDRESS"
+	ConfigBusinessPhone          = "BUSINESS_PHONE"
+	ConfigBusinessEmail          = "BUSINESS_EMAIL"
+	ConfigBusinessLogo           = "BUSINESS_LOGO"
+	ConfigInvoiceTemplateDir     = "INVOICE_TEMPLATE_DIR"
+	ConfigInvoiceOutputDir       = "INVOICE_OUTPUT_DIR"
+	ConfigInvoiceInstructions    = "INVOICE_INSTRUCTIONS"
+	ConfigInvoiceAttach          = "INVOICE_ATTACH"
+	LogOutputStderr              = "stderr"
+	DefaultNotifyOutbox          = "outbox.log"
+	DefaultNfseSimples           = 2
+	DefaultNfseRpsSeries         = "1"
+	DefaultNfseOutputDir         = "nfse"
+	DefaultPixQRDir              = "pix"
+	DefaultPixQRSize             = 256
+	DefaultPaymentMatchWindow    = 60
+	DefaultPaymentMatchWeights   = "10,1,1"
+	DefaultLateFinePercent       = 2.0
+	DefaultLateInterestPercent   = 1.0
+	DefaultDunningSteps          = "1,7,15"
+	DefaultInvoiceOutputDir      = "invoices"
+	DefaultInvoiceRender         = RenderPDF
+	DefaultReportBy              = ReportByService
+	DefaultForecastMonths        = 3
+	DefaultAttendanceBy          = ReportByClient
+	DefaultNoShowThreshold       = 20.0
+	DefaultAccountingChart       = "receivable=1.1.2.01,revenue=3.1.1.01,iss_expense=3.2.1.01,iss_payable=2.1.3.01,bank=1.1.1.02,cash=1.1.1.01"
+	DefaultAccountingOutputDir   = "accounting"
+	DefaultSessionTieJobs        = 1
+	MaxSessionTieJobs            = 32
+	DefaultSessionTieLimit       = 200
+	DefaultSessionTieWeights     = "100,10,1"
+	DefaultSessionTieWindow      = 60
+	DefaultSessionTieTolerance   = 0.0
+	ExplainChosen                = "chosen"
+	ExplainWorseScore            = "worse score"
+	ExplainLockedOtherDay        = "locked on another day"
+	ExplainAboveTolerance        = "above tolerance"
+	ExplainKindMismatch          = "kind mismatch"
+	Yes                          = "yes"
+	DefaultOriginalAgendaStatus  = AgendaStatusCanceled
+	SessionTieProgressSteps      = 10
+	SequenceScopeClient          = "client"
+	SequenceScopeContract        = "contract"
+	DefaultSequenceScope         = SequenceScopeClient
+	SequenceOk                   = "ok"
+	SequenceGap                  = "gap"
+	SequenceDuplicate            = "duplicate"
+	SequenceRenumbered           = "renumbered from %d"
+	RefundDescription            = "refund"
+	ChangeDescription            = "change %s to %s %s"
+	ReadjustReason               = "readjust %s"
+	ReadjustPercent              = "%.2f%%"
+	ReadjustIndex                = "index %.4f%%"
+	ReadjustDone                 = "readjusted"
+	ReadjustAlready              = "already readjusted"
+	ReadjustNotAnniversary       = "less than one year"
+	ReadjustNoPrice              = "no price to readjust"
+	ShareDescription             = "%s (%s share)"
+	InvoicePixMessage            = ". Pay with PIX copia e cola: %s"
+	DunningSubject               = "overdue invoices of %s"
+	DunningMessage               = "Dear %s, the invoice %s of %s with the value of %.2f is %d days overdue since %s, with fine of %.2f and interest of %.2f"
+	DunningResult                = "d+%d %s"
+	CreditNoteDescription        = "credit note %s"
+	StatementOpening             = "opening balance"
+	StatementClosing             = "closing balance"
+	StatementCharges             = "late charges of invoice %s"
+	StatementPayment             = "payment of invoice %s"
+	StatementRefund              = "refund of invoice %s"
+	StatementMakeup              = "make-up credit of %s on %s"
+	StatementMakeups             = "%d make-up credits pending"
+	StatementAgingCurrent        = "not due"
+	StatementAgingRange          = "%d to %d days overdue"
+	StatementAgingOver           = "over %d days overdue"
+	JournalInvoice               = "invoice %s - %s"
+	JournalIss                   = "iss of invoice %s"
+	JournalCreditNote            = "credit note %s of invoice %s"
+	JournalPayment               = "payment %s of invoice %s"
+	JournalRefund                = "refund %s of invoice %s"
+	InvoiceInstructions          = "Please pay the total until the due date informing the invoice %s as reference"
+	InvoiceInstructionsPix       = "Please pay the total until the due date with PIX reading the QR code or copying the code below"
+	NfseGenerated                = "generated"
+	NfseSkippedNoValue           = "skipped: no value"
+	NfseSkippedInvalid           = "skipped by the layout check: %s"
+	NfseDiscrimination           = "%s - %.2f"
+	InvoiceSubject               = "invoice %s"
+	InvoiceMessage               = "Dear %s, the invoice %s of %s has the value of %.2f"
+	PhoneOk                      = "ok"
+	PhoneNormalized              = "normalized"
+	PhoneInvalid                 = "invalid"
+	ChangeReason                 = "changed to %s"
+	MsgSessionTieProgress        = "session %s: %d of %d sessions processed"
 )
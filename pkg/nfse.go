@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"sync"
+)
+
+const (
+	// NfseNamespace is the namespace of the ABRASF NFS-e documents
+	NfseNamespace = "http://www.abrasf.org.br/nfse.xsd"
+	// NfseVersion is the version of the ABRASF NFS-e layout
+	NfseVersion = "2.02"
+)
+
+var (
+	//go:embed schema/nfse_v202_subset.xsd
+	nfseXsd []byte
+	// nfseLayout is the parsed subset of the NFS-e layout loaded on first use
+	nfseLayout     *Schema
+	nfseLayoutErr  error
+	nfseLayoutOnce sync.Once
+)
+
+// NfseGerarEnvio is the ABRASF document to generate a single NFS-e from a RPS
+type NfseGerarEnvio struct {
+	XMLName xml.Name                `xml:"http://www.abrasf.org.br/nfse.xsd GerarNfseEnvio"`
+	Rps     NfseDeclaracaoPrestacao `xml:"Rps"`
+}
+
+// NfseLoteEnvio is the ABRASF document to send a batch of RPS
+type NfseLoteEnvio struct {
+	XMLName xml.Name `xml:"http://www.abrasf.org.br/nfse.xsd EnviarLoteRpsEnvio"`
+	LoteRps NfseLote `xml:"LoteRps"`
+}
+
+// NfseLote represents a batch of RPS
+type NfseLote struct {
+	ID                 string                    `xml:"Id,attr,omitempty"`
+	Versao             string                    `xml:"versao,attr"`
+	NumeroLote         int64                     `xml:"NumeroLote"`
+	CpfCnpj            NfseCpfCnpj               `xml:"CpfCnpj"`
+	InscricaoMunicipal string                    `xml:"InscricaoMunicipal,omitempty"`
+	QuantidadeRps      int                       `xml:"QuantidadeRps"`
+	ListaRps           []NfseDeclaracaoPrestacao `xml:"ListaRps>Rps"`
+}
+
+// NfseDeclaracaoPrestacao wraps the declaration of a service provided
+type NfseDeclaracaoPrestacao struct {
+	Inf NfseInfDeclaracao `xml:"InfDeclaracaoPrestacaoServico"`
+}
+
+// NfseInfDeclaracao represents the declaration of a service provided on a RPS
+type NfseInfDeclaracao struct {
+	ID                     string            `xml:"Id,attr,omitempty"`
+	Rps                    *NfseRps          `xml:"Rps,omitempty"`
+	Competencia            string            `xml:"Competencia"`
+	Servico                NfseServico       `xml:"Servico"`
+	Prestador              NfseIdentificacao `xml:"Prestador"`
+	Tomador                *NfseTomador      `xml:"Tomador,omitempty"`
+	OptanteSimplesNacional int               `xml:"OptanteSimplesNacional"`
+	IncentivoFiscal        int               `xml:"IncentivoFiscal"`
+}
+
+// NfseRps represents the identification of a RPS
+type NfseRps struct {
+	Numero      int64  `xml:"IdentificacaoRps>Numero"`
+	Serie       string `xml:"IdentificacaoRps>Serie"`
+	Tipo        int    `xml:"IdentificacaoRps>Tipo"`
+	DataEmissao string `xml:"DataEmissao"`
+	Status      int    `xml:"Status"`
+}
+
+// NfseServico represents the service provided and its values
+type NfseServico struct {
+	ValorServicos       string `xml:"Valores>ValorServicos"`
+	ValorIss            string `xml:"Valores>ValorIss,omitempty"`
+	Aliquota            string `xml:"Valores>Aliquota,omitempty"`
+	IssRetido           int    `xml:"IssRetido"`
+	ItemListaServico    string `xml:"ItemListaServico"`
+	Discriminacao       string `xml:"Discriminacao"`
+	CodigoMunicipio     string `xml:"CodigoMunicipio"`
+	ExigibilidadeISS    int    `xml:"ExigibilidadeISS"`
+	MunicipioIncidencia string `xml:"MunicipioIncidencia,omitempty"`
+}
+
+// NfseIdentificacao represents the identification of the provider or the taker of the service
+type NfseIdentificacao struct {
+	CpfCnpj            NfseCpfCnpj `xml:"CpfCnpj"`
+	InscricaoMunicipal string      `xml:"InscricaoMunicipal,omitempty"`
+}
+
+// NfseCpfCnpj represents a brazilian person or company document
+type NfseCpfCnpj struct {
+	Cpf  string `xml:"Cpf,omitempty"`
+	Cnpj string `xml:"Cnpj,omitempty"`
+}
+
+// NfseTomador represents the taker of the service
+type NfseTomador struct {
+	IdentificacaoTomador *NfseIdentificacao `xml:"IdentificacaoTomador,omitempty"`
+	RazaoSocial          string             `xml:"RazaoSocial,omitempty"`
+	Endereco             *NfseEndereco      `xml:"Endereco,omitempty"`
+	Contato              *NfseContato       `xml:"Contato,omitempty"`
+}
+
+// NfseEndereco represents the address of the taker of the service
+type NfseEndereco struct {
+	Endereco        string `xml:"Endereco,omitempty"`
+	Numero          string `xml:"Numero,omitempty"`
+	Complemento     string `xml:"Complemento,omitempty"`
+	Bairro          string `xml:"Bairro,omitempty"`
+	CodigoMunicipio string `xml:"CodigoMunicipio,omitempty"`
+	Uf              string `xml:"Uf,omitempty"`
+	Cep             string `xml:"Cep,omitempty"`
+}
+
+// NfseContato represents the contact of the taker of the service
+type NfseContato struct {
+	Telefone string `xml:"Telefone,omitempty"`
+	Email    string `xml:"Email,omitempty"`
+}
+
+// MarshalNfse returns the xml of a NFS-e document with the xml header and checks it against the bundled layout subset
+func MarshalNfse(doc any) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	ret := append([]byte(xml.Header), body...)
+	if err := CheckNfseLayout(ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CheckNfseLayout checks a NFS-e document against the bundled subset of the ABRASF 2.02 layout
+// it is not a validation against the official ABRASF schemas: the subset was written here with just the elements
+// generated and their types and limits, and it is read by the subset of the xsd language supported by Schema.
+// It catches the order of the elements and the field limits locally, but the municipal web service remains
+// the only validation of the documents
+func CheckNfseLayout(doc []byte) error {
+	nfseLayoutOnce.Do(func() {
+		nfseLayout, nfseLayoutErr = NewSchema(nfseXsd)
+	})
+	if nfseLayoutErr != nil {
+		return nfseLayoutErr
+	}
+	return nfseLayout.Validate(doc)
+}
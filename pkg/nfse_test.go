@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func testNfseDeclaracao() NfseDeclaracaoPrestacao {
+	return NfseDeclaracaoPrestacao{Inf: NfseInfDeclaracao{
+		ID:          "rps1",
+		Rps:         &NfseRps{Numero: 1, Serie: "A", Tipo: 1, DataEmissao: "2024-02-01", Status: 1},
+		Competencia: "2024-01-01",
+		Servico: NfseServico{ValorServicos: "500.00", ValorIss: "25.00", Aliquota: "5.00", IssRetido: 2,
+			ItemListaServico: "4.08", Discriminacao: "Sessions of January", CodigoMunicipio: "3550308",
+			ExigibilidadeISS: 1, MunicipioIncidencia: "3550308"},
+		Prestador: NfseIdentificacao{CpfCnpj: NfseCpfCnpj{Cnpj: "11222333000181"}, InscricaoMunicipal: "12345"},
+		Tomador: &NfseTomador{
+			IdentificacaoTomador: &NfseIdentificacao{CpfCnpj: NfseCpfCnpj{Cpf: "52998224725"}},
+			RazaoSocial:          "John Doe",
+			Endereco: &NfseEndereco{Endereco: "Avenida Paulista", Numero: "1000", CodigoMunicipio: "3550308",
+				Uf: "SP", Cep: "01310100"},
+		},
+		OptanteSimplesNacional: 2,
+		IncentivoFiscal:        2,
+	}}
+}
+
+func TestMarshalNfse(t *testing.T) {
+	doc, err := MarshalNfse(&NfseGerarEnvio{Rps: testNfseDeclaracao()})
+	if err != nil {
+		t.Fatalf("MarshalNfse() error = %v", err)
+	}
+	if !strings.Contains(string(doc), `<GerarNfseEnvio xmlns="`+NfseNamespace+`">`) {
+		t.Errorf("MarshalNfse() = %s, want GerarNfseEnvio root", doc)
+	}
+	lote := &NfseLoteEnvio{LoteRps: NfseLote{ID: "lote1", Versao: NfseVersion, NumeroLote: 1,
+		CpfCnpj: NfseCpfCnpj{Cnpj: "11222333000181"}, QuantidadeRps: 2,
+		ListaRps: []NfseDeclaracaoPrestacao{testNfseDeclaracao(), testNfseDeclaracao()}}}
+	if _, err := MarshalNfse(lote); err != nil {
+		t.Errorf("MarshalNfse() batch error = %v", err)
+	}
+	wrong := testNfseDeclaracao()
+	wrong.Inf.Servico.ItemListaServico = "408"
+	if _, err := MarshalNfse(&NfseGerarEnvio{Rps: wrong}); err == nil {
+		t.Errorf("MarshalNfse() with invalid service code error = nil, want error")
+	}
+	wrong = testNfseDeclaracao()
+	wrong.Inf.Prestador.CpfCnpj = NfseCpfCnpj{}
+	if _, err := MarshalNfse(&NfseGerarEnvio{Rps: wrong}); err == nil {
+		t.Errorf("MarshalNfse() without provider document error = nil, want error")
+	}
+	wrong = testNfseDeclaracao()
+	wrong.Inf.Tomador.Endereco.Numero = "12345678901"
+	if _, err := MarshalNfse(&NfseGerarEnvio{Rps: wrong}); err == nil {
+		t.Errorf("MarshalNfse() with address number over 10 characters error = nil, want error")
+	}
+	wrong = testNfseDeclaracao()
+	wrong.Inf.Prestador.InscricaoMunicipal = "1234567890123456"
+	if _, err := MarshalNfse(&NfseGerarEnvio{Rps: wrong}); err == nil {
+		t.Errorf("MarshalNfse() with municipal registration over 15 digits error = nil, want error")
+	}
+}
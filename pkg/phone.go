@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+const (
+	phoneCountryBR = "55"
+	phoneRegionBR  = "BR"
+)
+
+// NormalizePhone returns the phone in canonical E.164 format accepting the usual ways numbers are typed
+// numbers without country code are brazilian, the trunk and carrier prefixes are removed and
+// brazilian mobiles typed without the 9th digit receive it
+func NormalizePhone(phone string) (string, error) {
+	international := strings.HasPrefix(strings.TrimSpace(phone), "+")
+	digits := strings.Join(regexp.MustCompile("[0-9]+").FindAllString(phone, -1), "")
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+	if !international {
+		if strings.HasPrefix(digits, "0") {
+			digits = digits[1:]
+			if len(digits) == 12 || len(digits) == 13 {
+				digits = digits[2:]
+			}
+		}
+		if len(digits) == 10 || len(digits) == 11 {
+			digits = phoneCountryBR + digits
+		}
+	}
+	if strings.HasPrefix(digits, phoneCountryBR) && len(digits) == 12 && digits[4] >= '6' {
+		digits = digits[:4] + "9" + digits[4:]
+	}
+	number, err := phonenumbers.Parse("+"+digits, phoneRegionBR)
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return "", errors.New(ErrInvalidPhone)
+	}
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// FormatPhone returns the phone formatted for display, national for brazilian numbers and international otherwise
+// It returns the phone as it is if it can not be parsed
+func FormatPhone(phone string) string {
+	if phone == "" {
+		return phone
+	}
+	number, err := phonenumbers.Parse(phone, phoneRegionBR)
+	if err != nil {
+		return phone
+	}
+	if phonenumbers.GetRegionCodeForNumber(number) == phoneRegionBR {
+		return phonenumbers.Format(number, phonenumbers.NATIONAL)
+	}
+	return phonenumbers.Format(number, phonenumbers.INTERNATIONAL)
+}
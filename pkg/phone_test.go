@@ -0,0 +1,53 @@
+package pkg
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"(11) 98765-4321", "+5511987654321"},
+		{"11 8765-4321", "+5511987654321"},
+		{"+55 11 98765-4321", "+5511987654321"},
+		{"5511987654321", "+5511987654321"},
+		{"551187654321", "+5511987654321"},
+		{"0 21 11 98765-4321", "+5511987654321"},
+		{"011 98765-4321", "+5511987654321"},
+		{"(11) 3456-7890", "+551134567890"},
+		{"0055 11 3456-7890", "+551134567890"},
+		{"+1 650-253-0000", "+16502530000"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhone(tt.in)
+		if err != nil {
+			t.Errorf("NormalizePhone(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "1234", "98765-4321", "abc"} {
+		if got, err := NormalizePhone(in); err == nil {
+			t.Errorf("NormalizePhone(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestFormatPhone(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"+5511987654321", "(11) 98765-4321"},
+		{"+551134567890", "(11) 3456-7890"},
+		{"+16502530000", "+1 650-253-0000"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := FormatPhone(tt.in); got != tt.want {
+			t.Errorf("FormatPhone(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
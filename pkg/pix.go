@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	pixGUI          = "br.gov.bcb.pix"
+	pixCurrency     = "986"
+	pixCountry      = "BR"
+	pixCategory     = "0000"
+	pixDynamicTxID  = "***"
+	pixMaxName      = 25
+	pixMaxCity      = 15
+	pixMaxTxID      = 25
+	pixMaxKey       = 77
+	pixMaxURL       = 77
+	pixCRCField     = "6304"
+	pixPointDynamic = "12"
+)
+
+// Pix represents the data of a PIX BR Code payment
+// a static code informs the key of the merchant and a dynamic one the location url provided by the PSP
+type Pix struct {
+	Key    string
+	URL    string
+	Name   string
+	City   string
+	TxID   string
+	Amount float64
+}
+
+// Payload returns the PIX BR Code "copia e cola" payload following the EMV QRCPS MPM with CRC16
+func (p *Pix) Payload() (string, error) {
+	name := p.text(p.Name, pixMaxName)
+	city := p.text(p.City, pixMaxCity)
+	if name == "" || city == "" {
+		return "", errors.New(ErrPixMerchant)
+	}
+	account := p.field("00", pixGUI)
+	txid := PixTxID(p.TxID)
+	switch {
+	case p.URL != "":
+		url := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(p.URL), "https://"), "http://")
+		if len(url) > pixMaxURL {
+			return "", errors.New(ErrPixKey)
+		}
+		account += p.field("25", url)
+		txid = pixDynamicTxID
+	case p.Key != "":
+		key := strings.TrimSpace(p.Key)
+		if len(key) > pixMaxKey {
+			return "", errors.New(ErrPixKey)
+		}
+		account += p.field("01", key)
+	default:
+		return "", errors.New(ErrPixKey)
+	}
+	if txid == "" {
+		txid = pixDynamicTxID
+	}
+	if p.Amount < 0 {
+		return "", errors.New(ErrPixAmount)
+	}
+	payload := p.field("00", "01")
+	if p.URL != "" {
+		payload += p.field("01", pixPointDynamic)
+	}
+	payload += p.field("26", account) + p.field("52", pixCategory) + p.field("53", pixCurrency)
+	if p.Amount > 0 {
+		payload += p.field("54", fmt.Sprintf("%.2f", p.Amount))
+	}
+	payload += p.field("58", pixCountry) + p.field("59", name) + p.field("60", city) +
+		p.field("62", p.field("05", txid)) + pixCRCField
+	return payload + fmt.Sprintf("%04X", crc16(payload)), nil
+}
+
+// field returns an EMV field with its id, size and value
+func (p *Pix) field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// text returns a text without accents and with just the characters accepted by the BR Code up to a size
+func (p *Pix) text(s string, size int) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, _ = transform.String(t, strings.TrimSpace(s))
+	s = regexp.MustCompile(`[^A-Za-z0-9 .,\-/]`).ReplaceAllString(s, "")
+	if len(s) > size {
+		s = strings.TrimSpace(s[:size])
+	}
+	return s
+}
+
+// PixTxID returns the transaction id of a PIX BR Code from an id keeping just its alphanumeric characters
+func PixTxID(id string) string {
+	txid := regexp.MustCompile(`[^A-Za-z0-9]`).ReplaceAllString(id, "")
+	if len(txid) > pixMaxTxID {
+		txid = txid[:pixMaxTxID]
+	}
+	return txid
+}
+
+// ValidPixPayload checks the CRC16 of a PIX BR Code payload
+func ValidPixPayload(payload string) bool {
+	if len(payload) < len(pixCRCField)+4 || payload[len(payload)-8:len(payload)-4] != pixCRCField {
+		return false
+	}
+	return fmt.Sprintf("%04X", crc16(payload[:len(payload)-4])) == strings.ToUpper(payload[len(payload)-4:])
+}
+
+// PixQRCode renders a PIX BR Code payload as a PNG QR code image with the size in pixels
+func PixQRCode(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+// crc16 returns the CRC16 CCITT-FALSE of a text used by the BR Code
+func crc16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPixPayload(t *testing.T) {
+	pix := &Pix{Key: "123e4567-e12b-12d1-a456-426655440000", Name: "Fulano de Tal", City: "BRASILIA"}
+	want := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR" +
+		"5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	if got, err := pix.Payload(); err != nil || got != want {
+		t.Errorf("Payload() = %q, %v, want %q", got, err, want)
+	}
+	pix = &Pix{Key: "+5511999999999", Name: "Clínica São João Ltda Psicologia", City: "São Paulo",
+		TxID: "2024_01_john", Amount: 150.5}
+	got, err := pix.Payload()
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+	for _, part := range []string{"5406150.50", "5925Clinica Sao Joao Ltda Psi", "6009Sao Paulo", "0510202401john"} {
+		if !strings.Contains(got, part) {
+			t.Errorf("Payload() = %q, want to contain %q", got, part)
+		}
+	}
+	if !ValidPixPayload(got) {
+		t.Errorf("ValidPixPayload(%q) = false, want true", got)
+	}
+	pix = &Pix{URL: "https://pix.example.com/qr/v2/9d36b84f", Name: "Fulano", City: "Rio", TxID: "x"}
+	if got, err := pix.Payload(); err != nil || !strings.Contains(got, "010212") ||
+		!strings.Contains(got, "2530pix.example.com/qr/v2/9d36b84f") || !strings.Contains(got, "0503***") {
+		t.Errorf("Payload() dynamic = %q, %v", got, err)
+	}
+	for _, p := range []*Pix{{Name: "a", City: "b"}, {Key: "k", City: "b"}, {Key: "k", Name: "a", City: "b", Amount: -1}} {
+		if got, err := p.Payload(); err == nil {
+			t.Errorf("Payload(%+v) = %q, want error", p, got)
+		}
+	}
+}
+
+func TestValidPixPayload(t *testing.T) {
+	payload := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR" +
+		"5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	if !ValidPixPayload(payload) {
+		t.Errorf("ValidPixPayload() = false, want true")
+	}
+	for _, p := range []string{"", payload[:len(payload)-1] + "E", strings.Replace(payload, "Fulano", "Ciclano", 1)} {
+		if ValidPixPayload(p) {
+			t.Errorf("ValidPixPayload(%q) = true, want false", p)
+		}
+	}
+}
+
+func TestPixQRCode(t *testing.T) {
+	png, err := PixQRCode("00020126580014br.gov.bcb.pix0136123e4567", 128)
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("PixQRCode() = %d bytes, %v, want png", len(png), err)
+	}
+}
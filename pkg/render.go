@@ -0,0 +1,218 @@
+package pkg
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	// RenderHTML is the html format of a rendered invoice
+	RenderHTML = "html"
+	// RenderPDF is the pdf format of a rendered invoice
+	RenderPDF = "pdf"
+	// InvoiceTemplateFormat is the name of the invoice template file of a format
+	InvoiceTemplateFormat = "invoice.%s.tmpl"
+
+	pdfMargin      = 15.0
+	pdfLine        = 5.0
+	pdfValueWidth  = 35.0
+	pdfLogoWidth   = 40.0
+	pdfQRCodeWidth = 45.0
+)
+
+var (
+	//go:embed template/invoice.html.tmpl template/invoice.pdf.tmpl
+	invoiceTemplates embed.FS
+	// imageTypes are the pdf image types by content type of the images allowed on the templates
+	imageTypes = map[string]string{"image/png": "PNG", "image/jpeg": "JPG"}
+)
+
+// InvoiceView represents the data of an invoice available to the render templates
+type InvoiceView struct {
+	ID           string
+	Date         string
+	Due          string
+	Business     InvoiceParty
+	Client       InvoiceParty
+	Items        []InvoiceViewItem
+	Charges      string
+	Credits      string
+	Paid         string
+	Total        string
+	Instructions string
+	Pix          string
+	Logo         []byte
+	QRCode       []byte
+}
+
+// InvoiceParty represents the business issuing the invoice or the client billed
+type InvoiceParty struct {
+	Name     string
+	Document string
+	Address  string
+	Phone    string
+	Email    string
+}
+
+// InvoiceViewItem represents an item of the invoice
+type InvoiceViewItem struct {
+	Description string
+	Value       string
+}
+
+// DefaultInvoiceTemplate returns the bundled invoice template of a format
+func DefaultInvoiceTemplate(format string) (string, error) {
+	data, err := invoiceTemplates.ReadFile("template/" + fmt.Sprintf(InvoiceTemplateFormat, format))
+	if err != nil {
+		return "", fmt.Errorf(ErrInvalidOutput, strings.Join([]string{RenderPDF, RenderHTML}, ", "))
+	}
+	return string(data), nil
+}
+
+// RenderInvoice renders an invoice view with a template of the html or pdf format
+func RenderInvoice(format, tmpl string, view *InvoiceView) ([]byte, error) {
+	if err := ValidImage(view.Logo); err != nil {
+		return nil, err
+	}
+	switch format {
+	case RenderHTML:
+		return renderHTML(tmpl, view)
+	case RenderPDF:
+		return renderPDF(tmpl, view)
+	}
+	return nil, fmt.Errorf(ErrInvalidOutput, strings.Join([]string{RenderPDF, RenderHTML}, ", "))
+}
+
+// ValidImage checks if an image informed is a png or a jpeg one
+func ValidImage(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if _, ok := imageTypes[http.DetectContentType(data)]; !ok {
+		return errors.New(ErrInvalidImage)
+	}
+	return nil
+}
+
+// renderHTML renders an invoice view with a html template escaping its values
+func renderHTML(tmpl string, view *InvoiceView) ([]byte, error) {
+	t, err := htmltemplate.New("invoice").Funcs(htmltemplate.FuncMap{
+		"dataURI": func(data []byte) htmltemplate.URL {
+			return htmltemplate.URL("data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data))
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidTemplate, err.Error())
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, view); err != nil {
+		return nil, fmt.Errorf(ErrInvalidTemplate, err.Error())
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPDF renders an invoice view with a pdf template where each line is one element of the page:
+// [logo] and [qrcode] images, # title, ## section, | table | row | with the first row as header,
+// --- rule, > small text or plain text. Blank lines are vertical spaces
+func renderPDF(tmpl string, view *InvoiceView) ([]byte, error) {
+	t, err := texttemplate.New("invoice").Funcs(texttemplate.FuncMap{
+		"cell": func(s string) string {
+			return strings.ReplaceAll(s, "|", "/")
+		},
+	}).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidTemplate, err.Error())
+	}
+	text := &bytes.Buffer{}
+	if err := t.Execute(text, view); err != nil {
+		return nil, fmt.Errorf(ErrInvalidTemplate, err.Error())
+	}
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width, _ := pdf.GetPageSize()
+	width -= 2 * pdfMargin
+	header := true
+	for _, line := range strings.Split(text.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			header = true
+		}
+		switch {
+		case line == "":
+			pdf.Ln(pdfLine)
+		case line == "[logo]":
+			pdfImage(pdf, "logo", view.Logo, pdfLogoWidth)
+		case line == "[qrcode]":
+			pdfImage(pdf, "qrcode", view.QRCode, pdfQRCodeWidth)
+		case line == "---":
+			y := pdf.GetY() + 1
+			pdf.Line(pdfMargin, y, pdfMargin+width, y)
+			pdf.Ln(3)
+		case strings.HasPrefix(line, "## "):
+			pdf.SetFont("Helvetica", "B", 12)
+			pdf.MultiCell(width, pdfLine+2, tr(strings.TrimPrefix(line, "## ")), "", "L", false)
+		case strings.HasPrefix(line, "# "):
+			pdf.SetFont("Helvetica", "B", 16)
+			pdf.MultiCell(width, pdfLine+3, tr(strings.TrimPrefix(line, "# ")), "", "L", false)
+		case strings.HasPrefix(line, ">"):
+			pdf.SetFont("Helvetica", "", 8)
+			pdf.SetTextColor(90, 90, 90)
+			pdf.MultiCell(width, pdfLine-1, tr(strings.TrimSpace(strings.TrimPrefix(line, ">"))), "", "L", false)
+			pdf.SetTextColor(0, 0, 0)
+		case strings.HasPrefix(line, "|"):
+			pdfRow(pdf, tr, line, width, header)
+			header = false
+		default:
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(width, pdfLine, tr(line), "", "L", false)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := pdf.Output(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfRow writes a table row with the first column filling the width left by the value columns aligned to the right
+func pdfRow(pdf *fpdf.Fpdf, tr func(string) string, line string, width float64, header bool) {
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	style, fill := "", false
+	if header {
+		style, fill = "B", true
+		pdf.SetFillColor(235, 235, 235)
+	}
+	pdf.SetFont("Helvetica", style, 10)
+	first := width - pdfValueWidth*float64(len(cells)-1)
+	for i, c := range cells {
+		w, align := pdfValueWidth, "R"
+		if i == 0 {
+			w, align = first, "L"
+		}
+		pdf.CellFormat(w, pdfLine+2, tr(strings.TrimSpace(c)), "B", 0, align, fill, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// pdfImage places a png or jpeg image on the flow of the page if it is informed
+func pdfImage(pdf *fpdf.Fpdf, name string, data []byte, width float64) {
+	kind, ok := imageTypes[http.DetectContentType(data)]
+	if len(data) == 0 || !ok {
+		return
+	}
+	options := fpdf.ImageOptions{ImageType: kind}
+	pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(data))
+	pdf.ImageOptions(name, pdf.GetX(), pdf.GetY(), width, 0, true, options, 0, "")
+}
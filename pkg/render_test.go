@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testInvoiceView(t *testing.T) *InvoiceView {
+	qr, err := PixQRCode("00020126580014br.gov.bcb.pix", 64)
+	if err != nil {
+		t.Fatalf("PixQRCode() error = %v", err)
+	}
+	return &InvoiceView{
+		ID:       "2024_05_ana",
+		Date:     "02/05/2024",
+		Due:      "10/05/2024",
+		Business: InvoiceParty{Name: "Clínica Ação", Document: "12.345.678/0001-95"},
+		Client:   InvoiceParty{Name: "Ana <Maria>", Address: "Rua A, 10"},
+		Items: []InvoiceViewItem{
+			{Description: "psico 02/05/2024 10:00 | extra", Value: "150.00"},
+			{Description: "credit note x", Value: "-20.00"},
+		},
+		Total:        "130.00",
+		Instructions: "Pay with PIX",
+		Pix:          "00020126580014br.gov.bcb.pix",
+		Logo:         qr,
+		QRCode:       qr,
+	}
+}
+
+func TestRenderInvoiceHTML(t *testing.T) {
+	tmpl, err := DefaultInvoiceTemplate(RenderHTML)
+	if err != nil {
+		t.Fatalf("DefaultInvoiceTemplate() error = %v", err)
+	}
+	got, err := RenderInvoice(RenderHTML, tmpl, testInvoiceView(t))
+	if err != nil {
+		t.Fatalf("RenderInvoice() error = %v", err)
+	}
+	html := string(got)
+	for _, want := range []string{"Invoice 2024_05_ana", "Ana &lt;Maria&gt;", "<td class=\"value\">-20.00</td>",
+		"Due date: <strong>10/05/2024</strong>", "src=\"data:image/png;base64,", "<code>00020126580014br.gov.bcb.pix</code>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("RenderInvoice() html should contain %q", want)
+		}
+	}
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	tmpl, err := DefaultInvoiceTemplate(RenderPDF)
+	if err != nil {
+		t.Fatalf("DefaultInvoiceTemplate() error = %v", err)
+	}
+	got, err := RenderInvoice(RenderPDF, tmpl, testInvoiceView(t))
+	if err != nil {
+		t.Fatalf("RenderInvoice() error = %v", err)
+	}
+	if !bytes.HasPrefix(got, []byte("%PDF-")) || !bytes.Contains(got, []byte("%%EOF")) {
+		t.Errorf("RenderInvoice() should return a pdf document")
+	}
+}
+
+func TestRenderInvoiceErrors(t *testing.T) {
+	view := testInvoiceView(t)
+	if _, err := RenderInvoice(RenderHTML, "{{.Unknown}}", view); err == nil {
+		t.Errorf("RenderInvoice() should fail with an unknown field")
+	}
+	if _, err := RenderInvoice("doc", "", view); err == nil {
+		t.Errorf("RenderInvoice() should fail with an unknown format")
+	}
+	if _, err := DefaultInvoiceTemplate("doc"); err == nil {
+		t.Errorf("DefaultInvoiceTemplate() should fail with an unknown format")
+	}
+	view.Logo = []byte("not an image")
+	if _, err := RenderInvoice(RenderPDF, "[logo]", view); err == nil {
+		t.Errorf("RenderInvoice() should fail with an invalid logo")
+	}
+}
@@ -0,0 +1,373 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// xsdNode is a generic node of a xml document keeping the order of its children
+type xsdNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []*xsdNode `xml:",any"`
+	Content string     `xml:",chardata"`
+}
+
+// attr returns the value of an attribute of the node by its local name
+func (n *xsdNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// children returns the children of the node with the local name informed
+func (n *xsdNode) children(name string) []*xsdNode {
+	ret := []*xsdNode{}
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == name {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// child returns the first child of the node with the local name informed or nil
+func (n *xsdNode) child(name string) *xsdNode {
+	if c := n.children(name); len(c) > 0 {
+		return c[0]
+	}
+	return nil
+}
+
+// Schema is a validator of xml documents based on a subset of the xsd language:
+// global and local elements, named and anonymous complex types with sequence, choice and attributes and
+// simple types restricted by length, pattern, enumeration, inclusive bounds, total and fraction digits
+type Schema struct {
+	namespace string
+	elements  map[string]*xsdNode
+	complex   map[string]*xsdNode
+	simple    map[string]*xsdNode
+}
+
+// NewSchema parses a xsd document
+func NewSchema(xsd []byte) (*Schema, error) {
+	root := &xsdNode{}
+	if err := xml.Unmarshal(xsd, root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "schema" {
+		return nil, errors.New(ErrXsdInvalid)
+	}
+	s := &Schema{namespace: root.attr("targetNamespace"), elements: map[string]*xsdNode{},
+		complex: map[string]*xsdNode{}, simple: map[string]*xsdNode{}}
+	for _, n := range root.Nodes {
+		switch n.XMLName.Local {
+		case "element":
+			s.elements[n.attr("name")] = n
+		case "complexType":
+			s.complex[n.attr("name")] = n
+		case "simpleType":
+			s.simple[n.attr("name")] = n
+		}
+	}
+	return s, nil
+}
+
+// Validate validates a xml document against the schema returning the first violation found
+func (s *Schema) Validate(doc []byte) error {
+	root := &xsdNode{}
+	if err := xml.NewDecoder(bytes.NewReader(doc)).Decode(root); err != nil {
+		return err
+	}
+	decl, ok := s.elements[root.XMLName.Local]
+	if !ok {
+		return fmt.Errorf(ErrXsdUnexpected, "/"+root.XMLName.Local)
+	}
+	if s.namespace != "" && root.XMLName.Space != s.namespace {
+		return fmt.Errorf(ErrXsdNamespace, root.XMLName.Space, s.namespace)
+	}
+	return s.validateElement(decl, root, "/"+root.XMLName.Local)
+}
+
+// validateElement validates a node against its element declaration
+func (s *Schema) validateElement(decl, node *xsdNode, path string) error {
+	if ct := decl.child("complexType"); ct != nil {
+		return s.validateComplex(ct, node, path)
+	}
+	if st := decl.child("simpleType"); st != nil {
+		return s.validateText(st, node, path)
+	}
+	typ := s.local(decl.attr("type"))
+	if ct, ok := s.complex[typ]; ok {
+		return s.validateComplex(ct, node, path)
+	}
+	if len(node.Nodes) > 0 {
+		return fmt.Errorf(ErrXsdUnexpected, path+"/"+node.Nodes[0].XMLName.Local)
+	}
+	return s.validateSimple(decl.attr("type"), strings.TrimSpace(node.Content), path)
+}
+
+// validateComplex validates the attributes and the children of a node against a complex type
+func (s *Schema) validateComplex(ct, node *xsdNode, path string) error {
+	for _, a := range ct.children("attribute") {
+		value := node.attr(a.attr("name"))
+		if value == "" {
+			if a.attr("use") == "required" {
+				return fmt.Errorf(ErrXsdMissing, path+"/@"+a.attr("name"))
+			}
+			continue
+		}
+		if err := s.validateSimple(a.attr("type"), value, path+"/@"+a.attr("name")); err != nil {
+			return err
+		}
+	}
+	group := ct.child("sequence")
+	if group == nil {
+		group = ct.child("choice")
+	}
+	if group == nil {
+		if len(node.Nodes) > 0 {
+			return fmt.Errorf(ErrXsdUnexpected, path+"/"+node.Nodes[0].XMLName.Local)
+		}
+		return nil
+	}
+	pos, err := s.matchParticle(group, node.Nodes, 0, path)
+	if err != nil {
+		return err
+	}
+	if pos < len(node.Nodes) {
+		return fmt.Errorf(ErrXsdUnexpected, path+"/"+node.Nodes[pos].XMLName.Local)
+	}
+	return nil
+}
+
+// matchParticle matches an element, sequence or choice particle with its occurrences from a position
+// of the children returning the next position
+func (s *Schema) matchParticle(p *xsdNode, nodes []*xsdNode, pos int, path string) (int, error) {
+	minOccurs, maxOccurs := s.occurs(p)
+	count := 0
+	for maxOccurs < 0 || count < maxOccurs {
+		next, matched, err := s.matchOnce(p, nodes, pos, path)
+		if err != nil {
+			return pos, err
+		}
+		if !matched {
+			break
+		}
+		pos = next
+		count++
+	}
+	if count < minOccurs {
+		name := p.attr("name")
+		if name == "" {
+			name = s.firstName(p)
+		}
+		return pos, fmt.Errorf(ErrXsdMissing, path+"/"+name)
+	}
+	return pos, nil
+}
+
+// matchOnce matches one occurrence of a particle. It returns if it was matched
+func (s *Schema) matchOnce(p *xsdNode, nodes []*xsdNode, pos int, path string) (int, bool, error) {
+	switch p.XMLName.Local {
+	case "element":
+		if pos >= len(nodes) || nodes[pos].XMLName.Local != p.attr("name") {
+			return pos, false, nil
+		}
+		return pos + 1, true, s.validateElement(p, nodes[pos], path+"/"+p.attr("name"))
+	case "sequence":
+		if pos >= len(nodes) || !s.starts(p, nodes[pos].XMLName.Local) {
+			return pos, false, nil
+		}
+		for _, c := range p.Nodes {
+			next, err := s.matchParticle(c, nodes, pos, path)
+			if err != nil {
+				return pos, false, err
+			}
+			pos = next
+		}
+		return pos, true, nil
+	case "choice":
+		for _, c := range p.Nodes {
+			if pos < len(nodes) && s.starts(c, nodes[pos].XMLName.Local) {
+				next, err := s.matchParticle(c, nodes, pos, path)
+				return next, err == nil && next > pos, err
+			}
+		}
+	}
+	return pos, false, nil
+}
+
+// starts checks if a particle can start with an element name
+func (s *Schema) starts(p *xsdNode, name string) bool {
+	switch p.XMLName.Local {
+	case "element":
+		return p.attr("name") == name
+	case "sequence":
+		for _, c := range p.Nodes {
+			if s.starts(c, name) {
+				return true
+			}
+			if minOccurs, _ := s.occurs(c); minOccurs > 0 {
+				return false
+			}
+		}
+	case "choice":
+		for _, c := range p.Nodes {
+			if s.starts(c, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstName returns the name of the first element of a particle to report it as missing
+func (s *Schema) firstName(p *xsdNode) string {
+	for _, c := range p.Nodes {
+		if c.XMLName.Local == "element" {
+			return c.attr("name")
+		}
+		if name := s.firstName(c); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// occurs returns the minimum and maximum occurrences of a particle. Unbounded maximum is -1
+func (s *Schema) occurs(p *xsdNode) (int, int) {
+	minOccurs, maxOccurs := 1, 1
+	if v, err := strconv.Atoi(p.attr("minOccurs")); err == nil {
+		minOccurs = v
+	}
+	if v := p.attr("maxOccurs"); v == "unbounded" {
+		maxOccurs = -1
+	} else if n, err := strconv.Atoi(v); err == nil {
+		maxOccurs = n
+	}
+	return minOccurs, maxOccurs
+}
+
+// validateText validates the text of a node without children against a simple type
+func (s *Schema) validateText(st, node *xsdNode, path string) error {
+	if len(node.Nodes) > 0 {
+		return fmt.Errorf(ErrXsdUnexpected, path+"/"+node.Nodes[0].XMLName.Local)
+	}
+	return s.validateRestriction(st, strings.TrimSpace(node.Content), path)
+}
+
+// validateSimple validates a value against a named simple type or a built-in one
+func (s *Schema) validateSimple(typ, value, path string) error {
+	if st, ok := s.simple[s.local(typ)]; ok {
+		return s.validateRestriction(st, value, path)
+	}
+	if !s.validBuiltin(s.local(typ), value) {
+		return fmt.Errorf(ErrXsdInvalidValue, path, value)
+	}
+	return nil
+}
+
+// validateRestriction validates a value against the base and the facets of a simple type restriction
+func (s *Schema) validateRestriction(st *xsdNode, value, path string) error {
+	r := st.child("restriction")
+	if r == nil {
+		return nil
+	}
+	if err := s.validateSimple(r.attr("base"), value, path); err != nil {
+		return err
+	}
+	enums := []string{}
+	for _, f := range r.Nodes {
+		v := f.attr("value")
+		n, _ := strconv.Atoi(v)
+		invalid := false
+		switch f.XMLName.Local {
+		case "length":
+			invalid = utf8.RuneCountInString(value) != n
+		case "minLength":
+			invalid = utf8.RuneCountInString(value) < n
+		case "maxLength":
+			invalid = utf8.RuneCountInString(value) > n
+		case "pattern":
+			re, err := regexp.Compile("^(?:" + v + ")$")
+			invalid = err != nil || !re.MatchString(value)
+		case "enumeration":
+			enums = append(enums, v)
+		case "totalDigits":
+			invalid = len(strings.TrimLeft(strings.Map(s.digit, value), "0")) > n
+		case "fractionDigits":
+			if i := strings.Index(value, "."); i >= 0 {
+				invalid = len(value)-i-1 > n
+			}
+		case "minInclusive", "maxInclusive":
+			limit, err1 := strconv.ParseFloat(v, 64)
+			number, err2 := strconv.ParseFloat(value, 64)
+			invalid = err1 != nil || err2 != nil || (f.XMLName.Local == "minInclusive" && number < limit) ||
+				(f.XMLName.Local == "maxInclusive" && number > limit)
+		}
+		if invalid {
+			return fmt.Errorf(ErrXsdFacet, path, value, f.XMLName.Local, v)
+		}
+	}
+	if len(enums) > 0 {
+		for _, e := range enums {
+			if e == value {
+				return nil
+			}
+		}
+		return fmt.Errorf(ErrXsdFacet, path, value, "enumeration", strings.Join(enums, ","))
+	}
+	return nil
+}
+
+// validBuiltin checks a value against the built-in xsd types used by the schemas
+func (s *Schema) validBuiltin(typ, value string) bool {
+	switch typ {
+	case "decimal":
+		return regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`).MatchString(value)
+	case "int", "integer", "long", "short", "byte":
+		return regexp.MustCompile(`^[+-]?\d+$`).MatchString(value)
+	case "nonNegativeInteger", "unsignedByte", "unsignedShort", "unsignedInt", "unsignedLong":
+		return regexp.MustCompile(`^\+?\d+$`).MatchString(value)
+	case "positiveInteger":
+		return regexp.MustCompile(`^\+?0*[1-9]\d*$`).MatchString(value)
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "dateTime":
+		for _, layout := range []string{"2006-01-02T15:04:05", time.RFC3339} {
+			if _, err := time.Parse(layout, value); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// digit keeps just the digits of a string on strings.Map
+func (s *Schema) digit(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return -1
+}
+
+// local returns the local name of a qualified type name
+func (s *Schema) local(name string) string {
+	if i := strings.Index(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package pkg
+
+import "testing"
+
+const testXsd = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:test" elementFormDefault="qualified">
+	<xs:simpleType name="tCode">
+		<xs:restriction base="xs:string">
+			<xs:pattern value="[A-Z]{3}"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:simpleType name="tValue">
+		<xs:restriction base="xs:decimal">
+			<xs:fractionDigits value="2"/>
+			<xs:minInclusive value="0"/>
+		</xs:restriction>
+	</xs:simpleType>
+	<xs:complexType name="tItem">
+		<xs:sequence>
+			<xs:element name="Code" type="tCode"/>
+			<xs:element name="Note" type="xs:string" minOccurs="0"/>
+			<xs:choice>
+				<xs:element name="Value" type="tValue"/>
+				<xs:element name="Free" type="xs:string"/>
+			</xs:choice>
+		</xs:sequence>
+		<xs:attribute name="id" type="xs:int" use="required"/>
+	</xs:complexType>
+	<xs:element name="Order">
+		<xs:complexType>
+			<xs:sequence>
+				<xs:element name="Date" type="xs:date"/>
+				<xs:element name="Item" type="tItem" maxOccurs="unbounded"/>
+			</xs:sequence>
+		</xs:complexType>
+	</xs:element>
+</xs:schema>`
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := NewSchema([]byte(testXsd))
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+	valid := []string{
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Value>10.50</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Note>x</Note><Free>y</Free></Item>` +
+			`<Item id="2"><Code>DEF</Code><Value>0</Value></Item></Order>`,
+	}
+	for _, doc := range valid {
+		if err := s.Validate([]byte(doc)); err != nil {
+			t.Errorf("Validate(%s) error = %v", doc, err)
+		}
+	}
+	invalid := []string{
+		`<Order><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Value>1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-02-31</Date><Item id="1"><Code>ABC</Code><Value>1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item><Code>ABC</Code><Value>1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>abc</Code><Value>1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Value>1.005</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Value>-1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Note>x</Note><Code>ABC</Code><Value>1</Value></Item></Order>`,
+		`<Order xmlns="urn:test"><Date>2024-01-31</Date><Item id="1"><Code>ABC</Code><Value>1</Value><Free>y</Free></Item></Order>`,
+		`<Invoice xmlns="urn:test"/>`,
+	}
+	for _, doc := range invalid {
+		if err := s.Validate([]byte(doc)); err == nil {
+			t.Errorf("Validate(%s) error = nil, want error", doc)
+		}
+	}
+}